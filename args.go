@@ -5,13 +5,17 @@
 package q
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/printer"
 	"go/token"
+	"os"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 	"unicode/utf8"
 
 	"github.com/bingoohuang/q/pretty"
@@ -33,11 +37,14 @@ func argName(arg ast.Expr) string {
 		}
 	case *ast.BinaryExpr,
 		*ast.CallExpr,
+		*ast.CompositeLit,
 		*ast.IndexExpr,
+		*ast.IndexListExpr,
 		*ast.KeyValueExpr,
 		*ast.ParenExpr,
 		*ast.SelectorExpr,
 		*ast.SliceExpr,
+		*ast.StarExpr,
 		*ast.TypeAssertExpr,
 		*ast.UnaryExpr:
 		name = exprToString(arg)
@@ -50,61 +57,259 @@ func argName(arg ast.Expr) string {
 // returns its arguments as a slice of strings. If the argument is a literal,
 // argNames will return an empty string at the index position of that argument.
 // For example, q.Q(ip, port, 5432) would return []string{"ip", "port", ""}.
-// argNames returns an error if the source text cannot be parsed.
+// The line is the one of the call's opening parenthesis, as reported by
+// runtime.Caller, so calls split across lines and method chains like
+// q.With("k", v).Q(x) are found too. argNames returns an error if the source
+// text cannot be parsed.
 func argNames(filename string, line int) ([]string, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", filename, err)
+	}
+
 	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, filename, nil, 0)
+	f, err := parser.ParseFile(fset, filename, src, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse %q: %w", filename, err)
 	}
 
-	var names []string
+	call := findQCall(fset, f, line)
+	if call == nil {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(call.Args))
+	for _, arg := range call.Args {
+		name := ""
+		if arg = unwrapLazy(arg); arg != nil && argName(arg) != "" {
+			name = sourceText(fset, src, arg)
+		}
+		names = append(names, name)
+	}
+
+	if call.Ellipsis.IsValid() && names[len(names)-1] != "" {
+		// q.Q(a, rest...) -> []string{"a", "rest..."}, see spreadNames.
+		names[len(names)-1] += "..."
+	}
+
+	return names, nil
+}
+
+// namesKey is a call site in the argNames cache.
+type namesKey struct {
+	file string
+	line int
+}
+
+// namesEntry is the cached result of argNames for a version of the file.
+type namesEntry struct {
+	modTime time.Time
+	size    int64
+	names   []string
+}
+
+// nolint: gochecknoglobals
+var (
+	namesCache   = make(map[namesKey]namesEntry)
+	namesCacheMu sync.Mutex
+)
+
+// cachedArgNames is argNames without reading and parsing the file again for
+// every call of the same call site. The names are looked up again if the file
+// changed since.
+func cachedArgNames(filename string, line int) ([]string, error) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", filename, err)
+	}
+
+	key := namesKey{file: filename, line: line}
+	namesCacheMu.Lock()
+	e, ok := namesCache[key]
+	namesCacheMu.Unlock()
+	if ok && e.modTime.Equal(fi.ModTime()) && e.size == fi.Size() {
+		return e.names, nil
+	}
+
+	names, err := argNames(filename, line)
+	if err != nil {
+		return nil, err
+	}
+
+	// Callers may append to the names, never into the cached array.
+	names = names[:len(names):len(names)]
+
+	namesCacheMu.Lock()
+	namesCache[key] = namesEntry{modTime: fi.ModTime(), size: fi.Size(), names: names}
+	namesCacheMu.Unlock()
+
+	return names, nil
+}
+
+// spreadNames returns the names of n values logged by a q call with the
+// argument names. The values of a variadic spread, named "rest..." by
+// argNames, are named rest[0], rest[1], etc. The values of a single call
+// returning multiple results, as in q.Q(strconv.Atoi(s)), are named
+// strconv.Atoi(s)[0] and strconv.Atoi(s)[1].
+func spreadNames(names []string, n int) []string {
+	last := len(names) - 1
+	if last < 0 {
+		return names
+	}
+
+	base, spread := strings.CutSuffix(names[last], "...")
+	if !spread && (len(names) == n || last > 0 || names[0] == "") {
+		return names
+	}
+
+	expanded := append([]string(nil), names[:last]...)
+	for i := 0; len(expanded) < n; i++ {
+		expanded = append(expanded, fmt.Sprintf("%s[%d]", base, i))
+	}
+
+	return expanded
+}
+
+// findQCall returns the q call whose opening parenthesis is on the given line.
+// Calls of the qFuncs are preferred over other q calls on the same line, e.g.
+// q.Q(x) over q.With("k", v) in q.With("k", v).Q(x), and outer calls over the
+// calls nested in their arguments.
+func findQCall(fset *token.FileSet, f *ast.File, line int) *ast.CallExpr {
+	var (
+		found, fallback *ast.CallExpr
+		recv            *receivers // looked up at the first method call
+		pkgRecv         bool       // recv has the receivers of the whole package
+	)
+	isMethod := func(call *ast.CallExpr) bool {
+		sel, is := call.Fun.(*ast.SelectorExpr)
+		if !is || sel.Sel == nil || !qFuncs[sel.Sel.Name] {
+			return false
+		}
+
+		if recv == nil {
+			recv = newReceivers(f)
+		}
+		if !isQMethod(call, recv) && !pkgRecv {
+			// The receiver may be declared in another file of the package.
+			recv.addPackage(fset.Position(f.Pos()).Filename, f)
+			pkgRecv = true
+		}
+
+		return isQMethod(call, recv)
+	}
+
 	ast.Inspect(f, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+
 		call, is := n.(*ast.CallExpr)
 		if !is {
 			// The node is not a function call.
 			return true // visit next node
 		}
 
-		if fset.Position(call.End()).Line != line {
+		if fset.Position(call.Lparen).Line != line {
 			// The node is a function call, but it's on the wrong line.
 			return true
 		}
 
-		if !isQCall(call) {
-			// The node is a function call on correct line, but it's not a Q()
-			// function.
-			return true
-		}
-
-		for _, arg := range call.Args {
-			names = append(names, argName(arg))
+		switch {
+		case isQFunction(call) || isMethod(call):
+			found = call
+		case fallback == nil && isQPackage(call):
+			fallback = call
 		}
 
 		return true
 	})
 
-	return names, nil
+	if found == nil {
+		return fallback
+	}
+
+	return found
+}
+
+// sourceText returns the exact source text of the expression. The lines of a
+// multi-line expression are joined, without the line breaks and indentation,
+// e.g. a method chain is returned as b.Reset().Len().
+func sourceText(fset *token.FileSet, src []byte, x ast.Expr) string {
+	start, end := fset.Position(x.Pos()).Offset, fset.Position(x.End()).Offset
+	if start < 0 || end > len(src) || start > end {
+		return exprToString(x)
+	}
+
+	lines := strings.Split(string(src[start:end]), "\n")
+	text := lines[0]
+	for _, l := range lines[1:] {
+		l = strings.TrimSpace(l)
+		switch {
+		case l == "":
+		case strings.HasSuffix(text, ",") && strings.ContainsAny(l[:1], ")]}"):
+			// Drop the trailing comma of a multi-line argument list.
+			text = text[:len(text)-1] + l
+		case strings.ContainsAny(text[len(text)-1:], ".([{") ||
+			strings.ContainsAny(l[:1], ".)]}"):
+			text += l
+		default:
+			text += " " + l
+		}
+	}
+
+	return strings.TrimSpace(text)
 }
 
 // argWidth returns the number of characters that will be seen when the given
-// argument is printed at the terminal.
+// argument is printed at the terminal. ANSI color codes and zero-width
+// characters like newlines are not counted. It doesn't allocate.
 func argWidth(arg string) int {
-	// Strip zero-width characters.
-	replacer := strings.NewReplacer(
-		"\n", "",
-		"\t", "",
-		"\r", "",
-		"\f", "",
-		"\v", "",
-		string(bold), "",
-		string(yellow), "",
-		string(cyan), "",
-		string(endColor), "",
-	)
-	s := replacer.Replace(arg)
+	width := 0
+	for i := 0; i < len(arg); {
+		switch arg[i] {
+		case '\033':
+			if code := colorCode(arg[i:]); code != "" {
+				i += len(code)
+				continue
+			}
+		case '\n', '\t', '\r', '\f', '\v':
+			i++
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(arg[i:])
+		i += size
+		width++
+	}
 
-	return utf8.RuneCountInString(s)
+	return width
+}
+
+// colorCode returns the ANSI color code added by colorize at the start of s,
+// or "" if s doesn't start with one.
+func colorCode[T string | []byte](s T) string {
+	for _, c := range [...]color{bold, yellow, cyan, endColor, fieldColor, stringColor, numberColor} {
+		if hasPrefix(s, string(c)) {
+			return string(c)
+		}
+	}
+
+	return ""
+}
+
+// hasPrefix is strings.HasPrefix for both strings and byte slices.
+func hasPrefix[T string | []byte](s T, prefix string) bool {
+	if len(s) < len(prefix) {
+		return false
+	}
+
+	for i := 0; i < len(prefix); i++ {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
 }
 
 // colorize returns the given text encapsulated in ANSI escape codes that
@@ -113,6 +318,44 @@ func colorize(text string, c color) string {
 	return string(c) + text + string(endColor)
 }
 
+// colorStripper removes the ANSI escape codes added by colorize.
+var colorStripper = strings.NewReplacer( // nolint: gochecknoglobals
+	string(bold), "",
+	string(yellow), "",
+	string(cyan), "",
+	string(endColor), "",
+	string(fieldColor), "",
+	string(stringColor), "",
+	string(numberColor), "",
+)
+
+// stripColors removes the ANSI escape codes added by colorize from the text.
+func stripColors(text string) string {
+	return colorStripper.Replace(text)
+}
+
+// appendStripColors appends src to dst without the ANSI escape codes added by
+// colorize. It is stripColors for the flushed output, without converting it
+// to a string and back.
+func appendStripColors(dst, src []byte) []byte {
+	for {
+		i := bytes.IndexByte(src, '\033')
+		if i < 0 {
+			return append(dst, src...)
+		}
+
+		dst = append(dst, src[:i]...)
+		src = src[i:]
+		if code := colorCode(src); code != "" {
+			src = src[len(code):]
+			continue
+		}
+
+		dst = append(dst, src[0])
+		src = src[1:]
+	}
+}
+
 // exprToString returns the source text underlying the given ast.Expr.
 func exprToString(arg ast.Expr) string {
 	var buf strings.Builder
@@ -126,17 +369,37 @@ func exprToString(arg ast.Expr) string {
 	return strings.ReplaceAll(buf.String(), "\t", "    ")
 }
 
-// formatArgs converts the given args to pretty-printed, colorized strings.
-func formatArgs(args ...interface{}) []string {
+// formatArgs converts the given args to pretty-printed, colorized strings,
+// printing the values other than strings with opts, see valueOptions.
+func formatArgs(opts []pretty.Option, args ...interface{}) []string {
 	formatted := make([]string, 0, len(args))
 	for _, a := range args {
-		s := colorize(pretty.Sprint(a), cyan)
+		if _, ok := a.(string); !ok && len(opts) > 0 {
+			a = pretty.NewFormatter(a, opts...)
+		}
+		s := colorize(sprint(a), cyan)
 		formatted = append(formatted, s)
 	}
 
 	return formatted
 }
 
+// highlightArgs is formatArgs with the syntax of the args highlighted, see
+// SetHighlight. Strings, including the values already rendered by helpers
+// like q.Timer, are colored as a whole, like formatArgs does.
+func highlightArgs(opts []pretty.Option, args ...interface{}) []string {
+	formatted := make([]string, 0, len(args))
+	for _, a := range args {
+		if s, ok := a.(string); ok {
+			formatted = append(formatted, colorize(sprint(s), cyan))
+			continue
+		}
+		formatted = append(formatted, sprint(pretty.NewFormatter(a, append(opts, pretty.Colors(true))...)))
+	}
+
+	return formatted
+}
+
 // getCallerInfo returns the name, file, and line of the function calling q.Q().
 func getCallerInfo(callDepth int) (funcName, file string, line int, err error) {
 	pc, file, line, ok := runtime.Caller(callDepth)
@@ -166,26 +429,27 @@ func prependArgName(names, values []string) []string {
 
 			continue
 		}
-		name = colorize(name, bold)
-		prepended[i] = fmt.Sprintf("%s=%s", name, value)
+		prepended[i] = string(bold) + name + string(endColor) + "=" + value
 	}
 
 	return prepended
 }
 
-// isQCall returns true if the given function call expression is Q() or q.Q().
-func isQCall(n *ast.CallExpr) bool {
-	return isQFunction(n) || isQPackage(n)
+// isQCall returns true if the given function call expression is Q(), q.Q()
+// or the l.Q() of one of the receivers.
+func isQCall(n *ast.CallExpr, r *receivers) bool {
+	return isQFunction(n) || isQPackage(n) || isQMethod(n, r)
 }
 
-// isQFunction returns true if the given function call expression is Q().
+// isQFunction returns true if the given function call expression is Q(), or
+// one of the other qFuncs called without the package name.
 func isQFunction(n *ast.CallExpr) bool {
 	ident, is := n.Fun.(*ast.Ident)
 	if !is {
 		return false
 	}
 
-	return ident.Name == "Q"
+	return qFuncs[ident.Name]
 }
 
 // isQPackage returns true if the given function call expression is in the q
@@ -204,3 +468,30 @@ func isQPackage(n *ast.CallExpr) bool {
 
 	return ident.Name == "q"
 }
+
+// qFuncs are the names of the q functions and Logger methods whose arguments
+// are logged with their names.
+var qFuncs = map[string]bool{ // nolint: gochecknoglobals
+	"Q":     true,
+	"If":    true,
+	"Every": true,
+	"Limit": true,
+	"Once":  true,
+	"Trace": true,
+	"Ctx":   true,
+	"Debug": true,
+	"Info":  true,
+	"Warn":  true,
+}
+
+// isQMethod returns true if the given function call expression is a call of
+// one of the qFuncs on one of the receivers, e.g. l.Q() on a *Logger, or on
+// package q, e.g. q.Q().
+func isQMethod(n *ast.CallExpr, r *receivers) bool {
+	sel, is := n.Fun.(*ast.SelectorExpr)
+	if !is || sel.Sel == nil || !qFuncs[sel.Sel.Name] {
+		return false
+	}
+
+	return isQPackage(n) || r.is(sel.X)
+}
@@ -7,6 +7,9 @@ package q
 import (
 	"fmt"
 	"go/ast"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/bingoohuang/q/pretty"
@@ -396,6 +399,153 @@ func TestArgNames(t *testing.T) {
 	}
 }
 
+// TestArgNamesMultiLine verifies that argNames() finds q calls by the line of
+// their opening parenthesis and returns the source text of each argument, for
+// calls split across lines, method chains and composite literals.
+func TestArgNamesMultiLine(t *testing.T) {
+	const src = `package main
+
+import "github.com/bingoohuang/q"
+
+func main() {
+	q.Q(a,
+		b.Len(),
+	)
+	q.Q(strings.NewReplacer("a", "b").
+		Replace(s))
+	q.With("k", v).Q(x, &point{X: 1, Y: y})
+	if q.Q(m[k]); ok {
+	}
+	q.Q(f(
+		1,
+		2,
+	), []int{1, 2}, *p, "literal")
+	q.If(len(items) > 0, items...)
+}
+`
+	filename := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(filename, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		line int
+		want []string
+	}{
+		{line: 6, want: []string{"a", "b.Len()"}},
+		{line: 9, want: []string{`strings.NewReplacer("a", "b").Replace(s)`}},
+		{line: 11, want: []string{"x", "&point{X: 1, Y: y}"}},
+		{line: 12, want: []string{"m[k]"}},
+		{line: 14, want: []string{"f(1, 2)", "[]int{1, 2}", "*p", ""}},
+		{line: 18, want: []string{"len(items) > 0", "items..."}},
+		{line: 7, want: nil},
+	}
+
+	for _, tc := range testCases {
+		got, err := argNames(filename, tc.line)
+		if err != nil {
+			t.Fatalf("argNames: failed to parse %q: %v", filename, err)
+		}
+
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("\nargNames(%d)\ngot:  %#v\nwant: %#v", tc.line, got, tc.want)
+		}
+	}
+}
+
+// TestArgNamesReceivers verifies that argNames() only takes the methods of q
+// Loggers and Fields for q calls, wherever the Logger is declared, and not the
+// methods of the same names of other loggers.
+func TestArgNamesReceivers(t *testing.T) {
+	const src = `package main
+
+import (
+	"log/slog"
+
+	ql "github.com/bingoohuang/q"
+)
+
+type server struct{ qlog *ql.Logger }
+
+func (s *server) run(log *slog.Logger, l *ql.Logger) {
+	log.Info("msg", a)
+	l.Info(b)
+	s.qlog.Warn(c)
+	f := l.With("k", v)
+	f.Q(d)
+	ql.New().Q(e)
+	std.Q(g)
+	logger().If(ok, h)
+	other.Q(i)
+}
+
+func logger() *ql.Logger { return nil }
+`
+	const other = `package main
+
+import "github.com/bingoohuang/q"
+
+var std = q.New()
+`
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(filename, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "std.go"), []byte(other), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		line int
+		want []string
+	}{
+		{line: 12, want: nil},
+		{line: 13, want: []string{"b"}},
+		{line: 14, want: []string{"c"}},
+		{line: 16, want: []string{"d"}},
+		{line: 17, want: []string{"e"}},
+		{line: 18, want: []string{"g"}},
+		{line: 19, want: []string{"ok", "h"}},
+		{line: 20, want: nil},
+	}
+
+	for _, tc := range testCases {
+		got, err := argNames(filename, tc.line)
+		if err != nil {
+			t.Fatalf("argNames: failed to parse %q: %v", filename, err)
+		}
+
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("\nargNames(%d)\ngot:  %#v\nwant: %#v", tc.line, got, tc.want)
+		}
+	}
+}
+
+// TestSpreadNames verifies that spreadNames() names each value of a variadic
+// spread and each result of a multi-valued call.
+func TestSpreadNames(t *testing.T) {
+	testCases := []struct {
+		names []string
+		n     int
+		want  []string
+	}{
+		{names: []string{"a", "b"}, n: 2, want: []string{"a", "b"}},
+		{names: []string{"a", "rest..."}, n: 3, want: []string{"a", "rest[0]", "rest[1]"}},
+		{names: []string{"a", "rest..."}, n: 1, want: []string{"a"}},
+		{names: []string{"strconv.Atoi(s)"}, n: 2, want: []string{"strconv.Atoi(s)[0]", "strconv.Atoi(s)[1]"}},
+		{names: []string{""}, n: 2, want: []string{""}},
+		{names: nil, n: 2, want: nil},
+	}
+
+	for _, tc := range testCases {
+		got := spreadNames(tc.names, tc.n)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("\nspreadNames(%q, %d)\ngot:  %#v\nwant: %#v", tc.names, tc.n, got, tc.want)
+		}
+	}
+}
+
 // TestArgNamesBadFilename verifies that argNames() returns an error if given an
 // invalid filename.
 func TestArgNamesBadFilename(t *testing.T) {
@@ -419,6 +569,8 @@ func TestArgWidth(t *testing.T) {
 		{colorize("myVar", bold), 5},
 		{colorize("3.14", cyan), 4},
 		{colorize("你好", cyan), 2},
+		{colorize("a\nb", cyan), 2},
+		{"\033[31mred", 8}, // only the codes of colorize are skipped
 	}
 
 	for _, tc := range testCases {
@@ -429,6 +581,32 @@ func TestArgWidth(t *testing.T) {
 	}
 }
 
+// TestAppendStripColors verifies that appendStripColors() removes the color
+// codes added by colorize, like stripColors.
+func TestAppendStripColors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"plain",
+		colorize("myVar", bold) + "=" + colorize("int(1)", cyan),
+		colorize("0.001s", yellow) + " \033[31mred\033",
+	} {
+		got := string(appendStripColors([]byte("prefix "), []byte(text)))
+		if want := "prefix " + stripColors(text); got != want {
+			t.Fatalf("\nappendStripColors(%q)\ngot:  %q\nwant: %q", text, got, want)
+		}
+	}
+}
+
+// BenchmarkArgWidth measures argWidth() of a colorized name=value argument.
+func BenchmarkArgWidth(b *testing.B) {
+	arg := prependArgName([]string{"host"}, formatArgs(nil, "example.com"))[0]
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		argWidth(arg)
+	}
+}
+
 // TestFormatArgs verifies that formatArgs() produces the expected string.
 func TestFormatArgs(t *testing.T) {
 	testCases := []struct {
@@ -475,7 +653,7 @@ func TestFormatArgs(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		got := formatArgs(tc.args...)
+		got := formatArgs(nil, tc.args...)
 
 		if len(got) != len(tc.want) {
 			t.Fatalf("\nTEST %d\ngot:  %s\nwant: %s", tc.id, got, tc.want)
@@ -540,9 +718,10 @@ func TestPrependArgName(t *testing.T) {
 }
 
 // TestIsQCall verifies that isQCall() returns true if the given call expression
-// is q.Q().
+// is q.Q(), or a q method of a Logger.
 // nolint: funlen
 func TestIsQCall(t *testing.T) {
+	recv := &receivers{vars: map[string]bool{"l": true}}
 	testCases := []struct {
 		id   int
 		expr *ast.CallExpr
@@ -596,10 +775,30 @@ func TestIsQCall(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			id: 7,
+			expr: &ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   &ast.Ident{Name: "l"},
+					Sel: &ast.Ident{Name: "Info"},
+				},
+			},
+			want: true,
+		},
+		{
+			id: 8,
+			expr: &ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   &ast.Ident{Name: "log"},
+					Sel: &ast.Ident{Name: "Info"},
+				},
+			},
+			want: false,
+		},
 	}
 
 	for _, tc := range testCases {
-		got := isQCall(tc.expr)
+		got := isQCall(tc.expr, recv)
 		if got != tc.want {
 			t.Fatalf(
 				"\nTEST %d\nisQCall(%s)\ngot:  %v\nwant: %v",
@@ -0,0 +1,176 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Overflow selects what an asynchronous logger does with a write when its
+// queue is full, see SetAsync.
+type Overflow int
+
+const (
+	// Block makes the q call wait until the queue has room. No output is lost.
+	Block Overflow = iota
+	// Drop discards the write and counts it, see Dropped. q calls never wait.
+	Drop
+)
+
+// asyncWriter writes the flushed output of a logger on its own goroutine.
+type asyncWriter struct {
+	queue    chan asyncWrite
+	overflow Overflow
+	dropped  atomic.Uint64
+	done     chan struct{} // closed when the goroutine has written everything
+}
+
+// asyncWrite is a queued write, or a flush request if flushed is set.
+type asyncWrite struct {
+	data     *[]byte       // from getScratch, returned to the pool once written
+	out, tee io.Writer     // destinations at the time of the q call
+	flushed  chan struct{} // closed when all writes queued before are done
+}
+
+// SetAsync moves the writes of Q and its variants to a background goroutine.
+// Up to queue flushed records wait for it. When the queue is full, overflow
+// decides whether the q call waits or its output is dropped. A queue <= 0
+// makes the writes synchronous again, which is the default. Call Flush or
+// Close before the program exits, or the last records may be lost.
+func SetAsync(queue int, overflow Overflow) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.setAsync(queue, overflow)
+}
+
+// WithAsync makes the Logger write on a background goroutine, see SetAsync.
+func WithAsync(queue int, overflow Overflow) Option {
+	return func(l *logger) { l.setAsync(queue, overflow) }
+}
+
+// Flush waits until the output of Q and its variants queued so far is
+// written, after writing the count of a pending run of repeated records, see
+// SetDedup. It returns at once if the writes are synchronous.
+func Flush() { std.flushAsync() }
+
+// Close flushes the output of Q and its variants and stops the background
+// goroutine started by SetAsync. Later writes are synchronous.
+func Close() { std.close() }
+
+// Dropped returns the number of writes of Q and its variants discarded
+// because the queue was full, see Drop.
+func Dropped() uint64 { return std.dropped() }
+
+// Flush waits until the Logger's output queued so far is written, like
+// q.Flush.
+func (l *Logger) Flush() { l.flushAsync() }
+
+// Close flushes the Logger's output and stops its background goroutine, like
+// q.Close.
+func (l *Logger) Close() { l.close() }
+
+// Dropped returns the number of the Logger's writes discarded because the
+// queue was full.
+func (l *Logger) Dropped() uint64 { return l.dropped() }
+
+// setAsync replaces the asynchronous writer of the logger, after writing out
+// the queue of the current one. l.mu must be held, unless l is not in use yet.
+func (l *logger) setAsync(queue int, overflow Overflow) {
+	if l.async != nil {
+		close(l.async.queue)
+		<-l.async.done
+		l.droppedBefore += l.async.dropped.Load()
+		l.async = nil
+	}
+
+	if queue <= 0 {
+		return
+	}
+
+	l.async = &asyncWriter{
+		queue:    make(chan asyncWrite, queue),
+		overflow: overflow,
+		done:     make(chan struct{}),
+	}
+	go l.async.run()
+}
+
+// close writes the pending repeats and stops the asynchronous writer.
+func (l *logger) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.writeRepeats()
+	l.setAsync(0, Block)
+}
+
+// flushAsync writes the pending repeats and waits for the writes queued so
+// far.
+func (l *logger) flushAsync() {
+	l.mu.Lock()
+	l.writeRepeats()
+	w := l.async
+	if w == nil {
+		l.mu.Unlock()
+		return
+	}
+
+	flushed := make(chan struct{})
+	w.queue <- asyncWrite{flushed: flushed}
+	l.mu.Unlock()
+
+	<-flushed
+}
+
+// dropped returns the number of writes dropped by the current and the
+// previous asynchronous writers.
+func (l *logger) dropped() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := l.droppedBefore
+	if l.async != nil {
+		n += l.async.dropped.Load()
+	}
+
+	return n
+}
+
+// enqueue queues the write, or drops it if the queue is full and the
+// overflow policy is Drop. l.mu must be held, which keeps the order of the
+// writes.
+func (w *asyncWriter) enqueue(aw asyncWrite) {
+	if w.overflow == Block {
+		w.queue <- aw
+		return
+	}
+
+	select {
+	case w.queue <- aw:
+	default:
+		w.dropped.Add(1)
+		putScratch(aw.data)
+	}
+}
+
+// run writes the queued writes until the queue is closed.
+func (w *asyncWriter) run() {
+	defer close(w.done)
+
+	for aw := range w.queue {
+		if aw.flushed != nil {
+			close(aw.flushed)
+			continue
+		}
+
+		if err := writeOutput(aw.out, aw.tee, *aw.data); err != nil {
+			fmt.Println(err)
+		}
+		putScratch(aw.data)
+	}
+}
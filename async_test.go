@@ -0,0 +1,110 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// gatedWriter collects writes, each only once the gate is opened.
+type gatedWriter struct {
+	gate chan struct{}
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.String()
+}
+
+// TestAsync verifies that an asynchronous Logger writes in the background and
+// that Flush waits for the queued writes.
+func TestAsync(t *testing.T) {
+	w := &gatedWriter{gate: make(chan struct{})}
+	l := New(WithOutput(w), WithColors(false), WithAsync(8, Block))
+	defer l.Close()
+
+	for i := 0; i < 3; i++ {
+		l.Q(i) // returns although the writer is blocked
+	}
+
+	if got := w.String(); got != "" {
+		t.Fatalf("\nLogger.Q()\ngot:  %q\nwant: nothing written yet", got)
+	}
+
+	close(w.gate)
+	l.Flush()
+
+	if got := strings.Count(w.String(), " i=int("); got != 3 {
+		t.Fatalf("\nLogger.Flush()\ngot:  %d records in %q\nwant: 3", got, w.String())
+	}
+}
+
+// TestAsyncDrop verifies that the Drop policy discards and counts the writes
+// that don't fit into the queue, and that Close writes the queued ones.
+func TestAsyncDrop(t *testing.T) {
+	w := &gatedWriter{gate: make(chan struct{})}
+	l := New(WithOutput(w), WithColors(false), WithAsync(1, Drop))
+
+	for i := 0; i < 5; i++ {
+		l.Q(i)
+	}
+
+	// One write is blocked in the writer, one is queued, the rest is dropped,
+	// unless the writer hasn't picked up the first one yet.
+	dropped := l.Dropped()
+	if dropped < 3 || dropped > 4 {
+		t.Fatalf("\nLogger.Dropped()\ngot:  %d\nwant: 3 or 4", dropped)
+	}
+
+	close(w.gate)
+	l.Close()
+
+	if got := strings.Count(w.String(), " i=int("); got != 5-int(dropped) {
+		t.Fatalf("\nLogger.Close()\ngot:  %d records in %q\nwant: %d", got, w.String(), 5-dropped)
+	}
+
+	l.Q("sync")
+	if got := w.String(); !strings.Contains(got, "sync") {
+		t.Fatalf("\nLogger.Q() after Close()\ngot:  %q\nwant: written synchronously", got)
+	}
+
+	if got := l.Dropped(); got != dropped {
+		t.Fatalf("\nLogger.Dropped() after Close()\ngot:  %d\nwant: %d", got, dropped)
+	}
+}
+
+// TestSetAsync verifies that Q writes to the log file in the background after
+// SetAsync, and that Flush waits for it.
+func TestSetAsync(t *testing.T) {
+	name := setTestPath(t)
+	SetAsync(16, Block)
+	t.Cleanup(Close)
+
+	answer := 42
+	Q(answer)
+	Flush()
+
+	if got := readLog(t, name); !strings.Contains(got, "answer") {
+		t.Fatalf("\nFlush()\ngot:  %q\nwant: answer=int(42)", got)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"time"
+)
+
+// BinaryMarker is the first byte of every record in the Binary format. It is
+// followed by the length of the record as a uvarint and the gob encoding of
+// the record. 0xff never occurs in UTF-8 text, so it tells binary records
+// apart from the text formats.
+const BinaryMarker = 0xff
+
+// binaryRecord is the Binary form of a record, decoded by package qlog. Values
+// are pretty-printed, like in the other formats.
+type binaryRecord struct {
+	Time   time.Time
+	Level  int
+	File   string
+	Line   int
+	Func   string
+	PID    int
+	GID    uint64
+	Names  []string
+	Values []string
+	Stack  []string
+}
+
+// outputBinary writes the record to the log buffer in the Binary format.
+func (l *logger) outputBinary(r Record) {
+	b, err := marshalBinary(r)
+	if err != nil {
+		// A record of strings always encodes, fall back to the text format to
+		// be safe.
+		l.outputText(r)
+		return
+	}
+
+	l.buf.Write(b)
+}
+
+// marshalBinary returns the Binary form of the record, see BinaryMarker. Every
+// record is encoded on its own, so that it can be decoded even if the records
+// before it were written by another process or rotated away.
+func marshalBinary(r Record) ([]byte, error) {
+	br := binaryRecord{
+		Time:   r.Time,
+		Level:  int(r.Level),
+		File:   r.File,
+		Line:   r.Line,
+		Func:   r.Func,
+		PID:    os.Getpid(),
+		GID:    r.GID,
+		Names:  r.Names,
+		Values: make([]string, 0, len(r.Values)),
+		Stack:  r.Stack,
+	}
+
+	for _, v := range r.Values {
+		br.Values = append(br.Values, sprint(v))
+	}
+
+	var body bytes.Buffer
+	if err := gob.NewEncoder(&body).Encode(br); err != nil {
+		return nil, fmt.Errorf("marshal q record: %w", err)
+	}
+
+	b := binary.AppendUvarint([]byte{BinaryMarker}, uint64(body.Len()))
+
+	return append(b, body.Bytes()...), nil
+}
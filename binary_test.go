@@ -0,0 +1,44 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"testing"
+)
+
+// TestOutputBinary verifies that the Binary format writes a marker and the
+// length before each gob record, and that stripping colors leaves the records
+// intact.
+func TestOutputBinary(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFormat(Binary), WithColors(false))
+
+	escaped := string(bold) + "bold" + string(endColor)
+	l.Q(escaped)
+
+	b := buf.Bytes()
+	if len(b) == 0 || b[0] != BinaryMarker {
+		t.Fatalf("\ngot:  %q\nwant: record starting with %#x", b, BinaryMarker)
+	}
+
+	n, size := binary.Uvarint(b[1:])
+	if size <= 0 || int(n) != len(b)-1-size {
+		t.Fatalf("\ngot:  length %d of %d bytes\nwant: %d", n, len(b), len(b)-1-size)
+	}
+
+	var got binaryRecord
+	if err := gob.NewDecoder(bytes.NewReader(b[1+size:])).Decode(&got); err != nil {
+		t.Fatalf("gob.Decode(): %v", err)
+	}
+
+	if len(got.Names) != 1 || got.Names[0] != "escaped" || got.Values[0] != escaped {
+		t.Fatalf("\ngot:  %+v\nwant: escaped=%q", got, escaped)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bingoohuang/q"
+	"github.com/bingoohuang/q/qlog"
+)
+
+// isBinary reports whether the next record of r is in the q.Binary format.
+func isBinary(r *bufio.Reader) bool {
+	b, err := r.Peek(1)
+	return err == nil && b[0] == q.BinaryMarker
+}
+
+// entry passes the record of a q.Binary entry to emit, laid out like the text
+// format: under the header of the previous record if it was written by the
+// same goroutine in the same function, and under a new header otherwise.
+func (ps *parser) entry(e qlog.Entry, emit func(record)) {
+	ps.flush(emit)
+
+	file := shortFile(e.File)
+	h := ps.header
+	if h == nil || h.binary != (binaryCaller{e.PID, e.GID}) || h.file != file || h.fn != e.Func {
+		h = &header{
+			time: e.Time,
+			file: file,
+			line: e.Line,
+			fn:   e.Func,
+			text: []string{
+				fmt.Sprintf("[%s %s:%d %s]", e.Time.Format("2006-01-02T15:04:05.000"), file, e.Line, e.Func),
+				fmt.Sprintf("[PID: %d GID: %d]", e.PID, e.GID),
+			},
+			binary: binaryCaller{e.PID, e.GID},
+		}
+		ps.header = h
+	}
+
+	args := make([]string, 0, len(e.Values)+1)
+	if e.Level != q.LevelInfo {
+		args = append(args, e.Level.String())
+	}
+	for i, v := range e.Values {
+		if i < len(e.Names) && e.Names[i] != "" {
+			args = append(args, e.Names[i]+"="+fmt.Sprint(v))
+		} else {
+			args = append(args, fmt.Sprint(v))
+		}
+	}
+
+	s := fmt.Sprintf("%.3fs %s", e.Time.Sub(h.time).Seconds(), strings.Join(args, " "))
+	lines := strings.Split(s, "\n")
+	for _, frame := range e.Stack {
+		lines = append(lines, "    at "+frame)
+	}
+
+	emit(record{header: h, lines: lines})
+}
+
+// binaryCaller tells the headers of q.Binary records apart.
+type binaryCaller struct {
+	pid int
+	gid uint64
+}
+
+// shortFile returns the <directory>/<file> of path, as q writes it in the
+// headers of the text format.
+func shortFile(path string) string {
+	return filepath.Join(filepath.Base(filepath.Dir(path)), filepath.Base(path))
+}
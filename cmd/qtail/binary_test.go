@@ -0,0 +1,64 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/bingoohuang/q"
+)
+
+// TestTailBinary verifies that tail() and readRecords() read the records of a
+// log file in the q.Binary format, laid out like the text format, and skip a
+// record that is still being written.
+func TestTailBinary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "q")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := q.New(q.WithOutput(f), q.WithFormat(q.Binary))
+	port := 443
+	l.Q(port)
+	l.Warn("slow")
+
+	var partial bytes.Buffer
+	q.New(q.WithOutput(&partial), q.WithFormat(q.Binary)).Q(port)
+	if _, err := f.Write(partial.Bytes()[:partial.Len()/2]); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	var buf bytes.Buffer
+	p := &printer{w: bufio.NewWriter(&buf)}
+	if err := tail(path, false, p); err != nil {
+		t.Fatalf("tail(%q): %v", path, err)
+	}
+
+	want := regexp.MustCompile(`^\n` +
+		`\[\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3} qtail/binary_test.go:\d+ \S+\.TestTailBinary\]\n` +
+		`\[PID: \d+ GID: \d+\]\n` +
+		`0\.000s port=int\(443\)\n` +
+		`0\.\d{3}s WARN slow\n$`)
+	if got := buf.String(); !want.MatchString(got) {
+		t.Fatalf("\ntail()\ngot:  %q\nwant: %q", got, want)
+	}
+
+	records, err := readRecords(path)
+	if err != nil {
+		t.Fatalf("readRecords(%q): %v", path, err)
+	}
+	if len(records) != 2 || records[0].header != records[1].header || records[0].time().IsZero() {
+		t.Fatalf("\nreadRecords(%q)\ngot:  %+v\nwant: 2 records under one header", path, records)
+	}
+}
@@ -0,0 +1,453 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+// Command qtail follows the q log file and prints its records, optionally
+// filtered by function, file, time or content:
+//
+//	qtail [-func name] [-file name] [-since 5m] [-grep regexp] [path]
+//
+// Records in the q.Binary format are printed like those of the text format.
+// Without a path, it reads the same file as q.Q: $Q_LOGFILE, $Q_OUTPUT if it
+// is a path, otherwise $TMPDIR/q.$USER. The timestamps of the headers are
+// parsed in the layout of $Q_TIME_FORMAT, like q writes them.
+//
+// With -web, it serves a page to browse the records of the log files and of
+// their rotated files instead, searchable and filterable like the output:
+//
+//	qtail -web localhost:7071 [path...]
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bingoohuang/q/qlog"
+)
+
+const (
+	bold     = "\033[1m"
+	yellow   = "\033[33m"
+	endColor = "\033[0m"
+
+	pollInterval = 200 * time.Millisecond
+)
+
+// nolint: gochecknoglobals
+var (
+	colorRe  = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	headerRe = regexp.MustCompile(`^\[(.+) (\S+):(\d+) (\S+)\]$`) // the time may have spaces
+	recordRe = regexp.MustCompile(`^(\d+\.\d{3}s) `)
+)
+
+// header is the caller info of a group of records.
+type header struct {
+	time time.Time
+	file string // <directory>/<file>, as written by q
+	line int
+	fn   string
+	text []string // the header lines as written, without colors
+
+	binary binaryCaller // writer of q.Binary records, zero for text
+}
+
+// record is a single record and the header it was written under.
+type record struct {
+	header *header
+	lines  []string
+}
+
+// filter selects the records to print. Empty fields match everything.
+type filter struct {
+	fn    string
+	file  string
+	since time.Time
+	until time.Time
+	grep  *regexp.Regexp
+}
+
+func main() {
+	var (
+		f       filter
+		since   time.Duration
+		grep    string
+		follow  bool
+		noColor bool
+		web     string
+	)
+
+	flag.StringVar(&f.fn, "func", "", "only records of functions containing `name`")
+	flag.StringVar(&f.file, "file", "", "only records of files containing `name`")
+	flag.DurationVar(&since, "since", 0, "only records of the last `duration`, e.g. 5m")
+	flag.StringVar(&grep, "grep", "", "only records matching the `regexp`")
+	flag.BoolVar(&follow, "follow", true, "wait for new records at the end of the file")
+	flag.BoolVar(&noColor, "no-color", os.Getenv("NO_COLOR") != "", "don't colorize the output")
+	flag.StringVar(&web, "web", "", "serve a page to browse the records at `addr`, e.g. localhost:7071")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: qtail [flags] [path]\n       qtail -web addr [path...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if since > 0 {
+		f.since = time.Now().Add(-since)
+	}
+
+	if grep != "" {
+		re, err := regexp.Compile(grep)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "qtail: invalid -grep:", err)
+			os.Exit(2)
+		}
+		f.grep = re
+	}
+
+	path := logPath()
+	if flag.NArg() > 0 {
+		path = flag.Arg(0)
+	}
+
+	if web != "" {
+		paths := flag.Args()
+		if len(paths) == 0 {
+			paths = []string{path}
+		}
+
+		fmt.Fprintf(os.Stderr, "qtail: serving %s on http://%s\n", strings.Join(paths, ", "), web)
+		if err := http.ListenAndServe(web, newIndex(paths)); err != nil { // nolint: gosec
+			fmt.Fprintln(os.Stderr, "qtail:", err)
+			os.Exit(1)
+		}
+	}
+
+	p := &printer{w: bufio.NewWriter(os.Stdout), filter: f, color: !noColor}
+	if err := tail(path, follow, p); err != nil {
+		fmt.Fprintln(os.Stderr, "qtail:", err)
+		os.Exit(1)
+	}
+}
+
+// logPath returns the path of the log file written by q.Q.
+func logPath() string {
+	if p := os.Getenv("Q_LOGFILE"); p != "" {
+		return p
+	}
+
+	if p := os.Getenv("Q_OUTPUT"); p != "" && p != "stderr" && p != "stdout" {
+		return p
+	}
+
+	u, _ := user.LookupId(strconv.Itoa(os.Getuid()))
+	if runtime.GOOS == "windows" {
+		u, _ = user.Current() // os.Getuid is always -1 on Windows
+	}
+
+	if u != nil {
+		name := u.Username
+		if i := strings.LastIndexByte(name, '\\'); i >= 0 {
+			name = name[i+1:] // DOMAIN\alice
+		}
+
+		return filepath.Join(os.TempDir(), "q."+name)
+	}
+
+	return filepath.Join(os.TempDir(), "q")
+}
+
+// tail parses the file at path and passes its records to p. If follow is set,
+// it waits for new records and reopens the file when it was rotated or
+// truncated, until it fails.
+func tail(path string, follow bool, p *printer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	r := bufio.NewReader(f)
+	lr := qlog.NewReader(r) // reads through r, see bufio.NewReader
+	var (
+		ps      parser
+		partial string
+	)
+
+	for {
+		if partial == "" && isBinary(r) {
+			err = readEntry(f, r, lr, func(e qlog.Entry) { ps.entry(e, p.print) })
+		} else {
+			var s string
+			s, err = r.ReadString('\n')
+			if err == nil {
+				ps.line(strings.TrimSuffix(partial+s, "\n"), p.print)
+				partial = ""
+
+				continue
+			}
+			partial += s
+		}
+
+		if err == nil {
+			continue
+		}
+
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		// q writes a whole record at once, so the last one is complete.
+		ps.flush(p.print)
+		if err := p.w.Flush(); err != nil {
+			return err
+		}
+
+		if !follow {
+			return nil
+		}
+
+		time.Sleep(pollInterval)
+
+		offset, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+
+		if replaced(f, path, offset) {
+			nf, err := os.Open(path)
+			if err != nil {
+				continue // between the rename and the creation of the new file
+			}
+
+			f.Close()
+			f, partial = nf, ""
+			r.Reset(f)
+		}
+	}
+}
+
+// readEntry decodes the next q.Binary record of f, read through r and lr, and
+// passes it to emit. A record that is still being written is left to be read
+// again, returning io.EOF.
+func readEntry(f *os.File, r *bufio.Reader, lr *qlog.Reader, emit func(qlog.Entry)) error {
+	start, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	start -= int64(r.Buffered())
+
+	e, err := lr.Read()
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		if _, err := f.Seek(start, io.SeekStart); err != nil {
+			return err
+		}
+		r.Reset(f)
+
+		return io.EOF
+	}
+
+	if err != nil {
+		return err
+	}
+	emit(e)
+
+	return nil
+}
+
+// replaced reports whether the file at path is not f anymore, or is shorter
+// than the offset read so far, i.e. it was rotated or truncated.
+func replaced(f *os.File, path string, offset int64) bool {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	cur, err := f.Stat()
+	if err != nil {
+		return true
+	}
+
+	return !os.SameFile(fi, cur) || fi.Size() < offset
+}
+
+// parser splits the lines of a q log file into records.
+type parser struct {
+	header *header
+	rec    *record
+}
+
+// line parses the next line, passing every completed record to emit.
+func (ps *parser) line(s string, emit func(record)) {
+	s = colorRe.ReplaceAllString(s, "")
+
+	switch {
+	case s == "":
+		ps.flush(emit)
+	case headerRe.MatchString(s):
+		ps.flush(emit)
+		ps.header = parseHeader(s)
+	case strings.HasPrefix(s, "[PID: ") && ps.rec == nil && ps.header != nil:
+		ps.header.text = append(ps.header.text, s)
+	case recordRe.MatchString(s):
+		ps.flush(emit)
+		ps.rec = &record{header: ps.header, lines: []string{s}}
+	default:
+		// Wrapped lines, multi-line values and stack frames.
+		if ps.rec == nil {
+			ps.rec = &record{header: ps.header}
+		}
+		ps.rec.lines = append(ps.rec.lines, s)
+	}
+}
+
+// flush passes the pending record, if any, to emit.
+func (ps *parser) flush(emit func(record)) {
+	if ps.rec != nil {
+		emit(*ps.rec)
+		ps.rec = nil
+	}
+}
+
+// parseHeader parses a header line like
+// [2006-01-02T15:04:05.000 main/main.go:42 main.main].
+func parseHeader(s string) *header {
+	m := headerRe.FindStringSubmatch(s)
+	h := &header{file: m[2], fn: m[4], text: []string{s}}
+	h.line, _ = strconv.Atoi(m[3])
+	h.time = parseTime(m[1])
+
+	return h
+}
+
+// parseTime parses the timestamp of a header in the layout of $Q_TIME_FORMAT
+// and $Q_TIME_LOCATION, like q does, in the default time format of q, in RFC
+// 3339 format or as milliseconds since the UNIX epoch, see q.SetTimeFormat.
+// A layout without a year, like time.Stamp, means the current year. It
+// returns the zero time for other formats.
+func parseTime(s string) time.Time {
+	if layout := os.Getenv("Q_TIME_FORMAT"); layout != "" && layout != "unixmilli" {
+		loc := time.Local
+		if name := os.Getenv("Q_TIME_LOCATION"); name != "" {
+			if l, err := time.LoadLocation(name); err == nil {
+				loc = l
+			}
+		}
+
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			if t.Year() == 0 {
+				t = t.AddDate(time.Now().In(loc).Year(), 0, 0)
+			}
+
+			return t
+		}
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05.000", s, time.Local); err == nil {
+		return t
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t
+	}
+
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms)
+	}
+
+	return time.Time{}
+}
+
+// match reports whether the record passes the filter.
+func (f filter) match(r record) bool {
+	h := r.header
+	if h == nil {
+		h = &header{}
+	}
+
+	if f.fn != "" && !strings.Contains(h.fn, f.fn) {
+		return false
+	}
+
+	if f.file != "" && !strings.Contains(h.file, f.file) {
+		return false
+	}
+
+	if !f.since.IsZero() && h.time.Before(f.since) {
+		return false
+	}
+
+	if !f.until.IsZero() && !h.time.Before(f.until) {
+		return false
+	}
+
+	if f.grep != nil && !f.grep.MatchString(strings.Join(r.lines, "\n")) {
+		return false
+	}
+
+	return true
+}
+
+// time returns the time of the record: that of its header plus its elapsed
+// time, or the zero time if the header has none.
+func (r record) time() time.Time {
+	if r.header == nil || r.header.time.IsZero() {
+		return time.Time{}
+	}
+
+	if len(r.lines) > 0 {
+		if m := recordRe.FindStringSubmatch(r.lines[0]); m != nil {
+			d, _ := time.ParseDuration(m[1])
+			return r.header.time.Add(d)
+		}
+	}
+
+	return r.header.time
+}
+
+// printer writes the records that pass its filter, each group under its
+// header.
+type printer struct {
+	w      *bufio.Writer
+	filter filter
+	color  bool
+	last   *header // header of the last printed record
+}
+
+func (p *printer) print(r record) {
+	if !p.filter.match(r) {
+		return
+	}
+
+	if r.header != nil && r.header != p.last {
+		p.last = r.header
+		fmt.Fprintln(p.w)
+		for _, s := range r.header.text {
+			fmt.Fprintln(p.w, p.colorize(s, bold))
+		}
+	}
+
+	for _, s := range r.lines {
+		if m := recordRe.FindStringSubmatch(s); m != nil {
+			s = p.colorize(m[1], yellow) + s[len(m[1]):]
+		}
+		fmt.Fprintln(p.w, s)
+	}
+}
+
+func (p *printer) colorize(s, c string) string {
+	if !p.color {
+		return s
+	}
+
+	return c + s + endColor
+}
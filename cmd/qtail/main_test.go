@@ -0,0 +1,149 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+const (
+	mainGroup = "\n" +
+		"[2024-01-02T15:04:05.000 main/main.go:10 main.main]\n" +
+		"[PID: 1 GID: 1 os.Args: main]\n" +
+		"0.000s port=int(443)\n" +
+		"0.001s s=\"a very long value\"\n" +
+		"       wrapped=true\n"
+
+	handlerGroup = "\n" +
+		"[2024-01-02T15:04:09.000 server/handler.go:20 server.handle]\n" +
+		"[PID: 1 GID: 7 os.Args: main]\n" +
+		"0.000s req=GET /\n"
+
+	// testLog is mainGroup and handlerGroup as written by q, with colors.
+	testLog = "\n" +
+		"[2024-01-02T15:04:05.000 main/main.go:10 main.main]\n" +
+		"[PID: 1 GID: 1 os.Args: main]\n" +
+		"\x1b[33m0.000s\x1b[0m \x1b[1mport\x1b[0m=\x1b[36mint(443)\x1b[0m\n" +
+		"0.001s s=\"a very long value\"\n" +
+		"       wrapped=true\n" +
+		handlerGroup
+)
+
+// TestTail verifies that tail() prints the records of a log file that
+// pass the filter, under their headers and without the original colors.
+func TestTail(t *testing.T) {
+	testCases := []struct {
+		filter filter
+		want   string
+	}{
+		{
+			filter: filter{},
+			want:   mainGroup + handlerGroup,
+		},
+		{
+			filter: filter{fn: "handle"},
+			want:   handlerGroup,
+		},
+		{
+			filter: filter{file: "main/"},
+			want:   mainGroup,
+		},
+		{
+			filter: filter{grep: regexp.MustCompile("wrapped")},
+			want: "\n" +
+				"[2024-01-02T15:04:05.000 main/main.go:10 main.main]\n" +
+				"[PID: 1 GID: 1 os.Args: main]\n" +
+				"0.001s s=\"a very long value\"\n" +
+				"       wrapped=true\n",
+		},
+		{
+			filter: filter{since: time.Date(2024, 1, 2, 15, 4, 6, 0, time.Local)},
+			want:   handlerGroup,
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "q")
+	if err := os.WriteFile(path, []byte(testLog), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		p := &printer{w: bufio.NewWriter(&buf), filter: tc.filter}
+		if err := tail(path, false, p); err != nil {
+			t.Fatalf("tail(%q): %v", path, err)
+		}
+
+		if got := buf.String(); got != tc.want {
+			t.Fatalf("\ntail(%+v)\ngot:  %q\nwant: %q", tc.filter, got, tc.want)
+		}
+	}
+}
+
+// TestTailTimeFormat verifies that tail() parses the headers written with a
+// time format of $Q_TIME_FORMAT that has spaces.
+func TestTailTimeFormat(t *testing.T) {
+	t.Setenv("Q_TIME_FORMAT", time.StampMilli)
+	group := "\n" +
+		"[Jan  2 15:04:05.000 main/main.go:10 main.main]\n" +
+		"[PID: 1 GID: 1 os.Args: main]\n" +
+		"0.000s port=int(443)\n"
+
+	path := filepath.Join(t.TempDir(), "q")
+	if err := os.WriteFile(path, []byte(group+handlerGroup), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	p := &printer{w: bufio.NewWriter(&buf), filter: filter{fn: "main.main"}}
+	if err := tail(path, false, p); err != nil {
+		t.Fatalf("tail(%q): %v", path, err)
+	}
+
+	if got := buf.String(); got != group {
+		t.Fatalf("\ntail(-func main.main)\ngot:  %q\nwant: %q", got, group)
+	}
+
+	records, err := readRecords(path)
+	if err != nil {
+		t.Fatalf("readRecords(%q): %v", path, err)
+	}
+	if h := records[0].header; h.file != "main/main.go" || h.line != 10 || h.time.Month() != time.January || h.time.Day() != 2 {
+		t.Fatalf("\nheader\ngot:  %+v\nwant: main/main.go:10 on January 2", h)
+	}
+}
+
+// TestParseTime verifies that parseTime() parses the header timestamps of the
+// time formats of q.
+func TestParseTime(t *testing.T) {
+	want := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	for _, s := range []string{
+		want.Local().Format("2006-01-02T15:04:05.000"),
+		"2024-01-02T15:04:05Z",
+		"1704207845000",
+	} {
+		if got := parseTime(s); !got.Equal(want) {
+			t.Fatalf("\nparseTime(%q)\ngot:  %v\nwant: %v", s, got, want)
+		}
+	}
+
+	// Layouts of $Q_TIME_FORMAT may have spaces and lack the year.
+	t.Setenv("Q_TIME_FORMAT", time.StampMilli)
+	t.Setenv("Q_TIME_LOCATION", "UTC")
+	want = time.Date(time.Now().UTC().Year(), 1, 2, 15, 4, 5, 0, time.UTC)
+	if got := parseTime("Jan  2 15:04:05.000"); !got.Equal(want) {
+		t.Fatalf("\nparseTime(%q)\ngot:  %v\nwant: %v", "Jan  2 15:04:05.000", got, want)
+	}
+
+	if got := parseTime("bogus"); !got.IsZero() {
+		t.Fatalf("\nparseTime(%q)\ngot:  %v\nwant: zero time", "bogus", got)
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	_ "embed" // for the web page
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bingoohuang/q/qlog"
+)
+
+// maxWebRecords is the number of records sent to the web page at most, the
+// latest of those that pass the filter.
+const maxWebRecords = 1000
+
+//go:embed web.html
+var webPage []byte // nolint: gochecknoglobals
+
+// index holds the records of the log files served by -web, reloaded when the
+// files change.
+type index struct {
+	mu      sync.Mutex // protects the fields below
+	paths   []string   // log files, each followed by its rotated files
+	stamps  []string   // size and modification time of the files at the last load
+	records []record   // oldest first
+	files   []string   // distinct files of the records, sorted
+	funcs   []string   // distinct functions of the records, sorted
+}
+
+// webRecord is the JSON form of a record sent to the web page.
+type webRecord struct {
+	Time   string   `json:"time"`
+	File   string   `json:"file"`
+	Line   int      `json:"line"`
+	Func   string   `json:"func"`
+	Header []string `json:"header"` // the header lines, to group records
+	Lines  []string `json:"lines"`
+}
+
+// webResult is the answer to a query of the web page.
+type webResult struct {
+	Records []webRecord `json:"records"`
+	Total   int         `json:"total"` // of the records that pass the filter
+	Files   []string    `json:"files"`
+	Funcs   []string    `json:"funcs"`
+}
+
+// newIndex returns an index of the log files at paths and their rotated
+// files, path.1, path.2 and so on, loaded on first use.
+func newIndex(paths []string) *index {
+	ix := &index{}
+	for _, p := range paths {
+		var backups []string
+		for i := 1; ; i++ {
+			b := p + "." + strconv.Itoa(i)
+			if _, err := os.Stat(b); err != nil {
+				break
+			}
+			backups = append(backups, b)
+		}
+
+		// Oldest first.
+		for i := len(backups) - 1; i >= 0; i-- {
+			ix.paths = append(ix.paths, backups[i])
+		}
+		ix.paths = append(ix.paths, p)
+	}
+
+	return ix
+}
+
+// load reads the log files again if they changed since the last load.
+// ix.mu must be held.
+func (ix *index) load() error {
+	stamps := make([]string, len(ix.paths))
+	for i, p := range ix.paths {
+		if fi, err := os.Stat(p); err == nil {
+			stamps[i] = strconv.FormatInt(fi.Size(), 10) + " " + fi.ModTime().String()
+		}
+	}
+
+	if ix.records != nil && strings.Join(stamps, "\n") == strings.Join(ix.stamps, "\n") {
+		return nil
+	}
+
+	records := []record{}
+	for i, p := range ix.paths {
+		if stamps[i] == "" {
+			continue // rotated away in the meantime
+		}
+
+		recs, err := readRecords(p)
+		if err != nil {
+			return err
+		}
+		records = append(records, recs...)
+	}
+
+	// The files of several paths interleave.
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].time().Before(records[j].time())
+	})
+
+	files, funcs := map[string]bool{}, map[string]bool{}
+	for _, r := range records {
+		if r.header != nil {
+			files[r.header.file], funcs[r.header.fn] = true, true
+		}
+	}
+
+	ix.stamps, ix.records = stamps, records
+	ix.files, ix.funcs = sortedKeys(files), sortedKeys(funcs)
+
+	return nil
+}
+
+// query returns the latest records that pass the filter.
+func (ix *index) query(f filter) (webResult, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if err := ix.load(); err != nil {
+		return webResult{}, err
+	}
+
+	res := webResult{Records: []webRecord{}, Files: ix.files, Funcs: ix.funcs}
+	for _, r := range ix.records {
+		if !f.match(r) {
+			continue
+		}
+
+		res.Total++
+		wr := webRecord{Lines: r.lines}
+		if t := r.time(); !t.IsZero() {
+			wr.Time = t.Format("2006-01-02T15:04:05.000")
+		}
+		if h := r.header; h != nil {
+			wr.File, wr.Line, wr.Func, wr.Header = h.file, h.line, h.fn, h.text
+		}
+		res.Records = append(res.Records, wr)
+	}
+
+	if len(res.Records) > maxWebRecords {
+		res.Records = res.Records[len(res.Records)-maxWebRecords:]
+	}
+
+	return res, nil
+}
+
+// ServeHTTP serves the web page at / and the records passing the filter of
+// the query parameters func, file, since, until and grep at /records.
+func (ix *index) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(webPage)
+	case "/records":
+		f, err := queryFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := ix.query(f)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// queryFilter returns the filter of the query parameters of r. since and until
+// are times like those of the headers, or durations before now like -since.
+func queryFilter(r *http.Request) (filter, error) {
+	q := r.URL.Query()
+	f := filter{fn: q.Get("func"), file: q.Get("file")}
+
+	var err error
+	if f.since, err = queryTime(q.Get("since")); err != nil {
+		return f, err
+	}
+	if f.until, err = queryTime(q.Get("until")); err != nil {
+		return f, err
+	}
+
+	if grep := q.Get("grep"); grep != "" {
+		if f.grep, err = regexp.Compile(grep); err != nil {
+			return f, err
+		}
+	}
+
+	return f, nil
+}
+
+// queryTime parses the time s of a query, see queryFilter. It returns the zero
+// time for an empty s.
+func queryTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	// As sent by <input type="datetime-local">.
+	if t, err := time.ParseInLocation("2006-01-02T15:04", s, time.Local); err == nil {
+		return t, nil
+	}
+
+	if t := parseTime(s); !t.IsZero() {
+		return t, nil
+	}
+
+	return time.Time{}, errors.New("invalid time " + strconv.Quote(s))
+}
+
+// readRecords returns the records of the log file at path.
+func readRecords(path string) ([]record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		ps      parser
+		records []record
+	)
+	emit := func(r record) { records = append(records, r) }
+
+	r := bufio.NewReader(f)
+	lr := qlog.NewReader(r) // reads through r, see bufio.NewReader
+	for {
+		if isBinary(r) {
+			e, err := lr.Read()
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				ps.flush(emit)
+				return records, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			ps.entry(e, emit)
+			continue
+		}
+
+		s, err := r.ReadString('\n')
+		if s != "" {
+			ps.line(strings.TrimSuffix(s, "\n"), emit)
+		}
+
+		if errors.Is(err, io.EOF) {
+			ps.flush(emit)
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
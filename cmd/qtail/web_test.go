@@ -0,0 +1,129 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestWeb verifies that the index serves the web page and the records of the
+// log file and its rotated files that pass the filter of the query.
+func TestWeb(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "q")
+	if err := os.WriteFile(path+".1", []byte(testLog), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	newer := "\n[2024-01-02T16:00:00.000 server/handler.go:30 server.close]\n0.500s done=true\n"
+	if err := os.WriteFile(path, []byte(newer), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(newIndex([]string{path}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET /: %v", err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(page), `fetch("records?"`) {
+		t.Fatalf("\nGET /\ngot:  %.100q\nwant: the web page", page)
+	}
+
+	testCases := []struct {
+		query string
+		want  []string // the first lines of the records
+	}{
+		{"", []string{"0.000s port=int(443)", "0.001s s=\"a very long value\"", "0.000s req=GET /", "0.500s done=true"}},
+		{"func=handle", []string{"0.000s req=GET /"}},
+		{"file=server/&grep=done", []string{"0.500s done=true"}},
+		{"since=2024-01-02T15:04:06.000&until=2024-01-02T16:00", []string{"0.000s req=GET /"}},
+	}
+
+	for _, tc := range testCases {
+		var res webResult
+		get(t, srv.URL+"/records?"+tc.query, &res)
+
+		var got []string
+		for _, r := range res.Records {
+			got = append(got, r.Lines[0])
+		}
+		if !reflect.DeepEqual(got, tc.want) || res.Total != len(tc.want) {
+			t.Fatalf("\nGET /records?%s\ngot:  %q (%d)\nwant: %q", tc.query, got, res.Total, tc.want)
+		}
+	}
+
+	var res webResult
+	get(t, srv.URL+"/records?grep=wrapped", &res)
+	want := webRecord{
+		Time:   "2024-01-02T15:04:05.001",
+		File:   "main/main.go",
+		Line:   10,
+		Func:   "main.main",
+		Header: []string{"[2024-01-02T15:04:05.000 main/main.go:10 main.main]", "[PID: 1 GID: 1 os.Args: main]"},
+		Lines:  []string{"0.001s s=\"a very long value\"", "       wrapped=true"},
+	}
+	if len(res.Records) != 1 || !reflect.DeepEqual(res.Records[0], want) {
+		t.Fatalf("\nGET /records?grep=wrapped\ngot:  %+v\nwant: %+v", res.Records, want)
+	}
+	if !reflect.DeepEqual(res.Funcs, []string{"main.main", "server.close", "server.handle"}) {
+		t.Fatalf("\nfuncs\ngot:  %q\nwant: the functions of all records", res.Funcs)
+	}
+
+	resp, err = http.Get(srv.URL + "/records?grep=" + url.QueryEscape("("))
+	if err != nil {
+		t.Fatalf("GET /records: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("\nGET /records?grep=(\ngot:  %d\nwant: %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+// TestWebReload verifies that the index reads the log file again when it
+// changes.
+func TestWebReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "q")
+	if err := os.WriteFile(path, []byte(handlerGroup), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ix := newIndex([]string{path})
+	if res, err := ix.query(filter{}); err != nil || res.Total != 1 {
+		t.Fatalf("query() = %d records, %v; want 1", res.Total, err)
+	}
+
+	if err := os.WriteFile(path, []byte(testLog), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := ix.query(filter{}); err != nil || res.Total != 3 {
+		t.Fatalf("query() = %d records, %v; want 3", res.Total, err)
+	}
+}
+
+// get decodes the JSON answer to a GET of u into v.
+func get(t *testing.T, u string, v interface{}) {
+	t.Helper()
+
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatalf("GET %s: %v", u, err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s: %v", u, err)
+	}
+}
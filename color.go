@@ -0,0 +1,55 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"io"
+	"os"
+)
+
+// SetColors enables or disables ANSI colors in the output of Q and its
+// variants. Colors are enabled by default, unless $NO_COLOR is set to a
+// non-empty value (see https://no-color.org).
+func SetColors(enabled bool) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.noColor = !enabled
+	if enabled {
+		enableTerminalColors(std.out)
+		enableTerminalColors(std.tee)
+	}
+}
+
+// SetHighlight makes Q highlight the syntax of the printed values, with type
+// names, field names, strings and numbers in distinct colors, instead of
+// printing each value in a single color. Like all colors, the highlighting
+// is left out while colors are disabled.
+func SetHighlight(enabled bool) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.highlight = enabled
+}
+
+// WithHighlight makes the Logger highlight the syntax of the printed values,
+// like SetHighlight.
+func WithHighlight(enabled bool) Option {
+	return func(l *logger) { l.highlight = enabled }
+}
+
+// envNoColor reports whether the NO_COLOR convention asks for no colors.
+func envNoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// enableTerminalColors prepares w to render ANSI escape codes if it is a
+// console. This is only needed on Windows, where virtual terminal processing
+// must be turned on explicitly.
+func enableTerminalColors(w io.Writer) {
+	if f, ok := w.(*os.File); ok {
+		enableVirtualTerminal(f)
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package q
+
+import "os"
+
+// enableVirtualTerminal does nothing: terminals outside of Windows render ANSI
+// escape codes without further setup.
+func enableVirtualTerminal(*os.File) {}
@@ -0,0 +1,56 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestNoColor verifies that a non-empty $NO_COLOR disables colors of new
+// loggers, unless they are enabled explicitly.
+func TestNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	var buf bytes.Buffer
+	New(WithOutput(&buf)).Q(1)
+	if got := buf.String(); strings.Contains(got, "\033[") {
+		t.Fatalf("\nNO_COLOR=1\ngot:  %q\nwant: no ANSI escape codes", got)
+	}
+
+	buf.Reset()
+	New(WithOutput(&buf), WithColors(true)).Q(1)
+	if got := buf.String(); !strings.Contains(got, string(cyan)) {
+		t.Fatalf("\nNO_COLOR=1, WithColors(true)\ngot:  %q\nwant: ANSI escape codes", got)
+	}
+}
+
+// TestHighlight verifies that WithHighlight colors the parts of values, and
+// that disabling colors removes the highlighting as well.
+func TestHighlight(t *testing.T) {
+	type point struct{ X, Y int }
+
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(true), WithHighlight(true))
+	l.Q(point{1, 2}, "msg")
+	got := buf.String()
+	want := "=" + string(cyan) + "q.point" + string(endColor) + "{" +
+		string(fieldColor) + "X" + string(endColor) + ":" + string(numberColor) + "1" + string(endColor)
+	if !strings.Contains(got, want) {
+		t.Fatalf("\nWithHighlight(true)\ngot:  %q\nwant: %q", got, want)
+	}
+	if !strings.Contains(got, colorize("msg", cyan)) {
+		t.Fatalf("\nWithHighlight(true)\ngot:  %q\nwant: %q", got, colorize("msg", cyan))
+	}
+
+	buf.Reset()
+	New(WithOutput(&buf), WithColors(false), WithHighlight(true)).Q(point{1, 2})
+	if got := buf.String(); strings.Contains(got, "\033[") || !strings.Contains(got, "q.point{X:1, Y:2}") {
+		t.Fatalf("\nWithColors(false), WithHighlight(true)\ngot:  %q\nwant: no ANSI escape codes", got)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanEventName is the name of the span events recorded by Ctx.
+const spanEventName = "q"
+
+// ContextExtractor returns a value of a context to prefix the records of Ctx
+// with, e.g. a request ID. ok is false if ctx doesn't have the value.
+type ContextExtractor func(ctx context.Context) (value string, ok bool)
+
+// contextValue is a ContextExtractor with the name its value is logged as.
+type contextValue struct {
+	name    string
+	extract ContextExtractor
+}
+
+// AddContextExtractor makes Ctx prefix its records with name=value for the
+// value returned by f, e.g.
+//
+//	q.AddContextExtractor("request_id", q.ContextValue(requestIDKey{}))
+func AddContextExtractor(name string, f ContextExtractor) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.ctxValues = append(std.ctxValues, contextValue{name: name, extract: f})
+}
+
+// WithContextExtractor makes the Logger's Ctx prefix its records with
+// name=value for the value returned by f, like AddContextExtractor.
+func WithContextExtractor(name string, f ContextExtractor) Option {
+	return func(l *logger) {
+		l.ctxValues = append(l.ctxValues, contextValue{name: name, extract: f})
+	}
+}
+
+// ContextValue returns a ContextExtractor for the value of ctx.Value(key).
+func ContextValue(key interface{}) ContextExtractor {
+	return func(ctx context.Context) (string, bool) {
+		v := ctx.Value(key)
+		if v == nil {
+			return "", false
+		}
+
+		return fmt.Sprint(v), true
+	}
+}
+
+// traceID returns the OpenTelemetry trace ID of the span of ctx.
+func traceID(ctx context.Context) (string, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return "", false
+	}
+
+	return sc.TraceID().String(), true
+}
+
+// Ctx pretty-prints the given arguments to the $TMPDIR/$USER.q log file, like
+// Q, prefixed with the trace ID of ctx as trace_id=..., if any, and the values
+// of the extractors added with AddContextExtractor. This correlates the output
+// of the goroutines handling the same request. If ctx carries a recording
+// OpenTelemetry span, the values are also added to it as an event, with one
+// attribute per argument, e.g. port=int(443).
+func Ctx(ctx context.Context, v ...interface{}) {
+	if off {
+		return
+	}
+
+	std.ctx(CallDepth, ctx, v)
+}
+
+// Ctx pretty-prints the given arguments to the Logger's output, and adds them
+// to the span of ctx like Ctx.
+func (l *Logger) Ctx(ctx context.Context, v ...interface{}) {
+	if off {
+		return
+	}
+
+	l.ctx(CallDepth, ctx, v)
+}
+
+func (l *logger) ctx(callDepth int, ctx context.Context, v []interface{}) {
+	if l.skip(LevelInfo) {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.labelCtx = ctx
+	defer func() { l.labelCtx = nil }()
+
+	r, ok := l.newRecord(callDepth+1, v, 1) // skip ctx
+	if !ok {
+		return
+	}
+
+	l.prefixContext(ctx, &r)
+	l.write(r)
+	addSpanEvent(ctx, r)
+}
+
+// prefixContext prepends the trace ID and the extracted values of ctx to the
+// values of the record.
+func (l *logger) prefixContext(ctx context.Context, r *Record) {
+	var names []string
+	var values []interface{}
+	if id, ok := traceID(ctx); ok {
+		names, values = append(names, "trace_id"), append(values, id)
+	}
+
+	for _, cv := range l.ctxValues {
+		if v, ok := cv.extract(ctx); ok {
+			names, values = append(names, cv.name), append(values, v)
+		}
+	}
+
+	r.prepend(names, values)
+}
+
+// addSpanEvent adds the record to the span of ctx as an event, if the span
+// is recording.
+func addSpanEvent(ctx context.Context, r Record) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(r.Values)+3)
+	if r.File != "" {
+		attrs = append(attrs,
+			attribute.String("code.filepath", r.File),
+			attribute.Int("code.lineno", r.Line),
+			attribute.String("code.function", r.Func))
+	}
+
+	for i, v := range r.Values {
+		key := "arg" + strconv.Itoa(i)
+		if i < len(r.Names) && r.Names[i] != "" {
+			key = r.Names[i]
+		}
+		attrs = append(attrs, attribute.String(key, sprint(v)))
+	}
+
+	span.AddEvent(spanEventName, trace.WithTimestamp(r.Time), trace.WithAttributes(attrs...))
+}
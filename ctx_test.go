@@ -0,0 +1,94 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingSpan is a span that records the attributes of its events.
+type recordingSpan struct {
+	noop.Span
+	events map[string][]attribute.KeyValue
+}
+
+func (s *recordingSpan) IsRecording() bool { return true }
+
+func (s *recordingSpan) AddEvent(name string, opts ...trace.EventOption) {
+	cfg := trace.NewEventConfig(opts...)
+	s.events[name] = cfg.Attributes()
+}
+
+// TestCtx verifies that Ctx() writes the values like Q() and adds them to the
+// span of the context as an event.
+func TestCtx(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false))
+	span := &recordingSpan{events: map[string][]attribute.KeyValue{}}
+	ctx := trace.ContextWithSpan(context.Background(), span)
+
+	port := 443
+	l.Ctx(ctx, port)
+
+	if got := buf.String(); !strings.Contains(got, " port=int(443)\n") {
+		t.Fatalf("\nl.Ctx(ctx, port)\ngot:  %q\nwant: port=int(443)", got)
+	}
+
+	attrs := attribute.NewSet(span.events[spanEventName]...)
+	if v, _ := attrs.Value("port"); v.AsString() != "int(443)" {
+		t.Fatalf("\nevent attributes\ngot:  %v\nwant: port=int(443)", attrs.Encoded(attribute.DefaultEncoder()))
+	}
+
+	if v, _ := attrs.Value("code.function"); v.AsString() != "github.com/bingoohuang/q.TestCtx" {
+		t.Fatalf("\nevent attributes\ngot:  %v\nwant: code.function of TestCtx", attrs.Encoded(attribute.DefaultEncoder()))
+	}
+}
+
+// TestCtxNoSpan verifies that Ctx() works without a span in the context.
+func TestCtxNoSpan(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false))
+
+	l.Ctx(context.Background(), "hello")
+
+	if got := buf.String(); !strings.Contains(got, " hello\n") {
+		t.Fatalf("\nl.Ctx(ctx, \"hello\")\ngot:  %q\nwant: hello", got)
+	}
+}
+
+type requestIDKey struct{}
+
+// TestCtxValues verifies that Ctx() prefixes the record with the trace ID and
+// the values of the context extractors.
+func TestCtxValues(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false),
+		WithContextExtractor("request_id", ContextValue(requestIDKey{})),
+		WithContextExtractor("user", ContextValue("missing")))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:  trace.SpanID{1},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	ctx = context.WithValue(ctx, requestIDKey{}, "r-42")
+
+	port := 443
+	l.Ctx(ctx, port)
+
+	want := " trace_id=4bf92f35000000000000000000000000 request_id=r-42 port=int(443)\n"
+	if got := buf.String(); !strings.Contains(got, want) {
+		t.Fatalf("\nl.Ctx(ctx, port)\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SetDedup makes Q and its variants collapse a run of identical records of
+// the same call site, like the kernel log does: only the first record is
+// written, followed by a "… repeated 134×" record when the run ends, before
+// the next different record or on Flush. It is disabled by default.
+func SetDedup(enabled bool) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.dedup = enabled
+	if !enabled {
+		std.writeRepeats()
+	}
+}
+
+// WithDedup makes the Logger collapse runs of identical records, see
+// SetDedup.
+func WithDedup(enabled bool) Option {
+	return func(l *logger) { l.dedup = enabled }
+}
+
+// repeated reports whether r is identical to the previous record and counts
+// it if so. Otherwise, it writes the count of the previous run, if any.
+// l.mu must be held.
+func (l *logger) repeated(r Record) bool {
+	if !l.dedup || r.File == "" {
+		return false
+	}
+
+	key := dedupKey(r)
+	if key == l.lastKey {
+		l.repeats++
+		return true
+	}
+
+	l.writeRepeats()
+	l.lastKey = key
+
+	return false
+}
+
+// writeRepeats writes the number of records collapsed into the previous one,
+// if any. l.mu must be held.
+func (l *logger) writeRepeats() {
+	if l.repeats == 0 {
+		return
+	}
+
+	msg := fmt.Sprintf("… repeated %d×", l.repeats)
+	l.repeats = 0
+	l.writeSinks(Record{Time: time.Now(), GID: goroutineID(), Values: []interface{}{msg}})
+}
+
+// dedupKey returns what makes records identical: the call site, the level and
+// the named values as they are printed.
+func dedupKey(r Record) string {
+	var b strings.Builder
+	b.WriteString(r.File)
+	b.WriteByte(':')
+	b.WriteString(strconv.Itoa(r.Line))
+	b.WriteByte(' ')
+	b.WriteString(r.Level.String())
+	for i, v := range r.Values {
+		b.WriteByte(0)
+		if i < len(r.Names) {
+			b.WriteString(r.Names[i])
+		}
+		b.WriteByte('=')
+		b.WriteString(sprint(v))
+	}
+
+	return b.String()
+}
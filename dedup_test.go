@@ -0,0 +1,51 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestDedup verifies that a Logger with WithDedup collapses runs of identical
+// records and writes their count when the run ends.
+func TestDedup(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false), WithDedup(true))
+
+	for i := 0; i < 5; i++ {
+		state := "waiting"
+		l.Q(state)
+	}
+	for i := 0; i < 2; i++ {
+		l.Q(i)
+	}
+	for i := 0; i < 2; i++ {
+		l.Q("done")
+	}
+	l.Q("done") // another call site
+	l.Flush()
+
+	got := buf.String()
+	for want, count := range map[string]int{
+		" state=waiting\n": 1,
+		" … repeated 4×\n": 1,
+		" i=int(0)\n":      1,
+		" i=int(1)\n":      1,
+		" done\n":          2,
+		" … repeated 1×\n": 1,
+	} {
+		if n := strings.Count(got, want); n != count {
+			t.Fatalf("\nLogger.Q()\ngot:  %q\nwant: %d× %q", got, count, want)
+		}
+	}
+
+	if strings.Index(got, "repeated 4×") > strings.Index(got, "i=int(0)") {
+		t.Fatalf("\nLogger.Q()\ngot:  %q\nwant: count before the next record", got)
+	}
+}
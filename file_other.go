@@ -0,0 +1,29 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package q
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+)
+
+// userName returns the name of the user running the process, or "" if it
+// cannot be looked up.
+func userName() string {
+	u, _ := user.LookupId(strconv.Itoa(os.Getuid()))
+	if u == nil {
+		return ""
+	}
+
+	return u.Username
+}
+
+// chmod sets the permission bits of the log file.
+func chmod(name string, mode os.FileMode) error {
+	return os.Chmod(name, mode)
+}
@@ -0,0 +1,29 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package q
+
+import (
+	"os"
+	"os/user"
+)
+
+// userName returns the name of the user running the process, without its
+// domain, or "" if it cannot be looked up. os.Getuid is always -1 on Windows,
+// so the user comes from the process token, or %USERNAME% as a fallback.
+func userName() string {
+	if u, _ := user.Current(); u != nil {
+		return trimDomain(u.Username)
+	}
+
+	return trimDomain(os.Getenv("USERNAME"))
+}
+
+// chmod does nothing: Windows files have no permission bits, os.Chmod would
+// only toggle the read-only attribute.
+func chmod(string, os.FileMode) error {
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"fmt"
+	"os"
+	slashpath "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// noMatch is an invalid glob, so it matches nothing.
+const noMatch = "["
+
+// sitePattern matches the call sites of a file or a function.
+type sitePattern struct {
+	glob     string // matched against the path or function name, see match
+	file     bool   // glob is a file pattern, not a function pattern
+	from, to int    // line range of a file pattern. 0 means no limit
+}
+
+// siteFilter selects the call sites whose records are written. A nil
+// siteFilter matches every call site.
+type siteFilter []sitePattern
+
+// SetFilter makes Q and its variants write only the records of call sites
+// matching one of the comma-separated patterns. Patterns ending in .go match
+// files, optionally with a line or line range, e.g. pkg/parser/*.go or
+// main.go:100-200. Other patterns match function names, e.g. parser.Parse*.
+// Patterns are globs as for path.Match, matched against the end of the path
+// or function name. An empty spec writes all records, which is the default.
+// The initial filter can also be set with $Q_FILTER.
+func SetFilter(spec string) error {
+	f, err := parseFilter(spec)
+
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.filter = f
+
+	return err
+}
+
+// WithFilter makes the Logger write only the records of call sites matching
+// the patterns, like SetFilter. Invalid patterns match nothing.
+func WithFilter(spec string) Option {
+	return func(l *logger) { l.filter, _ = parseFilter(spec) }
+}
+
+// envFilter returns the filter of $Q_FILTER. Errors are printed, like errors
+// flushing the log file, and the invalid patterns match nothing.
+func envFilter(spec string) siteFilter {
+	f, err := parseFilter(spec)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	return f
+}
+
+// parseFilter parses the comma-separated patterns of spec. Patterns that
+// can't be parsed are kept, matching nothing, so that a typo doesn't write
+// all records.
+func parseFilter(spec string) (siteFilter, error) {
+	var f siteFilter
+	var errs []error
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		p, err := parseSitePattern(s)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		f = append(f, p)
+	}
+
+	if err := MergeErrors(errs...); err != nil {
+		return f, fmt.Errorf("q filter: %w", err)
+	}
+
+	return f, nil
+}
+
+// parseSitePattern parses a single pattern of a filter spec.
+func parseSitePattern(s string) (sitePattern, error) {
+	p := sitePattern{glob: s}
+	if i := strings.LastIndex(s, ".go:"); i >= 0 {
+		p.glob = s[:i+len(".go")]
+
+		var err error
+		p.from, p.to, err = parseLines(s[i+len(".go:"):])
+		if err != nil {
+			return sitePattern{glob: noMatch}, fmt.Errorf("invalid lines in %q: %w", s, err)
+		}
+	}
+
+	p.file = strings.HasSuffix(p.glob, ".go")
+	if _, err := slashpath.Match(p.glob, ""); err != nil {
+		return sitePattern{glob: noMatch}, fmt.Errorf("invalid pattern %q: %w", s, err)
+	}
+
+	return p, nil
+}
+
+// parseLines parses a line, e.g. 42, or a line range, e.g. 100-200. Either
+// end of a range may be left out.
+func parseLines(s string) (from, to int, err error) {
+	first, last, isRange := strings.Cut(s, "-")
+	if !isRange {
+		last = first
+	}
+
+	if first != "" {
+		if from, err = strconv.Atoi(first); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if last != "" {
+		if to, err = strconv.Atoi(last); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return from, to, nil
+}
+
+// match reports whether the call site passes the filter.
+func (f siteFilter) match(file string, line int, funcName string) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, p := range f {
+		if p.match(file, line, funcName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p sitePattern) match(file string, line int, funcName string) bool {
+	if !p.file {
+		return matchSuffix(p.glob, funcName)
+	}
+
+	if p.from > 0 && line < p.from || p.to > 0 && line > p.to {
+		return false
+	}
+
+	return matchSuffix(p.glob, filepath.ToSlash(file))
+}
+
+// matchSuffix reports whether the glob matches s, or a part of s following
+// a slash. E.g. parser/*.go matches /src/pkg/parser/lex.go.
+func matchSuffix(glob, s string) bool {
+	for {
+		if ok, _ := slashpath.Match(glob, s); ok {
+			return true
+		}
+
+		i := strings.IndexByte(s, '/')
+		if i < 0 {
+			return false
+		}
+		s = s[i+1:]
+	}
+}
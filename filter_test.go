@@ -0,0 +1,76 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestFilterMatch verifies that a filter matches call sites by file, line
+// range and function name.
+func TestFilterMatch(t *testing.T) {
+	const file = "/src/app/pkg/parser/lex.go"
+	const fn = "example.com/app/pkg/parser.Lex"
+
+	testCases := []struct {
+		spec string
+		line int
+		want bool
+	}{
+		{spec: "", line: 1, want: true},
+		{spec: "pkg/parser/*.go", line: 1, want: true},
+		{spec: "parser/*.go", line: 1, want: true},
+		{spec: "*.go", line: 1, want: true},
+		{spec: "lexer/*.go", line: 1, want: false},
+		{spec: "lex.go:100-200", line: 150, want: true},
+		{spec: "lex.go:100-200", line: 99, want: false},
+		{spec: "lex.go:100-200", line: 201, want: false},
+		{spec: "lex.go:100-", line: 5000, want: true},
+		{spec: "lex.go:42", line: 42, want: true},
+		{spec: "lex.go:42", line: 43, want: false},
+		{spec: "parser.Lex", line: 1, want: true},
+		{spec: "parser.*", line: 1, want: true},
+		{spec: "main.*", line: 1, want: false},
+		{spec: "main.go, parser.L*", line: 1, want: true},
+		{spec: "lex.go:x", line: 1, want: false},
+		{spec: "[", line: 1, want: false},
+	}
+
+	for _, tc := range testCases {
+		f, _ := parseFilter(tc.spec)
+		if got := f.match(file, tc.line, fn); got != tc.want {
+			t.Fatalf("\nparseFilter(%q).match(%q, %d, %q)\ngot:  %v\nwant: %v", tc.spec, file, tc.line, fn, got, tc.want)
+		}
+	}
+}
+
+// TestParseFilterErrors verifies that parseFilter() reports invalid patterns.
+func TestParseFilterErrors(t *testing.T) {
+	for _, spec := range []string{"main.go:1-x", "a[.go", "ok.go,["} {
+		if _, err := parseFilter(spec); err == nil {
+			t.Fatalf("\nparseFilter(%q)\ngot:  nil error\nwant: error", spec)
+		}
+	}
+}
+
+// TestWithFilter verifies that a Logger only writes the records of matching
+// call sites.
+func TestWithFilter(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFilter("other.go"))
+	l.Q(1)
+	if buf.Len() != 0 {
+		t.Fatalf("\nWithFilter(other.go)\ngot:  %q\nwant: no output", buf.String())
+	}
+
+	l = New(WithOutput(&buf), WithFilter("filter_test.go"))
+	l.Q(1)
+	if buf.Len() == 0 {
+		t.Fatalf("\nWithFilter(filter_test.go)\ngot:  no output\nwant: the record")
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bingoohuang/q/pretty"
+)
+
+// Format selects how records are rendered in the output.
+type Format int
+
+const (
+	// Text is the default colorized, human-readable format.
+	Text Format = iota
+	// JSONL writes one JSON object per q.Q() call, one per line.
+	JSONL
+	// Logfmt writes one line of key=value pairs per q.Q() call.
+	Logfmt
+	// Binary writes one length-prefixed gob record per q.Q() call, for high
+	// frequency logging. Package qlog reads the records back, and qtail
+	// prints them like the text format. Sinks like the viewer of Serve get the
+	// records themselves, whatever the format.
+	Binary
+	// HTML writes one HTML fragment per q.Q() call, with collapsible values,
+	// for viewing in a browser or pasting into a bug report.
+	HTML
+	// Markdown writes one table of the caller and one code block of the
+	// values per q.Q() call, to paste into issues and pull requests.
+	Markdown
+)
+
+// SetFormat sets the format of the records written by Q and its variants.
+// The initial format can also be set with $Q_FORMAT, e.g. Q_FORMAT=json.
+func SetFormat(f Format) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.format = f
+}
+
+// WithFormat sets the format of the records written by the Logger.
+func WithFormat(f Format) Option {
+	return func(l *logger) { l.format = f }
+}
+
+// envFormat returns the Format named by the value of $Q_FORMAT.
+func envFormat(v string) Format {
+	switch v {
+	case "json", "jsonl":
+		return JSONL
+	case "logfmt":
+		return Logfmt
+	case "binary", "gob":
+		return Binary
+	case "html":
+		return HTML
+	case "markdown", "md":
+		return Markdown
+	}
+
+	return Text
+}
+
+// jsonRecord is the JSON Lines form of a record.
+type jsonRecord struct {
+	Time    string    `json:"time"`
+	Level   string    `json:"level"`
+	File    string    `json:"file,omitempty"`
+	Line    int       `json:"line,omitempty"`
+	Func    string    `json:"func,omitempty"`
+	PID     int       `json:"pid"`
+	GID     uint64    `json:"goroutine"`
+	Elapsed float64   `json:"elapsed"`
+	Args    []jsonArg `json:"args"`
+	Stack   []string  `json:"stack,omitempty"`
+}
+
+// jsonArg is a single argument of a jsonRecord. Value is always the pretty
+// printed form, JSON is set if the value could be marshaled as JSON. HTML is
+// only set for the viewer of Serve.
+type jsonArg struct {
+	Name  string          `json:"name,omitempty"`
+	Value string          `json:"value"`
+	JSON  json.RawMessage `json:"json,omitempty"`
+	HTML  string          `json:"html,omitempty"`
+}
+
+// outputJSON writes the record to the log buffer as a single JSON line.
+func (l *logger) outputJSON(r Record) {
+	if r.File != "" {
+		// Keep the grouping of records in sync with the text format, so that
+		// elapsed is relative to the same start.
+		l.header(r.Func, r.File, r.Line, r.GID)
+	}
+
+	b, err := marshalJSON(r, time.Since(l.start).Seconds())
+	if err != nil {
+		// Only the raw JSON of an argument can fail, and it is only set after
+		// a successful marshal. Fall back to the text format to be safe.
+		l.outputText(r)
+		return
+	}
+
+	l.buf.Write(b)
+	l.buf.WriteByte('\n')
+}
+
+// marshalJSON returns the JSON Lines form of the record, without the trailing
+// newline. elapsed is the time in seconds since the start of the log group.
+func marshalJSON(r Record, elapsed float64) ([]byte, error) {
+	b, err := json.Marshal(newJSONRecord(r, elapsed))
+	if err != nil {
+		return nil, fmt.Errorf("marshal q record: %w", err)
+	}
+
+	return b, nil
+}
+
+// newJSONRecord returns the JSON Lines form of the record, see marshalJSON.
+func newJSONRecord(r Record, elapsed float64) jsonRecord {
+	jr := jsonRecord{
+		Time:    r.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+		Level:   strings.ToLower(r.Level.String()),
+		File:    r.File,
+		Line:    r.Line,
+		Func:    r.Func,
+		PID:     os.Getpid(),
+		GID:     r.GID,
+		Elapsed: elapsed,
+		Args:    make([]jsonArg, 0, len(r.Values)),
+		Stack:   r.Stack,
+	}
+
+	for i, v := range r.Values {
+		a := jsonArg{Value: sprint(v)}
+		if i < len(r.Names) {
+			a.Name = r.Names[i]
+		}
+
+		// encoding/json doesn't know about redacted fields, so values that may
+		// contain secrets only get their pretty printed form.
+		redacted := pretty.ContainsRedacted(v)
+		if b, err := json.Marshal(v); err == nil && !redacted {
+			a.JSON = b
+		}
+
+		jr.Args = append(jr.Args, a)
+	}
+
+	return jr
+}
+
+// outputLogfmt writes the record to the log buffer as a single logfmt line.
+func (l *logger) outputLogfmt(r Record) {
+	writeLogfmt(&l.buf, r)
+}
+
+// writeLogfmt writes the record to w as a single logfmt line, e.g.
+// ts=2006-01-02T15:04:05.000Z level=info file=main.go:42 func=main.main port=443.
+// Arguments without a name are keyed by their position, e.g. arg1=...
+func writeLogfmt(w io.Writer, r Record) {
+	fmt.Fprintf(w, "ts=%s level=%s", r.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+		strings.ToLower(r.Level.String()))
+	if r.File != "" {
+		fmt.Fprintf(w, " file=%s func=%s",
+			Quote(shortFile(r.File)+":"+strconv.Itoa(r.Line)), Quote(r.Func))
+	}
+
+	fmt.Fprintf(w, " pid=%d goroutine=%d", os.Getpid(), r.GID)
+
+	for i, v := range r.Values {
+		name := ""
+		if i < len(r.Names) {
+			name = r.Names[i]
+		}
+
+		// Keep the record on one line, multi-line values get escaped newlines.
+		value := strings.ReplaceAll(sprint(v), "\n", `\n`)
+		fmt.Fprintf(w, " %s=%s", logfmtKey(name, i), Quote(value))
+	}
+
+	if len(r.Stack) > 0 {
+		fmt.Fprintf(w, " stack=%s", Quote(strings.Join(r.Stack, `\n`)))
+	}
+
+	fmt.Fprint(w, "\n")
+}
+
+// logfmtKey turns the source text of an argument into a logfmt key. Characters
+// that are not allowed in a key, like spaces and quotes, are replaced by _.
+// An empty name is replaced by the position of the argument.
+func logfmtKey(name string, i int) string {
+	if name == "" {
+		return "arg" + strconv.Itoa(i)
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == '\'' {
+			return '_'
+		}
+
+		return r
+	}, name)
+}
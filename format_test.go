@@ -0,0 +1,129 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestOutputJSON verifies that the JSONL format writes one JSON object per
+// call, with the argument names, pretty-printed values and raw JSON values.
+func TestOutputJSON(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFormat(JSONL))
+
+	port := 443
+	tags := []string{"a", "b"}
+	l.Q(port, tags)
+
+	var got jsonRecord
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal(%q): %v", buf.String(), err)
+	}
+
+	if got.Func != "github.com/bingoohuang/q.TestOutputJSON" || got.Line == 0 || got.PID == 0 {
+		t.Fatalf("\ngot:  %+v\nwant: caller info and pid", got)
+	}
+
+	want := []jsonArg{
+		{Name: "port", Value: "int(443)", JSON: json.RawMessage(`443`)},
+		{Name: "tags", Value: `[]string{"a", "b"}`, JSON: json.RawMessage(`["a","b"]`)},
+	}
+	if len(got.Args) != len(want) {
+		t.Fatalf("\ngot:  %+v\nwant: %+v", got.Args, want)
+	}
+
+	for i := range want {
+		g, w := got.Args[i], want[i]
+		if g.Name != w.Name || g.Value != w.Value || !bytes.Equal(g.JSON, w.JSON) {
+			t.Fatalf("\nargs[%d]\ngot:  %+v\nwant: %+v", i, g, w)
+		}
+	}
+}
+
+// TestEnvFormat verifies that envFormat() maps $Q_FORMAT values to formats.
+func TestEnvFormat(t *testing.T) {
+	testCases := map[string]Format{
+		"":         Text,
+		"text":     Text,
+		"json":     JSONL,
+		"jsonl":    JSONL,
+		"logfmt":   Logfmt,
+		"binary":   Binary,
+		"gob":      Binary,
+		"html":     HTML,
+		"markdown": Markdown,
+		"md":       Markdown,
+	}
+
+	for value, want := range testCases {
+		if got := envFormat(value); got != want {
+			t.Fatalf("\nenvFormat(%q)\ngot:  %v\nwant: %v", value, got, want)
+		}
+	}
+}
+
+// TestOutputLogfmt verifies that the logfmt format writes the caller and the
+// name=value pairs, quoting values with spaces.
+func TestOutputLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFormat(Logfmt))
+
+	port := 443
+	greeting := "hello world"
+	pair := []struct{ a, b int }{{1, 2}, {3, 4}}
+	l.Q(port, greeting, 3, pair)
+
+	got := buf.String()
+	for _, want := range []string{
+		"ts=",
+		"format_test.go:",
+		"func=github.com/bingoohuang/q.TestOutputLogfmt",
+		"port='int(443)'",
+		"greeting='hello world'",
+		"arg2='int(3)'",
+		`pair='[]struct { a int; b int }{\n    {a:1, b:2},`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+
+	if strings.Count(got, "\n") != 1 {
+		t.Fatalf("\ngot:  %q\nwant: a single line", got)
+	}
+}
+
+// TestOutputJSONRedacted verifies that values with redacted fields don't leak
+// through the raw JSON of the JSONL format.
+func TestOutputJSONRedacted(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFormat(JSONL))
+
+	type creds struct {
+		User     string
+		Password string `q:"redact"`
+	}
+	login := creds{"root", "hunter2"}
+
+	// Also when held by interfaces, which hide the type of the value.
+	for _, v := range []interface{}{
+		login,
+		map[string]interface{}{"c": login},
+		[]interface{}{1, login},
+	} {
+		buf.Reset()
+		l.Q(v)
+
+		if got := buf.String(); strings.Contains(got, "hunter2") || !strings.Contains(got, "***") {
+			t.Fatalf("\ngot:  %q\nwant: redacted password", got)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// SetGoroutineGrouping makes Q print a new header whenever the calling
+// goroutine differs from the previous call, so that each header block only
+// contains the output of a single goroutine.
+func SetGoroutineGrouping(enabled bool) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.groupGoroutines = enabled
+}
+
+// WithGoroutineGrouping makes the Logger print a new header whenever the
+// calling goroutine changes.
+func WithGoroutineGrouping(enabled bool) Option {
+	return func(l *logger) { l.groupGoroutines = enabled }
+}
+
+// goroutineID returns the id of the calling goroutine, parsed from the first
+// line of its stack trace, e.g. "goroutine 18 [running]:". It returns 0 if
+// the id cannot be found.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
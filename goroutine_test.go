@@ -0,0 +1,23 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import "testing"
+
+// TestGoroutineID verifies that goroutineID() returns distinct, non-zero ids
+// for distinct goroutines.
+func TestGoroutineID(t *testing.T) {
+	id := goroutineID()
+	if id == 0 {
+		t.Fatalf("goroutineID() = 0, want non-zero")
+	}
+
+	other := make(chan uint64)
+	go func() { other <- goroutineID() }()
+
+	if got := <-other; got == 0 || got == id {
+		t.Fatalf("goroutineID() in a new goroutine = %d, want non-zero and != %d", got, id)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setTestPath points the log file at a temporary file for the duration of
+// the test and returns its name.
+func setTestPath(t *testing.T) string {
+	t.Helper()
+
+	old := Path()
+	SetPath(filepath.Join(t.TempDir(), "q"))
+	t.Cleanup(func() { SetPath(old) })
+
+	return Path()
+}
+
+// readLog returns the content of the log file.
+func readLog(t *testing.T, name string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("failed to read %q: %v", name, err)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,122 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hex writes a canonical hex+ASCII dump of b, as name=dump, to the
+// $TMPDIR/$USER.q log file:
+//
+//	00000000  68 65 6c 6c 6f 20 77 6f  72 6c 64 0a              |hello world.|
+//
+// Rows hold 16 bytes unless the line width is too narrow for them.
+func Hex(name string, b []byte) {
+	if off || std.skip(LevelInfo) {
+		return
+	}
+
+	std.hex(CallDepth, name, b)
+}
+
+// SetHexThreshold makes Q render every []byte argument longer than n bytes
+// as a hex dump, like Hex. 0 disables it, which is the default.
+func SetHexThreshold(n int) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.hexThreshold = n
+}
+
+// WithHexThreshold makes the Logger render every []byte argument longer than
+// n bytes as a hex dump.
+func WithHexThreshold(n int) Option {
+	return func(l *logger) { l.hexThreshold = n }
+}
+
+func (l *logger) hex(callDepth int, name string, b []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r, ok := l.newRecord(callDepth+1, []interface{}{"\n" + hexDump(b, l.lineWidth())}, noNames)
+	if !ok {
+		return
+	}
+
+	r.Names = []string{name}
+	l.write(r)
+}
+
+// hexValues replaces the []byte values longer than the hex threshold with
+// their hex dump.
+func (l *logger) hexValues(v []interface{}) []interface{} {
+	if l.hexThreshold <= 0 {
+		return v
+	}
+
+	var replaced []interface{}
+	for i, x := range v {
+		if b, ok := x.([]byte); ok && len(b) > l.hexThreshold {
+			if replaced == nil {
+				replaced = append([]interface{}(nil), v...)
+			}
+			replaced[i] = "\n" + hexDump(b, l.lineWidth())
+		}
+	}
+
+	if replaced == nil {
+		return v
+	}
+
+	return replaced
+}
+
+// hexDump returns the hex dump of b in the format of hexdump -C, with as many
+// bytes per row, up to 16 and in steps of 4, as fit in width characters.
+func hexDump(b []byte, width int) string {
+	perRow := 16
+	for perRow > 4 && hexRowWidth(perRow) > width {
+		perRow -= 4
+	}
+
+	var sb strings.Builder
+	for off := 0; off < len(b); off += perRow {
+		row := b[off:min(off+perRow, len(b))]
+		fmt.Fprintf(&sb, "%08x  ", off)
+
+		for i := 0; i < perRow; i++ {
+			if i < len(row) {
+				fmt.Fprintf(&sb, "%02x ", row[i])
+			} else {
+				sb.WriteString("   ")
+			}
+
+			if i == perRow/2-1 {
+				sb.WriteByte(' ')
+			}
+		}
+
+		sb.WriteString(" |")
+		for _, c := range row {
+			if c < ' ' || c > '~' {
+				c = '.'
+			}
+			sb.WriteByte(c)
+		}
+		sb.WriteString("|\n")
+	}
+
+	fmt.Fprintf(&sb, "%08x", len(b))
+
+	return sb.String()
+}
+
+// hexRowWidth returns the width of a hexDump row of n bytes.
+func hexRowWidth(n int) int {
+	// offset, 2 spaces, 3 chars per byte, middle gap, " |", ascii, "|"
+	return 8 + 2 + 3*n + 1 + 2 + n + 1
+}
@@ -0,0 +1,53 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestHexDump verifies that hexDump() writes canonical rows and narrows them
+// to fit the width.
+func TestHexDump(t *testing.T) {
+	data := []byte("hello world\nq is a better way to debug")
+
+	want := `00000000  68 65 6c 6c 6f 20 77 6f  72 6c 64 0a 71 20 69 73  |hello world.q is|
+00000010  20 61 20 62 65 74 74 65  72 20 77 61 79 20 74 6f  | a better way to|
+00000020  20 64 65 62 75 67                                 | debug|
+00000026`
+	if got := hexDump(data, maxLineWidth); got != want {
+		t.Fatalf("\nhexDump(%q, 80)\ngot:\n%s\nwant:\n%s", data, got, want)
+	}
+
+	want = `00000000  68 65 6c 6c  6f 20 77 6f  |hello wo|
+00000008  72 6c 64                  |rld|
+0000000b`
+	if got := hexDump(data[:11], 50); got != want {
+		t.Fatalf("\nhexDump(%q, 50)\ngot:\n%s\nwant:\n%s", data[:11], got, want)
+	}
+}
+
+// TestHexThreshold verifies that long []byte arguments are hex dumped while
+// short ones keep their normal form.
+func TestHexThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false), WithHexThreshold(4))
+
+	short, long := []byte("abc"), []byte("abcdefgh")
+	l.Q(short, long)
+
+	got := buf.String()
+	if !strings.Contains(got, "short=[]uint8(\"abc\")") {
+		t.Fatalf("\ngot:  %q\nwant: short printed as a byte slice", got)
+	}
+
+	if !strings.Contains(got, "|abcdefgh|") {
+		t.Fatalf("\ngot:  %q\nwant: long printed as a hex dump", got)
+	}
+}
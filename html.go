@@ -0,0 +1,60 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bingoohuang/q/pretty"
+)
+
+// outputHTML writes the record to the log buffer as an HTML fragment.
+func (l *logger) outputHTML(r Record) {
+	writeHTML(&l.buf, r)
+}
+
+// writeHTML writes the record to w as a <div class="q-record"> element: a
+// header with the time, level and caller, followed by the arguments, each
+// value printed by pretty.HTML with its structs, maps and slices collapsible.
+// Style it with pretty.HTMLStyle.
+func writeHTML(w io.Writer, r Record) {
+	fmt.Fprintf(w, `<div class="q-record"><div class="q-head">%s %s`,
+		r.Time.Format("2006-01-02T15:04:05.000Z07:00"), strings.ToLower(r.Level.String()))
+	if r.File != "" {
+		fmt.Fprintf(w, " %s %s", html.EscapeString(shortFile(r.File)+":"+strconv.Itoa(r.Line)),
+			html.EscapeString(r.Func))
+	}
+	fmt.Fprint(w, "</div>\n")
+
+	for i, v := range r.Values {
+		fmt.Fprint(w, `<div class="q-arg">`)
+		if i < len(r.Names) && r.Names[i] != "" {
+			fmt.Fprintf(w, `<span class="q-name">%s</span>=`, html.EscapeString(r.Names[i]))
+		}
+		fmt.Fprint(w, sprintHTML(v), "</div>\n")
+	}
+
+	if len(r.Stack) > 0 {
+		fmt.Fprintf(w, "<details><summary>stack</summary><pre>%s</pre></details>\n",
+			html.EscapeString(strings.Join(r.Stack, "\n")))
+	}
+
+	fmt.Fprint(w, "</div>\n")
+}
+
+// sprintHTML pretty-prints v as HTML within the current limits.
+func sprintHTML(v interface{}) string {
+	opts := []pretty.Option{pretty.HTML(true)}
+	if l := limits.Load(); l != nil {
+		opts = append(opts, pretty.MaxDepth(l.MaxDepth), pretty.MaxSliceLen(l.MaxElems),
+			pretty.MaxStringLen(l.MaxStringLen))
+	}
+
+	return fmt.Sprint(pretty.NewFormatter(v, opts...))
+}
@@ -0,0 +1,43 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestOutputHTML verifies that the HTML format writes a record per call, with
+// the caller, the argument names and the values as collapsible HTML.
+func TestOutputHTML(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFormat(HTML))
+
+	port := 443
+	tags := []string{"<a>"}
+	l.Q(port, tags)
+
+	got := buf.String()
+	for _, want := range []string{
+		`<div class="q-record"><div class="q-head">`,
+		"html_test.go:",
+		"github.com/bingoohuang/q.TestOutputHTML</div>\n",
+		`<div class="q-arg"><span class="q-name">port</span>=<div class="pretty">`,
+		`<span class="pretty-number">443</span>`,
+		`<details open><summary><span class="pretty-type">[]string</span></summary>`,
+		`<span class="pretty-string">&#34;&lt;a&gt;&#34;</span>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+
+	if strings.Count(got, `<div class="q-record">`) != 1 || !strings.HasSuffix(got, "</div>\n") {
+		t.Fatalf("\ngot:  %q\nwant: a single record", got)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// journalSocket is the native protocol socket of systemd-journald.
+const journalSocket = "/run/systemd/journal/socket"
+
+// journalDebug is the syslog priority of q records, LOG_DEBUG.
+const journalDebug = 7
+
+// JournalSink is a Sink that sends records to the systemd journal using the
+// native protocol, so that they show up in journalctl with structured fields:
+// MESSAGE, CODE_FILE, CODE_LINE, CODE_FUNC and one field per argument, e.g.
+// PORT=int(443) for q.Q(port).
+type JournalSink struct {
+	conn *net.UnixConn
+}
+
+// DialJournal returns a JournalSink connected to the local journal, e.g.
+// q.AddSink(sink) after sink, err := q.DialJournal().
+func DialJournal() (*JournalSink, error) {
+	return dialJournal(journalSocket)
+}
+
+// dialJournal returns a JournalSink connected to the socket at path.
+func dialJournal(path string) (*JournalSink, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("dial journal: %w", err)
+	}
+
+	return &JournalSink{conn: conn}, nil
+}
+
+// Write sends the record to the journal as a single entry.
+func (s *JournalSink) Write(r Record) error {
+	if _, err := s.conn.Write(journalEntry(r)); err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the connection to the journal.
+func (s *JournalSink) Close() error {
+	return s.conn.Close()
+}
+
+// journalEntry returns the record in the native journal protocol.
+func journalEntry(r Record) []byte {
+	var buf bytes.Buffer
+	writeJournalField(&buf, "PRIORITY", strconv.Itoa(journalDebug))
+	writeJournalField(&buf, "SYSLOG_IDENTIFIER", filepath.Base(os.Args[0]))
+
+	if r.File != "" {
+		writeJournalField(&buf, "CODE_FILE", r.File)
+		writeJournalField(&buf, "CODE_LINE", strconv.Itoa(r.Line))
+		writeJournalField(&buf, "CODE_FUNC", r.Func)
+	}
+
+	writeJournalField(&buf, "Q_GOROUTINE", strconv.FormatUint(r.GID, 10))
+
+	msg := make([]string, 0, len(r.Values))
+	for i, v := range r.Values {
+		name := ""
+		if i < len(r.Names) {
+			name = r.Names[i]
+		}
+
+		value := sprint(v)
+		writeJournalField(&buf, journalKey(name, i), value)
+
+		if name != "" {
+			value = name + "=" + value
+		}
+		msg = append(msg, value)
+	}
+
+	if len(r.Stack) > 0 {
+		writeJournalField(&buf, "Q_STACK", strings.Join(r.Stack, "\n"))
+	}
+
+	writeJournalField(&buf, "MESSAGE", strings.Join(msg, " "))
+
+	return buf.Bytes()
+}
+
+// writeJournalField writes a single field of a journal entry. Values with a
+// newline are length prefixed, as required by the protocol.
+func writeJournalField(buf *bytes.Buffer, key, value string) {
+	if !strings.Contains(value, "\n") {
+		fmt.Fprintf(buf, "%s=%s\n", key, value)
+		return
+	}
+
+	buf.WriteString(key)
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalKey turns the source text of an argument into a journal field name.
+// Field names may only contain uppercase letters, digits and underscores, and
+// must not start with an underscore or a digit. Names that are empty after
+// the conversion are replaced by the position of the argument, e.g. ARG1.
+// Names of the fields set by q itself get a Q_ prefix, e.g. Q_MESSAGE.
+func journalKey(name string, i int) string {
+	key := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+
+		return '_'
+	}, name)
+
+	key = strings.TrimLeft(key, "_0123456789")
+	switch key {
+	case "":
+		return "ARG" + strconv.Itoa(i)
+	case "MESSAGE", "PRIORITY", "SYSLOG_IDENTIFIER", "CODE_FILE", "CODE_LINE", "CODE_FUNC":
+		key = "Q_" + key
+	}
+
+	const maxKeyLen = 64
+	if len(key) > maxKeyLen {
+		key = key[:maxKeyLen]
+	}
+
+	return key
+}
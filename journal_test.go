@@ -0,0 +1,81 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestJournalSink verifies that a JournalSink sends records as native journal
+// entries with the code location and one field per argument.
+func TestJournalSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	ln, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+
+	s, err := dialJournal(path)
+	if err != nil {
+		t.Fatalf("dialJournal(%q): %v", path, err)
+	}
+	defer s.Close()
+
+	l := New(WithOutput(io.Discard), WithSink(s))
+	port := 443
+	l.Q(port, "a\nb")
+
+	b := make([]byte, 4096)
+	n, err := ln.Read(b)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	got := string(b[:n])
+
+	for _, want := range []string{
+		"PRIORITY=7\n",
+		"CODE_LINE=",
+		"CODE_FUNC=github.com/bingoohuang/q.TestJournalSink\n",
+		"PORT=int(443)\n",
+		"ARG1\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n",
+		"MESSAGE\n",
+		"port=int(443) a\nb\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+}
+
+// TestJournalKey verifies that journalKey() turns argument names into valid
+// journal field names.
+func TestJournalKey(t *testing.T) {
+	testCases := []struct {
+		name string
+		want string
+	}{
+		{name: "port", want: "PORT"},
+		{name: "req.URL", want: "REQ_URL"},
+		{name: "_private", want: "PRIVATE"},
+		{name: "1 + 2", want: "ARG3"},
+		{name: "", want: "ARG3"},
+		{name: "message", want: "Q_MESSAGE"},
+		{name: strings.Repeat("a", 100), want: strings.Repeat("A", 64)},
+	}
+
+	for _, tc := range testCases {
+		got := journalKey(tc.name, 3)
+		if got != tc.want {
+			t.Fatalf("\njournalKey(%q, 3)\ngot:  %q\nwant: %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import "go/ast"
+
+// Lazy wraps a function producing an expensive value, e.g. a digest or the
+// serialization of a big struct, to pass it to Q or its variants. f is only
+// called if the record is actually written: not if logging is disabled, the
+// level or filter skip the call, or Every or Limit drop it.
+//
+//	q.Q(q.Lazy(func() interface{} { return sha256.Sum256(body) }))
+//
+// The value is named after the returned expression, sha256.Sum256(body).
+func Lazy(f func() interface{}) interface{} {
+	return lazyValue(f)
+}
+
+// lazyValue is a value passed as q.Lazy(f), replaced by f() when the record
+// is written.
+type lazyValue func() interface{}
+
+// resolveLazy returns the values with the Lazy ones replaced by their results.
+// v itself is left as is, it may be the caller's slice.
+func resolveLazy(v []interface{}) []interface{} {
+	var resolved []interface{}
+	for i, x := range v {
+		if f, ok := x.(lazyValue); ok {
+			if resolved == nil {
+				resolved = append([]interface{}(nil), v...)
+			}
+			resolved[i] = f()
+		}
+	}
+
+	if resolved == nil {
+		return v
+	}
+
+	return resolved
+}
+
+// unwrapLazy returns the expression returned by the function of a q.Lazy
+// argument, e.g. sha256.Sum256(body), so that the value is named after it.
+// Functions with more than a single return statement return nil: their
+// values are not named. Other arguments are returned as they are.
+func unwrapLazy(arg ast.Expr) ast.Expr {
+	call, is := arg.(*ast.CallExpr)
+	if !is || len(call.Args) != 1 || !isLazyCall(call) {
+		return arg
+	}
+
+	fn, is := call.Args[0].(*ast.FuncLit)
+	if !is || len(fn.Body.List) != 1 {
+		return nil
+	}
+
+	ret, is := fn.Body.List[0].(*ast.ReturnStmt)
+	if !is || len(ret.Results) != 1 {
+		return nil
+	}
+
+	return ret.Results[0]
+}
+
+// isLazyCall returns true if the given function call expression is q.Lazy()
+// or Lazy().
+func isLazyCall(n *ast.CallExpr) bool {
+	switch fun := n.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name == "Lazy"
+	case *ast.SelectorExpr:
+		return fun.Sel.Name == "Lazy" && isQPackage(n)
+	}
+
+	return false
+}
@@ -0,0 +1,45 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestLazy verifies that Lazy() values are only evaluated for records that are
+// written, and named after the returned expression.
+func TestLazy(t *testing.T) {
+	calls := 0
+	digest := func(s string) string {
+		calls++
+		return strings.ToUpper(s)
+	}
+
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false), WithLevel(LevelWarn))
+	l.Q(Lazy(func() interface{} { return digest("skipped") }))
+
+	filtered := New(WithOutput(&buf), WithFilter("nomatch.go"))
+	filtered.Q(Lazy(func() interface{} { return digest("filtered") }))
+
+	if calls != 0 || buf.Len() != 0 {
+		t.Fatalf("\nLogger.Q(Lazy(f)) of a skipped record\ngot:  %d calls, %q\nwant: no calls", calls, buf.String())
+	}
+
+	l = New(WithOutput(&buf), WithColors(false))
+	l.Q(Lazy(func() interface{} { return digest("body") }), Lazy(func() interface{} {
+		s := digest("two")
+		return s
+	}))
+
+	got := buf.String()
+	if calls != 2 || !strings.Contains(got, ` digest("body")=BODY TWO`) {
+		t.Fatalf("\nLogger.Q(Lazy(f))\ngot:  %d calls, %q\nwant: 2 calls, digest(\"body\")=BODY TWO", calls, got)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Level is the importance of a record. Records below the level of a logger
+// are skipped before any formatting or source parsing.
+type Level int
+
+const (
+	// LevelDebug is the level of Debug, for noisy low-level dumps that are
+	// only written when asked for, e.g. with Q_LEVEL=debug.
+	LevelDebug Level = -1
+	// LevelInfo is the level of Info, Q and all other q calls. It is the
+	// default level of loggers.
+	LevelInfo Level = 0
+	// LevelWarn is the level of Warn.
+	LevelWarn Level = 1
+)
+
+// String returns the name of the level, e.g. DEBUG.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	}
+
+	return "LEVEL(" + strconv.Itoa(int(l)) + ")"
+}
+
+// envLevel returns the Level named by the value of $Q_LEVEL, LevelInfo if it
+// is empty or unknown.
+func envLevel(v string) Level {
+	switch strings.ToLower(v) {
+	case "debug":
+		return LevelDebug
+	case "warn", "warning":
+		return LevelWarn
+	}
+
+	return LevelInfo
+}
+
+// SetLevel makes Q and its variants skip records below level. The initial
+// level is LevelInfo, or the level named by $Q_LEVEL, e.g. Q_LEVEL=debug.
+func SetLevel(level Level) {
+	std.level.Store(int32(level))
+}
+
+// WithLevel makes the Logger skip records below level.
+func WithLevel(level Level) Option {
+	return func(l *logger) { l.level.Store(int32(level)) }
+}
+
+// levelTag prepends the name of the level to the formatted arguments of a
+// record, unless it is LevelInfo.
+func levelTag(level Level, args []string) []string {
+	if level == LevelInfo {
+		return args
+	}
+
+	return append([]string{colorize(level.String(), bold)}, args...)
+}
+
+// skip reports whether records of the level are not written, because the
+// level is below the logger's level or logging is disabled.
+func (l *logger) skip(level Level) bool {
+	return disabled.Load() || level < Level(l.level.Load())
+}
+
+// Debug pretty-prints the given arguments like Q, at LevelDebug. Debug
+// records are only written if the level is lowered, e.g. with Q_LEVEL=debug.
+func Debug(v ...interface{}) {
+	if off || std.skip(LevelDebug) {
+		return
+	}
+
+	std.leveled(CallDepth, LevelDebug, v)
+}
+
+// Info pretty-prints the given arguments like Q, at LevelInfo.
+func Info(v ...interface{}) {
+	if off || std.skip(LevelInfo) {
+		return
+	}
+
+	std.leveled(CallDepth, LevelInfo, v)
+}
+
+// Warn pretty-prints the given arguments like Q, at LevelWarn.
+func Warn(v ...interface{}) {
+	if off || std.skip(LevelWarn) {
+		return
+	}
+
+	std.leveled(CallDepth, LevelWarn, v)
+}
+
+// Debug pretty-prints the given arguments to the Logger's output, at
+// LevelDebug.
+func (l *Logger) Debug(v ...interface{}) {
+	if off || l.skip(LevelDebug) {
+		return
+	}
+
+	l.leveled(CallDepth, LevelDebug, v)
+}
+
+// Info pretty-prints the given arguments to the Logger's output, at
+// LevelInfo.
+func (l *Logger) Info(v ...interface{}) {
+	if off || l.skip(LevelInfo) {
+		return
+	}
+
+	l.leveled(CallDepth, LevelInfo, v)
+}
+
+// Warn pretty-prints the given arguments to the Logger's output, at
+// LevelWarn.
+func (l *Logger) Warn(v ...interface{}) {
+	if off || l.skip(LevelWarn) {
+		return
+	}
+
+	l.leveled(CallDepth, LevelWarn, v)
+}
+
+func (l *logger) leveled(callDepth int, level Level, v []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r, ok := l.newRecord(callDepth+1, v, 0)
+	if !ok {
+		return
+	}
+
+	r.Level = level
+	l.write(r)
+}
@@ -0,0 +1,78 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestLevels verifies that records below the logger's level are skipped and
+// that other levels than info are tagged in the output.
+func TestLevels(t *testing.T) {
+	testCases := []struct {
+		level Level
+		want  []string
+		skip  []string
+	}{
+		{
+			level: LevelInfo,
+			want:  []string{" i=int(2)\n", " WARN w=int(3)\n", " q=int(4)\n"},
+			skip:  []string{"d=int(1)"},
+		},
+		{
+			level: LevelDebug,
+			want:  []string{" DEBUG d=int(1)\n", " i=int(2)\n", " WARN w=int(3)\n"},
+		},
+		{
+			level: LevelWarn,
+			want:  []string{" WARN w=int(3)\n"},
+			skip:  []string{"d=int(1)", "i=int(2)", "q=int(4)"},
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		l := New(WithOutput(&buf), WithColors(false), WithLevel(tc.level))
+		d, i, w, q := 1, 2, 3, 4
+		l.Debug(d)
+		l.Info(i)
+		l.Warn(w)
+		l.Q(q)
+
+		got := buf.String()
+		for _, want := range tc.want {
+			if !strings.Contains(got, want) {
+				t.Fatalf("\nWithLevel(%v)\ngot:  %q\nmissing %q", tc.level, got, want)
+			}
+		}
+
+		for _, skip := range tc.skip {
+			if strings.Contains(got, skip) {
+				t.Fatalf("\nWithLevel(%v)\ngot:  %q\nwant no %q", tc.level, got, skip)
+			}
+		}
+	}
+}
+
+// TestEnvLevel verifies that envLevel() maps $Q_LEVEL values to levels.
+func TestEnvLevel(t *testing.T) {
+	for value, want := range map[string]Level{
+		"":        LevelInfo,
+		"debug":   LevelDebug,
+		"DEBUG":   LevelDebug,
+		"info":    LevelInfo,
+		"warn":    LevelWarn,
+		"warning": LevelWarn,
+		"bogus":   LevelInfo,
+	} {
+		if got := envLevel(value); got != want {
+			t.Fatalf("\nenvLevel(%q)\ngot:  %v\nwant: %v", value, got, want)
+		}
+	}
+}
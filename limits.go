@@ -0,0 +1,57 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"sync/atomic"
+
+	"github.com/bingoohuang/q/pretty"
+)
+
+// limits bounds the pretty-printed output of every value logged by q.
+var limits atomic.Pointer[pretty.Limits] // nolint: gochecknoglobals
+
+// SetMaxDepth limits the nesting of printed maps, structs, arrays and slices to
+// n levels; deeper values are printed as their type followed by {…}. 0 means no limit, the default.
+func SetMaxDepth(n int) {
+	updateLimits(func(l *pretty.Limits) { l.MaxDepth = n })
+}
+
+// SetMaxElems limits the number of printed elements of each map, array and
+// slice to n, followed by …(+1234 more elems). 0 means no limit, the default.
+func SetMaxElems(n int) {
+	updateLimits(func(l *pretty.Limits) { l.MaxElems = n })
+}
+
+// SetMaxStringLen limits each printed string to n bytes, followed by
+// …(+1234 more bytes). 0 means no limit, the default.
+func SetMaxStringLen(n int) {
+	updateLimits(func(l *pretty.Limits) { l.MaxStringLen = n })
+}
+
+// updateLimits atomically replaces the limits by a copy modified by f.
+func updateLimits(f func(l *pretty.Limits)) {
+	for {
+		old := limits.Load()
+		l := pretty.Limits{}
+		if old != nil {
+			l = *old
+		}
+		f(&l)
+
+		if limits.CompareAndSwap(old, &l) {
+			return
+		}
+	}
+}
+
+// sprint pretty-prints v within the current limits.
+func sprint(v interface{}) string {
+	if l := limits.Load(); l != nil {
+		return l.Sprint(v)
+	}
+
+	return pretty.Sprint(v)
+}
@@ -0,0 +1,28 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import "testing"
+
+// TestLimits verifies that the SetMax* functions bound the output of
+// formatArgs().
+func TestLimits(t *testing.T) {
+	t.Cleanup(func() { limits.Store(nil) })
+
+	SetMaxElems(2)
+	SetMaxStringLen(3)
+	SetMaxDepth(1)
+
+	got := formatArgs(nil, []string{"abcdef", "b", "c"}, [][]int{{1}})
+	want := []string{
+		colorize(`[]string{"abc"…(+3 more bytes), "b", …(+1 more elems)}`, cyan),
+		colorize("[][]int{\n    []int{…},\n}", cyan),
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("\nformatArgs()[%d]\ngot:  %q\nwant: %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows
+
+package q
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLockFile verifies that lockFile() blocks while another open file of the
+// same name holds the lock, as it does for another process.
+func TestLockFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "q")
+	open := func() *os.File {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+
+		return f
+	}
+
+	first, second := open(), open()
+	if err := lockFile(first); err != nil {
+		t.Fatalf("lockFile(): %v", err)
+	}
+
+	locked := make(chan error)
+	go func() { locked <- lockFile(second) }()
+
+	select {
+	case <-locked:
+		t.Fatal("lockFile() returned while the file was locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := unlockFile(first); err != nil {
+		t.Fatalf("unlockFile(): %v", err)
+	}
+
+	if err := <-locked; err != nil {
+		t.Fatalf("lockFile(): %v", err)
+	}
+}
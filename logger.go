@@ -6,15 +6,20 @@ package q
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"math"
 	"os"
-	"os/user"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/bingoohuang/q/pretty"
 )
 
 type color string
@@ -26,6 +31,12 @@ const (
 	cyan     color = "\033[36m"
 	endColor color = "\033[0m" // "reset everything"
 
+	// Colors of the syntax highlighting, see SetHighlight. The type names
+	// are cyan and end with endColor, like the other colors.
+	fieldColor  color = pretty.FieldColor
+	stringColor color = pretty.StringColor
+	numberColor color = pretty.NumberColor
+
 	maxLineWidth = 80
 )
 
@@ -36,15 +47,47 @@ type logger struct {
 	lastWrite time.Time    // last time buffer was flushed. determines when to print header
 	lastFile  string       // last file to call q.Q(). determines when to print header
 	lastFunc  string       // last function to call q.Q(). determines when to print header
+	lastGID   uint64       // last goroutine to call q.Q(). determines when to print header
 	buf       bytes.Buffer // collects writes before they're flushed to the log file
 	mu        sync.Mutex   // protects all the other fields
+
+	out       io.Writer // destination of flushed writes. nil means the log file at Path
+	tee       io.Writer // optional second destination of flushed writes
+	noColor   bool      // strip ANSI color codes before flushing
+	highlight bool      // highlight the syntax of values, see SetHighlight
+	maxWidth  int       // width at which long lines are broken, see setMaxWidth
+	format    Format    // rendering of records
+
+	timeFormat string         // layout of the header timestamps. "" means DefaultTimeFormat
+	timeLoc    *time.Location // time zone of the header timestamps. nil means local time
+
+	stackFrames     int             // number of stack frames written under every record
+	groupGoroutines bool            // print a new header whenever the calling goroutine changes
+	hexThreshold    int             // []byte values longer than this are hex dumped. 0 disables it
+	profileLabels   bool            // label the goroutine with the call site, see SetProfileLabels
+	labelCtx        context.Context // context of the running Ctx call, see labeled
+
+	sinks  []Sink    // receive every record, the output first by default
+	recent *RingSink // keeps the last records for Recent. nil disables it
+
+	ctxValues []contextValue // values of the context logged by Ctx
+
+	level  atomic.Int32 // records below this Level are skipped
+	filter siteFilter   // call sites whose records are written. nil means all
+
+	async         *asyncWriter // writes flushes in the background. nil means synchronous writes
+	droppedBefore uint64       // writes dropped by the previous asyncWriters
+
+	dedup   bool   // collapse runs of identical records, see SetDedup
+	lastKey string // dedupKey of the last written record
+	repeats int    // number of records collapsed into the last written one
 }
 
 // header returns a formatted header string, e.g. [14:00:36 main.go main.main:122]
 // if the 2s timer has expired, or the calling function or filename has changed.
 // If none of those things are true, it returns an empty string.
-func (l *logger) header(funcName, file string, line int) string {
-	if !l.shouldPrintHeader(funcName, file) {
+func (l *logger) header(funcName, file string, line int, gid uint64) string {
+	if !l.shouldPrintHeader(funcName, file, gid) {
 		return ""
 	}
 
@@ -52,11 +95,12 @@ func (l *logger) header(funcName, file string, line int) string {
 	l.start = now
 	l.lastFunc = funcName
 	l.lastFile = file
+	l.lastGID = gid
 
-	return fmt.Sprintf("[%s %s:%d %s]\n[PID: %d os.Args: %s]",
-		now.Format("2006-01-02T15:04:05.000"),
+	return fmt.Sprintf("[%s %s:%d %s]\n[PID: %d GID: %d os.Args: %s]",
+		l.formatTime(now),
 		shortFile(file), line, funcName,
-		os.Getpid(), QuoteCommand(os.Args))
+		os.Getpid(), gid, QuoteCommand(os.Args))
 }
 
 var pattern = regexp.MustCompile(`[^\w@%+=:,./-]`)
@@ -86,7 +130,7 @@ func QuoteCommand(args []string) string {
 
 	return strings.Join(l, " ")
 }
-func (l *logger) shouldPrintHeader(funcName, file string) bool {
+func (l *logger) shouldPrintHeader(funcName, file string, gid uint64) bool {
 	if file != l.lastFile {
 		return true
 	}
@@ -95,6 +139,10 @@ func (l *logger) shouldPrintHeader(funcName, file string) bool {
 		return true
 	}
 
+	if l.groupGoroutines && gid != l.lastGID {
+		return true
+	}
+
 	// If less than 2s has elapsed, this log line will be printed under the
 	// previous header.
 	const timeWindow = 2 * time.Second
@@ -102,21 +150,88 @@ func (l *logger) shouldPrintHeader(funcName, file string) bool {
 	return time.Since(l.lastWrite) > timeWindow
 }
 
-var path = func() string {
-	uid := os.Getuid()
-	str := strconv.Itoa(uid)
-	if u, _ := user.LookupId(str); u != nil {
-		return filepath.Join(os.TempDir(), "q."+u.Username)
+// envOutput returns the writer selected by the value of $Q_OUTPUT: os.Stderr
+// for "stderr" and os.Stdout for "stdout". Any other value, including a file
+// path, returns nil so that flushes go to the log file at Path.
+func envOutput(v string) io.Writer {
+	switch v {
+	case "stderr":
+		return os.Stderr
+	case "stdout":
+		return os.Stdout
 	}
 
-	return filepath.Join(os.TempDir(), "q")
-}()
+	return nil
+}
 
-// flush writes the logger's buffer to disk.
-func (l *logger) flush() (err error) {
-	err = AppendFile(path, l.buf.Bytes(), 0o666)
+// flush writes the logger's buffer to disk, or to its configured output. An
+// asynchronous logger only queues a copy of the buffer, see SetAsync.
+func (l *logger) flush() error {
 	l.lastWrite = time.Now()
-	l.buf.Reset()
+	defer l.buf.Reset()
+
+	data := l.buf.Bytes()
+	strip := l.noColor && l.format != Binary
+	if !strip && l.async == nil {
+		return writeOutput(l.out, l.tee, data)
+	}
+
+	// Strip the colors into a pooled buffer, which also keeps the data of a
+	// queued write after the log buffer is reset.
+	scratch := getScratch()
+	if strip {
+		*scratch = appendStripColors(*scratch, data)
+	} else {
+		*scratch = append(*scratch, data...)
+	}
+
+	if l.async != nil {
+		l.async.enqueue(asyncWrite{data: scratch, out: l.out, tee: l.tee})
+		return nil
+	}
+
+	defer putScratch(scratch)
+
+	return writeOutput(l.out, l.tee, *scratch)
+}
+
+// scratchPool recycles the buffers of flush, see getScratch.
+var scratchPool = sync.Pool{ // nolint: gochecknoglobals
+	New: func() interface{} { return new([]byte) },
+}
+
+// maxScratch is the capacity above which buffers are not recycled, so that a
+// single huge record doesn't stay in memory.
+const maxScratch = 64 << 10
+
+// getScratch returns an empty buffer from the pool.
+func getScratch() *[]byte {
+	b := scratchPool.Get().(*[]byte) // nolint: forcetypeassert
+	*b = (*b)[:0]
+
+	return b
+}
+
+// putScratch returns a buffer of getScratch to the pool.
+func putScratch(b *[]byte) {
+	if cap(*b) <= maxScratch {
+		scratchPool.Put(b)
+	}
+}
+
+// writeOutput writes data to out, or to the log file if out is nil, and to
+// tee if it is set.
+func writeOutput(out, tee io.Writer, data []byte) (err error) {
+	if out == nil {
+		out = fileWriter(Path())
+	}
+	_, err = out.Write(data)
+
+	if tee != nil {
+		if _, teeErr := tee.Write(data); teeErr != nil {
+			err = MergeErrors(err, fmt.Errorf("tee: %w", teeErr))
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to flush q buffer: %w", err)
 	}
@@ -124,20 +239,37 @@ func (l *logger) flush() (err error) {
 	return nil
 }
 
-// AppendFile appends data to a file.
+// fileWriter appends the data written to it to the file it names, rotated by
+// SetMaxFileSize.
+type fileWriter string
+
+func (name fileWriter) Write(data []byte) (int, error) {
+	if err := appendRotated(string(name), data, 0o666); err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
+
+// AppendFile appends data to a file. The file is locked while appending, so
+// that the data of processes appending to the same file don't interleave.
 func AppendFile(name string, data []byte, mode os.FileMode) error {
 	f, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, mode)
 	if err != nil {
 		return fmt.Errorf("failed to open %q: %w", name, err)
 	}
-	err1 := os.Chmod(name, mode)
+	err1 := chmod(name, mode)
 	if err1 != nil {
-		err1 = fmt.Errorf("chmod %s to mod %s: %w", name, mode, err)
+		err1 = fmt.Errorf("chmod %s to mod %s: %w", name, mode, err1)
 	}
+	locked := lockFile(f) == nil // append anyway if locking fails
 	_, err2 := f.Write(data)
 	if err2 != nil {
 		err2 = fmt.Errorf("write %s: %w", name, err2)
 	}
+	if locked {
+		_ = unlockFile(f) // closing the file releases the lock as well
+	}
 	err3 := f.Close()
 	if err3 != nil {
 		err3 = fmt.Errorf("close %s: %w", name, err3)
@@ -176,42 +308,88 @@ func MergeErrors(errors ...error) error {
 }
 
 // output writes to the log buffer. Each log message is prepended with a
-// timestamp. Long lines are broken at 80 characters.
+// timestamp. Long lines are broken at the lineWidth. The buffer is written
+// directly, without formatting intermediate strings.
 func (l *logger) output(args ...string) {
-	timestamp := fmt.Sprintf("%.3fs", time.Since(l.start).Seconds())
+	var scratch [16]byte
+	timestamp := strconv.AppendFloat(scratch[:0], time.Since(l.start).Seconds(), 'f', 3, 64)
+	timestamp = append(timestamp, 's')
 	timestampWidth := len(timestamp) + 1 // +1 for padding space after timestamp
-	timestamp = colorize(timestamp, yellow)
 
-	// preWidth is the length of everything before the log message.
-	fmt.Fprint(&l.buf, timestamp, " ")
+	l.buf.WriteString(string(yellow))
+	l.buf.Write(timestamp)
+	l.buf.WriteString(string(endColor))
+	l.buf.WriteByte(' ')
 
 	// Subsequent lines have to be indented by the width of the timestamp.
-	indent := strings.Repeat(" ", timestampWidth)
-	padding := "" // padding is the space between args.
+	indent := spaces(timestampWidth)
 	lineArgs := 0 // number of args printed on the current log line.
 	lineWidth := timestampWidth
+	maxWidth := l.lineWidth()
 	for _, arg := range args {
 		argWidth := argWidth(arg)
-		lineWidth += argWidth + len(padding)
-
-		// Some names in name=value strings contain newlines. Insert indentation
-		// after each newline so they line up.
-		arg = strings.ReplaceAll(arg, "\n", "\n"+indent)
+		if lineArgs != 0 {
+			lineWidth++ // padding space between args
+		}
+		lineWidth += argWidth
 
 		// Break up long lines. If this is first arg printed on the line
 		// (lineArgs == 0), it makes no sense to break up the line.
-		if lineWidth > maxLineWidth && lineArgs != 0 {
-			fmt.Fprint(&l.buf, "\n", indent)
+		if lineWidth > maxWidth && lineArgs != 0 {
+			l.buf.WriteByte('\n')
+			l.buf.WriteString(indent)
 			lineArgs = 0
 			lineWidth = timestampWidth + argWidth
-			padding = ""
 		}
-		fmt.Fprint(&l.buf, padding, arg)
+		if lineArgs != 0 {
+			l.buf.WriteByte(' ')
+		}
+
+		// Some names in name=value strings contain newlines. Insert indentation
+		// after each newline so they line up.
+		for {
+			i := strings.IndexByte(arg, '\n')
+			if i < 0 {
+				l.buf.WriteString(arg)
+				break
+			}
+
+			l.buf.WriteString(arg[:i+1])
+			l.buf.WriteString(indent)
+			arg = arg[i+1:]
+		}
 		lineArgs++
-		padding = " "
 	}
 
-	fmt.Fprint(&l.buf, "\n")
+	l.buf.WriteByte('\n')
+}
+
+// blanks backs the indentation returned by spaces.
+const blanks = "                                "
+
+// spaces returns a string of n spaces, without allocating for the usual
+// indentation widths.
+func spaces(n int) string {
+	if n <= len(blanks) {
+		return blanks[:n]
+	}
+
+	return strings.Repeat(" ", n)
+}
+
+// lineWidth returns the width at which output breaks long lines.
+func (l *logger) lineWidth() int {
+	switch {
+	case l.maxWidth > 0:
+		return l.maxWidth
+	case l.maxWidth == noWrap:
+		return math.MaxInt
+	}
+	if width, ok := l.terminalWidth(); ok {
+		return width
+	}
+
+	return maxLineWidth
 }
 
 // shortFile takes an absolute file path and returns just the <directory>/<file>,
@@ -6,6 +6,10 @@ package q
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
@@ -82,7 +86,7 @@ func TestHeader(t *testing.T) {
 		}
 
 		const line = 123
-		h := l.header(tc.currFunc, tc.currFile, line)
+		h := l.header(tc.currFunc, tc.currFile, line, 0)
 		if tc.wantEmptyString {
 			if h == "" {
 				continue
@@ -129,3 +133,72 @@ func TestOutput(t *testing.T) {
 		}
 	}
 }
+
+// TestEnvOutput verifies that envOutput() maps $Q_OUTPUT values to the
+// expected writers.
+func TestEnvOutput(t *testing.T) {
+	testCases := []struct {
+		value string
+		want  io.Writer
+	}{
+		{value: "stderr", want: os.Stderr},
+		{value: "stdout", want: os.Stdout},
+		{value: "", want: nil},
+		{value: "/var/log/q", want: nil},
+	}
+
+	for _, tc := range testCases {
+		got := envOutput(tc.value)
+		if got != tc.want {
+			t.Fatalf("\nenvOutput(%q)\ngot:  %v\nwant: %v", tc.value, got, tc.want)
+		}
+	}
+}
+
+// TestHeaderGoroutineGrouping verifies that a goroutine change only prints a
+// new header when goroutine grouping is enabled.
+func TestHeaderGoroutineGrouping(t *testing.T) {
+	for _, group := range []bool{false, true} {
+		l := logger{groupGoroutines: group}
+		if h := l.header("main.main", "main.go", 1, 1); !strings.Contains(h, "GID: 1 ") {
+			t.Fatalf("\nl.header(main.main, main.go, 1, 1)\ngot:  %q\nmissing goroutine id", h)
+		}
+		l.lastWrite = time.Now()
+
+		h := l.header("main.main", "main.go", 2, 2)
+		if (h != "") != group {
+			t.Fatalf("\ngroupGoroutines=%v\nl.header(main.main, main.go, 2, 2)\ngot:  %q", group, h)
+		}
+	}
+}
+
+// TestAppendFileMode verifies that AppendFile() sets the permissions of the
+// file, where the platform has permission bits.
+func TestAppendFileMode(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "q")
+	if err := AppendFile(name, []byte("x\n"), 0o600); err != nil {
+		t.Fatalf("AppendFile(%q): %v", name, err)
+	}
+
+	fi, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if runtime.GOOS != "windows" && fi.Mode().Perm() != 0o600 {
+		t.Fatalf("\nAppendFile(%q, 0600)\ngot:  %v\nwant: -rw-------", name, fi.Mode())
+	}
+}
+
+// BenchmarkOutput measures writing the formatted arguments of a record, with
+// a line break, to the log buffer.
+func BenchmarkOutput(b *testing.B) {
+	l := &logger{}
+	args := prependArgName([]string{"port", "host"}, formatArgs(nil, 443, strings.Repeat("x", 70)))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.output(args...)
+		l.buf.Reset()
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// outputMarkdown writes the record to the log buffer as Markdown.
+func (l *logger) outputMarkdown(r Record) {
+	writeMarkdown(&l.buf, r)
+}
+
+// writeMarkdown writes the record to w as Markdown, ready to paste into an
+// issue: a table of the time, level and caller, followed by the arguments as
+// name = value in a fenced code block, e.g.
+//
+//	| time | level | file | func |
+//	| --- | --- | --- | --- |
+//	| 2006-01-02T15:04:05.000Z | info | main.go:42 | main.main |
+//
+//	```go
+//	port = int(443)
+//	```
+func writeMarkdown(w io.Writer, r Record) {
+	fmt.Fprint(w, "| time | level | file | func |\n| --- | --- | --- | --- |\n")
+	file := ""
+	if r.File != "" {
+		file = shortFile(r.File) + ":" + strconv.Itoa(r.Line)
+	}
+	fmt.Fprintf(w, "| %s | %s | %s | %s |\n\n", r.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+		strings.ToLower(r.Level.String()), markdownCell(file), markdownCell(r.Func))
+
+	var b strings.Builder
+	for i, v := range r.Values {
+		if i < len(r.Names) && r.Names[i] != "" {
+			b.WriteString(r.Names[i] + " = ")
+		}
+		b.WriteString(sprint(v) + "\n")
+	}
+	writeFence(w, "go", b.String())
+
+	if len(r.Stack) > 0 {
+		writeFence(w, "", strings.Join(r.Stack, "\n")+"\n")
+	}
+}
+
+// writeFence writes s, which ends with a newline, to w as a fenced code block
+// of the language lang, followed by an empty line. The fence is longer than
+// any run of backticks in s, so that s cannot close it.
+func writeFence(w io.Writer, lang, s string) {
+	n, run := 3, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != '`' {
+			run = 0
+			continue
+		}
+		if run++; run >= n {
+			n = run + 1
+		}
+	}
+
+	fence := strings.Repeat("`", n)
+	fmt.Fprint(w, fence, lang, "\n", s, fence, "\n\n")
+}
+
+// markdownCell escapes s for a cell of a Markdown table.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
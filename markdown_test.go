@@ -0,0 +1,54 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestOutputMarkdown verifies that the Markdown format writes a table of the
+// caller followed by the name = value pairs in a code block.
+func TestOutputMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFormat(Markdown))
+
+	port := 443
+	l.Q(port, "done")
+
+	got := buf.String()
+	for _, want := range []string{
+		"| time | level | file | func |\n| --- | --- | --- | --- |\n| ",
+		" | info | ",
+		"markdown_test.go:",
+		" | github.com/bingoohuang/q.TestOutputMarkdown |\n\n",
+		"```go\nport = int(443)\ndone\n```\n\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+}
+
+// TestWriteFence verifies that code blocks can't be closed by their content.
+func TestWriteFence(t *testing.T) {
+	testCases := map[string]string{
+		"a\n":           "```go\na\n```\n\n",
+		"`a`\n":         "```go\n`a`\n```\n\n",
+		"```\na\n```\n": "````go\n```\na\n```\n````\n\n",
+		"a ````` b\n":   "``````go\na ````` b\n``````\n\n",
+	}
+
+	for s, want := range testCases {
+		var buf bytes.Buffer
+		writeFence(&buf, "go", s)
+		if got := buf.String(); got != want {
+			t.Fatalf("\nwriteFence(%q)\ngot:  %q\nwant: %q", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	netSinkBuffer     = 1024 // records waiting to be sent
+	netSinkTimeout    = 5 * time.Second
+	netSinkMinBackoff = 100 * time.Millisecond
+	netSinkMaxBackoff = 10 * time.Second
+	netSinkAttempts   = 5 // tries to send a record before it is dropped
+)
+
+// ErrSinkClosed is returned when writing to a closed sink.
+var ErrSinkClosed = errors.New("q: sink closed")
+
+// NetSink is a Sink that sends records over a network connection, one record
+// per line. Records are buffered and sent by a background goroutine, which
+// reconnects with exponential backoff when the connection fails. Records that
+// don't fit into the buffer or can't be sent after a few tries are dropped
+// and counted. As with any stream, a record written just before the peer
+// closed the connection may be lost.
+type NetSink struct {
+	// Format of the sent records, JSONL by default. Text is sent as Logfmt,
+	// since the text format needs the headers of preceding records. Set it
+	// before adding the sink.
+	Format Format
+
+	network, addr string
+	records       chan []byte
+	quit          chan struct{}
+	done          chan struct{}
+	dropped       atomic.Uint64
+
+	mu     sync.Mutex // protects closed
+	closed bool
+}
+
+// DialSink returns a NetSink sending records to addr on the named network,
+// e.g. q.AddSink(q.DialSink("udp", "host:5514")). See net.Dial for the
+// networks and addresses. The connection is established in the background.
+func DialSink(network, addr string) *NetSink {
+	s := &NetSink{
+		Format:  JSONL,
+		network: network,
+		addr:    addr,
+		records: make(chan []byte, netSinkBuffer),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+
+	return s
+}
+
+// Write encodes the record and queues it for sending. It never blocks.
+func (s *NetSink) Write(r Record) error {
+	var buf bytes.Buffer
+	if s.Format == JSONL {
+		b, err := marshalJSON(r, 0)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	} else {
+		writeLogfmt(&buf, r)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSinkClosed
+	}
+
+	select {
+	case s.records <- buf.Bytes():
+	default:
+		s.dropped.Add(1)
+	}
+
+	return nil
+}
+
+// Dropped returns the number of records dropped because the buffer was full
+// or they could not be sent.
+func (s *NetSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close sends the buffered records, giving up on the first failure, and
+// closes the connection.
+func (s *NetSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.records)
+	close(s.quit)
+	s.mu.Unlock()
+
+	<-s.done
+
+	return nil
+}
+
+// run sends the queued records until the sink is closed. A record that can't
+// be sent in netSinkAttempts tries, e.g. because it is too large for a UDP
+// datagram or the peer is down, is dropped and counted. Once the sink is
+// closed, the first failure drops the records that are left.
+func (s *NetSink) run() {
+	defer close(s.done)
+
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	backoff := netSinkMinBackoff
+	for b := range s.records {
+		for attempt := 1; ; attempt++ {
+			if conn == nil {
+				if c, err := net.DialTimeout(s.network, s.addr, netSinkTimeout); err == nil {
+					conn, backoff = c, netSinkMinBackoff
+				}
+			}
+
+			if conn != nil {
+				_ = conn.SetWriteDeadline(time.Now().Add(netSinkTimeout))
+				if _, err := conn.Write(b); err == nil {
+					break
+				}
+				conn.Close()
+				conn = nil // reconnect and resend
+			}
+
+			if attempt == netSinkAttempts {
+				s.dropped.Add(1)
+				break
+			}
+
+			if !s.sleep(backoff) {
+				// Closed: drop this record and the rest of the buffer.
+				s.dropped.Add(1)
+				for range s.records {
+					s.dropped.Add(1)
+				}
+
+				return
+			}
+			backoff = min(2*backoff, netSinkMaxBackoff)
+		}
+	}
+}
+
+// sleep waits for d and returns true, or returns false as soon as the sink is
+// closed.
+func (s *NetSink) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-s.quit:
+		return false
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNetSink verifies that a NetSink sends records as JSON lines and
+// reconnects after the connection was closed by the peer.
+func TestNetSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+
+	s := DialSink("tcp", ln.Addr().String())
+	defer s.Close()
+
+	l := New(WithOutput(io.Discard), WithSink(s))
+
+	for _, want := range []string{"first", "second"} {
+		// A write to a connection closed by the peer may still succeed, so keep
+		// logging until the sink has reconnected.
+		stop := make(chan struct{})
+		go func() {
+			for {
+				l.Q(want)
+				select {
+				case <-stop:
+					return
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+		}()
+
+		conn, err := ln.Accept()
+		close(stop)
+		if err != nil {
+			t.Fatalf("accept: %v", err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		// Records of the previous round may be resent after the reconnect.
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+
+			var jr jsonRecord
+			if err := json.Unmarshal([]byte(line), &jr); err != nil {
+				t.Fatalf("json.Unmarshal(%q): %v", line, err)
+			}
+
+			if len(jr.Args) != 1 {
+				t.Fatalf("\ngot:  %q\nwant: record of %q", line, want)
+			}
+
+			if jr.Args[0].Value == want {
+				break
+			}
+		}
+
+		// Closing the connection makes the sink reconnect.
+		conn.Close()
+	}
+}
+
+// TestNetSinkClosed verifies that writing to a closed NetSink fails.
+func TestNetSinkClosed(t *testing.T) {
+	s := DialSink("tcp", "127.0.0.1:1")
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+
+	if err := s.Write(Record{}); err != ErrSinkClosed {
+		t.Fatalf("Write() after Close() = %v, want %v", err, ErrSinkClosed)
+	}
+}
+
+// TestNetSinkUnsendable verifies that a NetSink drops a record it can't send,
+// here one too large for a UDP datagram, and that Close doesn't hang on it.
+func TestNetSinkUnsendable(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer pc.Close()
+
+	s := DialSink("udp", pc.LocalAddr().String())
+	if err := s.Write(Record{Values: []interface{}{strings.Repeat("x", 1<<17)}}); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	closed := make(chan error)
+	go func() { closed <- s.Close() }()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close(): %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Close() did not return")
+	}
+
+	if got := s.Dropped(); got != 1 {
+		t.Fatalf("\ngot:  Dropped() = %d\nwant: 1", got)
+	}
+}
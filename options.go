@@ -0,0 +1,93 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"io"
+	"os"
+)
+
+// Logger is a q logger with its own configuration, isolated from the
+// package-level Q function. Create one with New.
+type Logger struct {
+	logger
+}
+
+// Option configures a Logger created by New.
+type Option func(*logger)
+
+// New returns a Logger configured by the given options. Without WithOutput,
+// it writes to the same $TMPDIR/$USER.q log file as Q.
+func New(opts ...Option) *Logger {
+	l := &Logger{logger{
+		noColor:    envNoColor(),
+		filter:     envFilter(os.Getenv("Q_FILTER")),
+		timeFormat: os.Getenv("Q_TIME_FORMAT"),
+		timeLoc:    envTimeLocation(os.Getenv("Q_TIME_LOCATION")),
+	}}
+	l.level.Store(int32(envLevel(os.Getenv("Q_LEVEL"))))
+	l.sinks = []Sink{outputSink{&l.logger}}
+	for _, opt := range opts {
+		opt(&l.logger)
+	}
+
+	if !l.noColor {
+		enableTerminalColors(l.out)
+		enableTerminalColors(l.tee)
+	}
+
+	return l
+}
+
+// WithOutput makes the Logger write to w instead of the log file.
+func WithOutput(w io.Writer) Option {
+	return func(l *logger) { l.out = w }
+}
+
+// WithTee makes the Logger write everything to w as well as to its output.
+func WithTee(w io.Writer) Option {
+	return func(l *logger) { l.tee = w }
+}
+
+// WithColors enables or disables ANSI colors in the output. Colors are
+// enabled by default, unless $NO_COLOR is set.
+func WithColors(enabled bool) Option {
+	return func(l *logger) { l.noColor = !enabled }
+}
+
+// WithMaxWidth sets the width at which long output lines are broken, like
+// SetMaxWidth. The default is AutoWidth.
+func WithMaxWidth(width int) Option {
+	return func(l *logger) { l.setMaxWidth(width) }
+}
+
+// Q pretty-prints the given arguments to the Logger's output.
+func (l *Logger) Q(v ...interface{}) {
+	if off {
+		return
+	}
+
+	l.q(CallDepth, v...)
+}
+
+// If pretty-prints the given arguments to the Logger's output only if cond is
+// true.
+func (l *Logger) If(cond bool, v ...interface{}) {
+	if off || !cond {
+		return
+	}
+
+	l.log(CallDepth, v, 1)
+}
+
+// Qf formats according to a format specifier and writes the result to the
+// Logger's output.
+func (l *Logger) Qf(format string, v ...interface{}) {
+	if off {
+		return
+	}
+
+	l.qf(CallDepth, format, v...)
+}
@@ -0,0 +1,38 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestNew verifies that a Logger created by New writes name=value pairs to
+// its own output, honoring the color and width options.
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false), WithMaxWidth(20))
+
+	alpha, beta := "aaaaaaaaaa", "bbbbbbbbbb"
+	l.Q(alpha, beta)
+
+	got := buf.String()
+	if strings.Contains(got, "\033[") {
+		t.Fatalf("\nLogger.Q()\ngot:  %q\nwant: no ANSI escape codes", got)
+	}
+
+	for _, want := range []string{"alpha=aaaaaaaaaa", "beta=bbbbbbbbbb", "q.TestNew"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\nLogger.Q()\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+
+	if !strings.Contains(got, "\n       beta=") {
+		t.Fatalf("\nLogger.Q()\ngot:  %q\nwant: line broken before beta", got)
+	}
+}
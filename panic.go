@@ -0,0 +1,105 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"runtime"
+	"strings"
+	"time"
+)
+
+// CatchPanic writes the value and the full stack of a panic to the
+// $TMPDIR/$USER.q log file, under the header of the panicking function, and
+// then panics again with the same value. It must be deferred:
+//
+//	defer q.CatchPanic()
+//
+// The record is written synchronously, also after SetAsync, so the log file
+// has the last records followed by the crash.
+func CatchPanic() {
+	if off {
+		return
+	}
+
+	if v := recover(); v != nil {
+		std.panicked(v)
+		panic(v)
+	}
+}
+
+// CatchPanic writes the value and the full stack of a panic to the Logger's
+// output and panics again, like q.CatchPanic. It must be deferred.
+func (l *Logger) CatchPanic() {
+	if off {
+		return
+	}
+
+	if v := recover(); v != nil {
+		l.panicked(v)
+		panic(v)
+	}
+}
+
+// panicked writes the record of the panic value v at LevelWarn and waits
+// until it is written.
+func (l *logger) panicked(v interface{}) {
+	defer l.flushAsync()
+
+	if l.skip(LevelWarn) {
+		return
+	}
+
+	r := Record{
+		Time:   time.Now(),
+		Level:  LevelWarn,
+		GID:    goroutineID(),
+		Values: []interface{}{"panic: " + sprint(v)},
+	}
+
+	for _, f := range panicFrames() {
+		if r.File == "" && !isRuntimeFrame(f) {
+			// Runtime errors panic in the runtime, e.g. in
+			// runtime.mapassign_faststr, write them under the code causing them.
+			r.Func, r.File, r.Line = f.Function, f.File, f.Line
+		}
+		r.Stack = append(r.Stack, formatFrame(f))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.write(r)
+}
+
+// panicFrames returns the stack of the panicking goroutine, starting at the
+// function that panicked. It must be called by a deferred function while
+// panicking.
+func panicFrames() []runtime.Frame {
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []runtime.Frame
+	for {
+		f, more := frames.Next()
+		if stack != nil || f.Function == "runtime.gopanic" {
+			stack = append(stack, f)
+		}
+		if !more {
+			break
+		}
+	}
+
+	if len(stack) == 0 {
+		return nil
+	}
+
+	return stack[1:] // skip runtime.gopanic
+}
+
+// isRuntimeFrame reports whether the frame is in the Go runtime.
+func isRuntimeFrame(f runtime.Frame) bool {
+	return strings.HasPrefix(f.Function, "runtime.") || strings.HasPrefix(f.Function, "internal/")
+}
@@ -0,0 +1,54 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestCatchPanic verifies that CatchPanic() writes the panic value and stack
+// under the header of the panicking function and panics again.
+func TestCatchPanic(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false), WithAsync(4, Block))
+	defer l.Close()
+
+	crash := func() {
+		defer l.CatchPanic()
+
+		var m map[string]int
+		m["boom"] = 1
+	}
+
+	var recovered interface{}
+	func() {
+		defer func() { recovered = recover() }()
+		crash()
+	}()
+
+	if recovered == nil {
+		t.Fatal("CatchPanic() didn't panic again")
+	}
+
+	got := buf.String() // written although the Logger is asynchronous
+	for _, want := range []string{
+		"q.TestCatchPanic.func1]\n",
+		" WARN panic: assignment to entry in nil map",
+		"    at github.com/bingoohuang/q.TestCatchPanic.func1 ",
+		"    at github.com/bingoohuang/q.TestCatchPanic ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\nCatchPanic()\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+
+	if strings.Contains(got, "runtime.gopanic") || strings.Contains(got, "q.panicFrames") {
+		t.Fatalf("\nCatchPanic()\ngot:  %q\nwant: no frames of the panic handling", got)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// path is the path of the log file, see SetPath.
+var path atomic.Pointer[string] // nolint: gochecknoglobals
+
+func init() { // nolint: gochecknoinits
+	SetPath("")
+}
+
+// SetPath makes Q and the Loggers without WithOutput write to the log file at
+// p, e.g. in a directory where the application has write access. The file is
+// opened by name for every write, so records written concurrently go either
+// to the old or to the new file. An empty p restores the
+// initial path: $Q_LOGFILE, $Q_OUTPUT if it is a path, or $TMPDIR/q.$USER.
+func SetPath(p string) {
+	if p == "" {
+		p = envPath()
+	}
+
+	path.Store(&p)
+}
+
+// Path returns the path of the log file written by Q.
+func Path() string {
+	return *path.Load()
+}
+
+// envPath returns the path of the log file set by $Q_LOGFILE or $Q_OUTPUT,
+// defaultPath if none is.
+func envPath() string {
+	if p := os.Getenv("Q_LOGFILE"); p != "" {
+		return p
+	}
+
+	if p := os.Getenv("Q_OUTPUT"); p != "" && envOutput(p) == nil {
+		return p
+	}
+
+	return defaultPath()
+}
+
+// defaultPath returns the path of the log file named after the current user in
+// the temporary directory: $TMPDIR/q.$USER, or %TEMP%\q.%USERNAME% on Windows.
+func defaultPath() string {
+	if name := userName(); name != "" {
+		return filepath.Join(os.TempDir(), "q."+name)
+	}
+
+	return filepath.Join(os.TempDir(), "q")
+}
+
+// trimDomain strips the domain from a Windows user name, e.g. DOMAIN\alice.
+func trimDomain(name string) string {
+	if i := strings.LastIndexByte(name, '\\'); i >= 0 {
+		return name[i+1:]
+	}
+
+	return name
+}
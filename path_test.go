@@ -0,0 +1,91 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestSetPath verifies that SetPath() switches the log file while other
+// goroutines are logging, without losing records.
+func TestSetPath(t *testing.T) {
+	first := setTestPath(t)
+	second := filepath.Join(t.TempDir(), "q")
+
+	const n = 50
+	Q("logged") // the first file exists even if SetPath wins the race
+	var wg sync.WaitGroup
+	for i := 1; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Q("logged")
+		}()
+
+		if i == n/2 {
+			SetPath(second)
+		}
+	}
+	wg.Wait()
+
+	if got := Path(); got != second {
+		t.Fatalf("\nPath()\ngot:  %q\nwant: %q", got, second)
+	}
+
+	count := strings.Count(readLog(t, first), "logged") + strings.Count(readLog(t, second), "logged")
+	if count != n {
+		t.Fatalf("\nSetPath()\ngot:  %d records\nwant: %d", count, n)
+	}
+}
+
+// TestEnvPath verifies that $Q_LOGFILE takes precedence over a $Q_OUTPUT path
+// and that $Q_OUTPUT=stderr keeps the default path.
+func TestEnvPath(t *testing.T) {
+	t.Setenv("Q_LOGFILE", "")
+	t.Setenv("Q_OUTPUT", "stderr")
+	if got := envPath(); got != defaultPath() {
+		t.Fatalf("\nenvPath()\ngot:  %q\nwant: %q", got, defaultPath())
+	}
+
+	t.Setenv("Q_OUTPUT", "/tmp/output.q")
+	if got := envPath(); got != "/tmp/output.q" {
+		t.Fatalf("\nenvPath()\ngot:  %q\nwant: %q", got, "/tmp/output.q")
+	}
+
+	t.Setenv("Q_LOGFILE", "/tmp/logfile.q")
+	if got := envPath(); got != "/tmp/logfile.q" {
+		t.Fatalf("\nenvPath()\ngot:  %q\nwant: %q", got, "/tmp/logfile.q")
+	}
+}
+
+// TestDefaultPath verifies that the default log file is in the temporary
+// directory and named after the user, without a Windows domain.
+func TestDefaultPath(t *testing.T) {
+	got := defaultPath()
+	if filepath.Dir(got) != filepath.Clean(os.TempDir()) {
+		t.Fatalf("\ndefaultPath()\ngot:  %q\nwant: file in %q", got, os.TempDir())
+	}
+
+	if base := filepath.Base(got); base != "q" && base != "q."+userName() {
+		t.Fatalf("\ndefaultPath()\ngot:  %q\nwant: q.%s", got, userName())
+	}
+
+	for name, want := range map[string]string{
+		"alice":        "alice",
+		`DOMAIN\alice`: "alice",
+		`A\B\alice`:    "alice",
+		"":             "",
+	} {
+		if got := trimDomain(name); got != want {
+			t.Fatalf("\ntrimDomain(%q)\ngot:  %q\nwant: %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package pretty
+
+import "strings"
+
+// TB is the part of testing.TB used by AssertEqual and RequireEqual, so that
+// the package needn't import testing. The standard library testing.T and
+// testing.B are TBs.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// AssertEqual reports the differences between want and got found by Diff
+// with the options, if any, with t.Errorf, e.g.
+//
+//	pretty.AssertEqual(t, want, got, pretty.IgnorePaths("UpdatedAt"))
+//
+// fails the test with
+//
+//	values differ (want != got):
+//		Items[0].Price: 10 != 12
+//
+// It reports whether want and got are equal.
+func AssertEqual(t TB, want, got interface{}, opts ...DiffOpt) bool {
+	t.Helper()
+	if diffs := Diff(want, got, opts...); len(diffs) > 0 {
+		t.Errorf("%s", diffMessage(diffs))
+		return false
+	}
+	return true
+}
+
+// RequireEqual is AssertEqual with t.Fatalf, which stops the test.
+func RequireEqual(t TB, want, got interface{}, opts ...DiffOpt) {
+	t.Helper()
+	if diffs := Diff(want, got, opts...); len(diffs) > 0 {
+		t.Fatalf("%s", diffMessage(diffs))
+	}
+}
+
+// diffMessage returns the differences found by Diff for a test failure, one
+// per line, indented by a tab like their continuation lines.
+func diffMessage(diffs []string) string {
+	var b strings.Builder
+	b.WriteString("values differ (want != got):")
+	for _, d := range diffs {
+		b.WriteString("\n\t" + strings.ReplaceAll(d, "\n", "\n\t"))
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package pretty
+
+import (
+	"fmt"
+	"testing"
+)
+
+// fakeTB records the failures of AssertEqual and RequireEqual.
+type fakeTB struct {
+	errors, fatals []string
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeTB) Fatalf(format string, args ...interface{}) {
+	t.fatals = append(t.fatals, fmt.Sprintf(format, args...))
+}
+
+var _ TB = (testing.TB)(nil)
+
+func TestAssertEqual(t *testing.T) {
+	want := Doc{ID: 1, Items: []Meta{{ID: 1, UpdatedAt: 1}}}
+	got := Doc{ID: 1, Items: []Meta{{ID: 2, UpdatedAt: 2}}}
+
+	var tb fakeTB
+	if !AssertEqual(&tb, want, want) || len(tb.errors) > 0 {
+		t.Errorf("AssertEqual(want, want) failed: %q", tb.errors)
+	}
+	if AssertEqual(&tb, want, got, IgnorePaths("*.UpdatedAt")) {
+		t.Error("AssertEqual(want, got) = true")
+	}
+	exp := "values differ (want != got):\n\tItems[0].ID: 1 != 2"
+	if len(tb.errors) != 1 || tb.errors[0] != exp {
+		t.Errorf("expected %q", exp)
+		t.Errorf("got      %q", tb.errors)
+	}
+
+	tb = fakeTB{}
+	RequireEqual(&tb, "a\nb\nc", "a\nB\nc")
+	exp = "values differ (want != got):\n\t@@ -1,3 +1,3 @@\n\t a\n\t-b\n\t+B\n\t c"
+	if len(tb.fatals) != 1 || tb.fatals[0] != exp || len(tb.errors) > 0 {
+		t.Errorf("expected %q", exp)
+		t.Errorf("got      %q", tb.fatals)
+	}
+
+	AssertEqual(t, []int{1, 2}, []int{1, 2})
+	RequireEqual(t, map[string]int{}, map[string]int(nil))
+}
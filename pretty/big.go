@@ -0,0 +1,83 @@
+package pretty
+
+import (
+	"math/big"
+	"reflect"
+)
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+)
+
+// RawBig prints big.Int, big.Float and big.Rat values like other structs,
+// instead of as a decimal number like 12345678901234567890, 1.5 or 1/3.
+func RawBig(on bool) Option {
+	return func(f *formatter) { f.rawBig = on }
+}
+
+// formatBig returns the decimal form of a big.Int, big.Float or big.Rat, or
+// of a pointer to one, and false for values of other types. Floats are
+// printed with the digits that tell them apart at their precision, and
+// rationals that are integers without the denominator.
+func formatBig(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	switch v.Type() {
+	case bigIntType, bigFloatType, bigRatType:
+	default:
+		return "", false
+	}
+	if !v.CanAddr() {
+		if !v.CanInterface() {
+			return "", false
+		}
+		// The methods have pointer receivers. The copy shares the words of
+		// v, which they don't modify.
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	} else if !v.Addr().CanInterface() {
+		return "", false
+	}
+	switch x := v.Addr().Interface().(type) {
+	case *big.Int:
+		return x.String(), true
+	case *big.Float:
+		return x.Text('g', -1), true
+	case *big.Rat:
+		return x.RatString(), true
+	}
+	return "", false
+}
+
+// printBig prints v with formatBig, and returns false if v isn't a big
+// number. Like the elements of a slice, pointers are printed as their
+// number, with an & if the type is shown, e.g. &big.Int(42).
+func (p *printer) printBig(v reflect.Value, showType bool) bool {
+	if p.rawBig || p.goSyntax || !v.IsValid() {
+		return false
+	}
+	s, ok := formatBig(v)
+	if !ok {
+		return false
+	}
+	if showType {
+		if v.Kind() == reflect.Ptr {
+			writeByte(p, '&')
+			v = v.Elem()
+		}
+		p.writeType(v.Type())
+		writeByte(p, '(')
+	}
+	p.writeColored(NumberColor, s)
+	if showType {
+		writeByte(p, ')')
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package pretty
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+type Balance struct {
+	Amount *big.Int
+	Rate   big.Float
+	Share  *big.Rat
+}
+
+func TestBig(t *testing.T) {
+	n, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	b := Balance{Amount: big.NewInt(42), Rate: *big.NewFloat(1.5), Share: big.NewRat(1, 3)}
+	cases := []struct {
+		opts []Option
+		v    interface{}
+		s    string
+	}{
+		{nil, n, `&big.Int(-123456789012345678901234567890)`},
+		{nil, *n, `big.Int(-123456789012345678901234567890)`},
+		{nil, big.NewFloat(0.1), `&big.Float(0.1)`},
+		{nil, big.NewRat(4, 2), `&big.Rat(2)`},
+		{nil, (*big.Int)(nil), `(*big.Int)(nil)`},
+		{[]Option{Compact(true)}, []*big.Int{big.NewInt(1), nil}, `[]*big.Int{1, (*big.Int)(nil)}`},
+		{nil, b, `pretty.Balance{
+    Amount: 42,
+    Rate:   big.Float(1.5),
+    Share:  1/3,
+}`},
+		{[]Option{Compact(true), RawBig(true), UnexportedFields(HideUnexported)}, big.NewInt(42), `&big.Int{}`},
+	}
+	for _, tt := range cases {
+		s := fmt.Sprint(NewFormatter(tt.v, tt.opts...))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+			t.Errorf("expraw\n%s", tt.s)
+			t.Errorf("gotraw\n%s", s)
+		}
+	}
+}
+
+func TestDiffBig(t *testing.T) {
+	a := Balance{Amount: big.NewInt(1), Rate: *big.NewFloat(1.5), Share: big.NewRat(1, 3)}
+	b := Balance{Amount: big.NewInt(2), Rate: *big.NewFloat(1.5), Share: big.NewRat(2, 3)}
+	want := []string{
+		"Amount: 1 != 2",
+		"Share: 1/3 != 2/3",
+	}
+	diffdiff(t, Diff(a, b), want)
+	diffdiff(t, Diff(Balance{Amount: big.NewInt(1)}, Balance{}), []string{"Amount: &big.Int(1) != nil"})
+}
@@ -0,0 +1,147 @@
+package pretty
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// BytesFormat selects how byte slices and arrays are printed.
+type BytesFormat uint8
+
+const (
+	// BytesAuto prints bytes that are printable UTF-8 text as a quoted
+	// string, and other bytes in hex, the default.
+	BytesAuto BytesFormat = iota
+	// BytesHex prints bytes in hex, in groups of 4 bytes, e.g.
+	// []uint8(hex:89504e47 0d0a1a0a).
+	BytesHex
+	// BytesBase64 prints bytes in standard base64 encoding, e.g.
+	// []uint8(base64:iVBORw0KGgo=).
+	BytesBase64
+	// BytesList prints bytes like other slices and arrays, as a list of
+	// numbers.
+	BytesList
+)
+
+// defaultMaxBytes is the number of bytes printed in hex or base64 unless set
+// by MaxBytes.
+const defaultMaxBytes = 64
+
+// Bytes selects how byte slices and arrays are printed.
+func Bytes(f BytesFormat) Option {
+	return func(fo *formatter) { fo.bytes = f }
+}
+
+// MaxBytes limits the bytes printed in hex or base64 to n, followed by
+// …(+1234 more bytes). The default is 64, a negative n means no limit. Bytes
+// printed as text are limited like strings, see Limits.MaxStringLen.
+func MaxBytes(n int) Option {
+	return func(f *formatter) { f.maxBytes = n }
+}
+
+// printBytes prints v, if it is a non-empty byte slice or array, in the
+// format selected by Bytes, and returns false otherwise.
+func (p *printer) printBytes(v reflect.Value, showType bool) bool {
+	if p.bytes == BytesList || v.Kind() != reflect.Slice && v.Kind() != reflect.Array ||
+		v.Type().Elem().Kind() != reflect.Uint8 || v.Len() == 0 {
+		return false
+	}
+	b := bytesOf(v)
+
+	if p.goSyntax {
+		// Only a slice can be converted from a string, arrays are printed as
+		// lists.
+		if v.Kind() != reflect.Slice {
+			return false
+		}
+		p.writeType(v.Type())
+		writeByte(p, '(')
+		p.writeColored(StringColor, strconv.Quote(string(b)))
+		writeByte(p, ')')
+		return true
+	}
+
+	format := p.bytes
+	if format == BytesAuto {
+		if isText(b) {
+			if showType {
+				p.writeType(v.Type())
+				writeByte(p, '(')
+			}
+			p.fmtString(string(b), true)
+			if showType {
+				writeByte(p, ')')
+			}
+			return true
+		}
+		format = BytesHex
+	}
+
+	more := 0
+	if n := p.maxBytes; n >= 0 {
+		if n == 0 {
+			n = defaultMaxBytes
+		}
+		if len(b) > n {
+			b, more = b[:n], len(b)-n
+		}
+	}
+	if showType {
+		p.writeType(v.Type())
+		writeByte(p, '(')
+	}
+	p.color(NumberColor)
+	if format == BytesBase64 {
+		io.WriteString(p, "base64:")
+		io.WriteString(p, base64.StdEncoding.EncodeToString(b))
+	} else {
+		io.WriteString(p, "hex:")
+		for i := 0; i < len(b); i += 4 {
+			if i > 0 {
+				writeByte(p, ' ')
+			}
+			io.WriteString(p, hex.EncodeToString(b[i:min(i+4, len(b))]))
+		}
+	}
+	p.endColor()
+	if more > 0 {
+		fmt.Fprintf(p, "…(+%d more bytes)", more)
+	}
+	if showType {
+		writeByte(p, ')')
+	}
+	return true
+}
+
+// bytesOf returns the bytes of a byte slice or array. Unlike v.Bytes, it
+// works for arrays that are not addressable.
+func bytesOf(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice {
+		return v.Bytes()
+	}
+	b := make([]byte, v.Len())
+	for i := range b {
+		b[i] = byte(v.Index(i).Uint())
+	}
+	return b
+}
+
+// isText reports whether b is valid UTF-8 made of printable characters and
+// whitespace.
+func isText(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package pretty
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+type Blob struct {
+	Name []byte
+	Data []byte
+	Sum  [4]byte
+}
+
+func TestBytes(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	cases := []struct {
+		opts []Option
+		v    interface{}
+		s    string
+	}{
+		{nil, []byte("hello\n"), `[]uint8("hello\n")`},
+		{nil, []byte("héllo"), `[]uint8("héllo")`},
+		{nil, png, `[]uint8(hex:89504e47 0d0a1a0a)`},
+		{nil, []byte{0xff, 0xfe, 1}, `[]uint8(hex:fffe01)`},
+		{nil, [4]byte{0xde, 0xad, 0xbe, 0xef}, `[4]uint8(hex:deadbeef)`},
+		{nil, []byte{}, `[]uint8{}`},
+		{nil, []byte(nil), `[]uint8(nil)`},
+		{nil, bytes.Repeat([]byte{0}, 70), `[]uint8(hex:00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000…(+6 more bytes))`},
+		{[]Option{MaxBytes(2)}, png, `[]uint8(hex:8950…(+6 more bytes))`},
+		{[]Option{MaxBytes(-1)}, bytes.Repeat([]byte{1}, 5), `[]uint8(hex:01010101 01)`},
+		{[]Option{Bytes(BytesHex)}, []byte("hi"), `[]uint8(hex:6869)`},
+		{[]Option{Bytes(BytesBase64)}, png, `[]uint8(base64:iVBORw0KGgo=)`},
+		{[]Option{Bytes(BytesList)}, []byte("hi"), `[]uint8{0x68, 0x69}`},
+		{nil, Blob{Name: []byte("a"), Data: png, Sum: [4]byte{1}}, `pretty.Blob{
+    Name: "a",
+    Data: hex:89504e47 0d0a1a0a,
+    Sum:  hex:01000000,
+}`},
+	}
+	for _, tt := range cases {
+		s := fmt.Sprint(NewFormatter(tt.v, tt.opts...))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package pretty
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI escape codes of the syntax highlighting, see Colors.
+const (
+	TypeColor   = "\033[36m" // type names, cyan
+	FieldColor  = "\033[34m" // struct field names, blue
+	StringColor = "\033[32m" // strings, green
+	NumberColor = "\033[35m" // numbers, booleans, times and bytes, magenta
+	ResetColor  = "\033[0m"  // ends each of the other colors
+)
+
+// ANSI escape codes of the differences, see DiffColors.
+const (
+	RemovedColor = "\033[31m" // values of a, red
+	AddedColor   = "\033[32m" // values of b, green
+	LabelColor   = "\033[2m"  // paths, dim
+)
+
+// Colors highlights type names, field names, strings and numbers with ANSI
+// colors, which makes large values scannable in a terminal.
+func Colors(on bool) Option {
+	return func(f *formatter) { f.colors = on }
+}
+
+// color starts writing in color c, if colors are enabled. Each color must be
+// ended by endColor.
+func (p *printer) color(c string) {
+	if p.colors {
+		io.WriteString(p, c)
+	}
+}
+
+func (p *printer) endColor() {
+	if p.colors {
+		io.WriteString(p, ResetColor)
+	}
+}
+
+// writeColored writes s in color c, if colors are enabled.
+func (p *printer) writeColored(c, s string) {
+	p.color(c)
+	io.WriteString(p, s)
+	p.endColor()
+}
+
+// writeType writes the name of t.
+func (p *printer) writeType(t reflect.Type) {
+	p.writeColored(TypeColor, t.String())
+}
+
+// padCell pads a colored cell of n visible characters to the minimum cell
+// width. The tabwriter counts the escape codes as part of the cell, which
+// therefore never gets the padding to the minimum width an uncolored cell
+// gets. The escape codes of the cells of a column are as long, so they don't
+// change the alignment otherwise.
+func (p *printer) padCell(n int) {
+	if !p.colors {
+		return
+	}
+	for ; n+1 < p.width(); n++ { // +1 for the tabwriter padding
+		writeByte(p, ' ')
+	}
+}
+
+// printKeyCell prints the key of an expanded map and its colon as a colored
+// cell, see padCell.
+func (p *printer) printKeyCell(k reflect.Value, showType bool) {
+	var b strings.Builder
+	kp := *p
+	kp.Writer = &b
+	kp.printValue(k, showType, true)
+	key := b.String()
+	io.WriteString(p, key)
+	writeByte(p, ':')
+	p.padCell(utf8.RuneCountInString(colorStripper.Replace(key)) + 1)
+}
+
+// colorStripper removes the escape codes of the syntax highlighting.
+var colorStripper = strings.NewReplacer(TypeColor, "", FieldColor, "", StringColor, "", NumberColor, "", ResetColor, "")
@@ -0,0 +1,48 @@
+package pretty
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestColors(t *testing.T) {
+	cases := []struct {
+		v interface{}
+		s string
+	}{
+		{1, "\033[36mint\033[0m(\033[35m1\033[0m)"},
+		{"a", "\033[32m\"a\"\033[0m"},
+		{T{1, 2}, "\033[36mpretty.T\033[0m{\033[34mx\033[0m:\033[35m1\033[0m, \033[34my\033[0m:\033[35m2\033[0m}"},
+		{[]bool{true}, "\033[36m[]bool\033[0m{\033[35mtrue\033[0m}"},
+	}
+	for _, tt := range cases {
+		s := fmt.Sprint(NewFormatter(tt.v, Colors(true)))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+		}
+	}
+}
+
+// TestColorsLayout verifies that colors don't change the layout, e.g. the
+// alignment of field values.
+func TestColorsLayout(t *testing.T) {
+	values := []interface{}{
+		SA{t: &T{1, 2}},
+		Event{At: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Took: time.Second},
+		map[string]interface{}{"a": 1, "long key": []byte{0xff}, "c": ID(1)},
+		Outer{Name: "o", Pub: &inner{secret: "s"}},
+		list(1, 2, 3),
+		map[int]string{1: "a", 2: "b"},
+		map[string]T{"k": {1, 2}},
+	}
+	for _, v := range values {
+		want := fmt.Sprint(NewFormatter(v, UnexportedFields(MarkUnexported)))
+		got := colorStripper.Replace(fmt.Sprint(NewFormatter(v, UnexportedFields(MarkUnexported), Colors(true))))
+		if got != want {
+			t.Errorf("expected %q", want)
+			t.Errorf("got      %q", got)
+		}
+	}
+}
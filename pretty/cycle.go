@@ -0,0 +1,110 @@
+package pretty
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pathElem is a step from a value to one of its elements: a struct field, a
+// map key or an array or slice index.
+type pathElem struct {
+	field string
+	key   reflect.Value // valid for map keys
+	index int
+}
+
+// cycles tracks the pointers, maps, slices and addressable structs being
+// printed, so that a value referring back to one of its
+// ancestors is printed as a marker instead of recursing forever.
+// It is shared by the printers of a single Format call.
+type cycles struct {
+	visited map[visit]int // ancestors, to the length of the path to them
+	path    []pathElem    // path from the printed value to the current one
+}
+
+func newCycles() *cycles {
+	return &cycles{visited: make(map[visit]int)}
+}
+
+// walk calls f with vis recorded as an ancestor of the values f encodes. If
+// vis already is an ancestor, walk calls cycle with the path to it instead.
+func (c *cycles) walk(vis visit, cycle func(path string), f func() error) error {
+	if n, ok := c.visited[vis]; ok {
+		cycle(formatPath(c.path[:n]))
+		return nil
+	}
+	c.visited[vis] = len(c.path)
+	defer delete(c.visited, vis)
+	return f()
+}
+
+// within calls f with e appended to the path of the current value.
+func (c *cycles) within(e pathElem, f func() error) error {
+	c.path = append(c.path, e)
+	defer func() { c.path = c.path[:len(c.path)-1] }()
+	return f()
+}
+
+// cycle writes <cycle to path>, or nil /* cycle to path */ in Go syntax, and
+// returns true if vis is an ancestor of the
+// current value.
+func (p *printer) cycle(vis visit) bool {
+	n, ok := p.cycles.visited[vis]
+	if !ok {
+		return false
+	}
+	if p.goSyntax {
+		io.WriteString(p, "nil /* cycle to ")
+		io.WriteString(p, formatPath(p.cycles.path[:n]))
+		io.WriteString(p, " */")
+		return true
+	}
+	io.WriteString(p, "<cycle to ")
+	io.WriteString(p, formatPath(p.cycles.path[:n]))
+	writeByte(p, '>')
+	return true
+}
+
+// enter records vis as an ancestor of the values printed until leave.
+func (p *printer) enter(vis visit) {
+	p.cycles.visited[vis] = len(p.cycles.path)
+}
+
+func (p *printer) leave(vis visit) {
+	delete(p.cycles.visited, vis)
+}
+
+// push appends e to the path of the printed values, until the matching pop.
+func (p *printer) push(e pathElem) {
+	p.cycles.path = append(p.cycles.path, e)
+}
+
+func (p *printer) pop() {
+	p.cycles.path = p.cycles.path[:len(p.cycles.path)-1]
+}
+
+// formatPath returns the path like an expression, e.g. Next.Prev or
+// ["a"][0]. The empty path is the printed value itself, root.
+func formatPath(path []pathElem) string {
+	if len(path) == 0 {
+		return "root"
+	}
+	var b strings.Builder
+	for _, e := range path {
+		switch {
+		case e.field != "":
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(e.field)
+		case e.key.IsValid():
+			fmt.Fprintf(&b, "[%v]", formatter{v: e.key, force: true, quote: true, style: style{compact: true}})
+		default:
+			b.WriteString("[" + strconv.Itoa(e.index) + "]")
+		}
+	}
+	return b.String()
+}
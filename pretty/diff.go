@@ -1,9 +1,18 @@
 package pretty
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
+	"os"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 type sbuf []string
@@ -14,9 +23,11 @@ func (p *sbuf) Printf(format string, a ...interface{}) {
 }
 
 // Diff returns a slice where each element describes
-// a difference between a and b.
-func Diff(a, b interface{}) (desc []string) {
-	Pdiff((*sbuf)(&desc), a, b)
+// a difference between a and b. Nil and empty slices and maps are equal,
+// e.g. []string(nil) and the []string{} it becomes through JSON. Struct
+// fields tagged with `pretty:"diff:-"` or `diff:"-"` are not compared.
+func Diff(a, b interface{}, opts ...DiffOpt) (desc []string) {
+	Pdiff((*sbuf)(&desc), a, b, opts...)
 	return desc
 }
 
@@ -29,8 +40,89 @@ func (p *wprintfer) Printf(format string, a ...interface{}) {
 }
 
 // Fdiff writes to w a description of the differences between a and b.
-func Fdiff(w io.Writer, a, b interface{}) {
-	Pdiff(&wprintfer{w}, a, b)
+func Fdiff(w io.Writer, a, b interface{}, opts ...DiffOpt) {
+	Pdiff(&wprintfer{w}, a, b, opts...)
+}
+
+// Cdiff is like Fdiff with DiffColors, unless $NO_COLOR is set, e.g. to
+// write to a terminal. The options may turn colors on or off regardless.
+func Cdiff(w io.Writer, a, b interface{}, opts ...DiffOpt) {
+	opts = append([]DiffOpt{DiffColors(os.Getenv("NO_COLOR") == "")}, opts...)
+	Fdiff(w, a, b, opts...)
+}
+
+// DiffKind is the kind of a Difference.
+type DiffKind int
+
+const (
+	// Modified is a value of a that differs from the one at the same path
+	// in b.
+	Modified DiffKind = iota
+	// Added is an element or map entry of b missing from a.
+	Added
+	// Removed is an element or map entry of a missing from b.
+	Removed
+	// Moved is an element of a slice of a at another index in b.
+	Moved
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case Modified:
+		return "Modified"
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Moved:
+		return "Moved"
+	}
+	return fmt.Sprintf("DiffKind(%d)", int(k))
+}
+
+// Difference is a difference between two values found by DiffReport.
+type Difference struct {
+	// Path is the label of the difference in Diff, e.g. Items[0].Price, and
+	// empty for the values themselves.
+	Path string
+	Kind DiffKind
+	// A and B are the values at Path, nil if missing. Values that can't be
+	// used outside of their package, in unexported fields, are their
+	// printed form instead.
+	A, B interface{}
+	// To is the label of the element of b that the element of a at Path
+	// moved to, e.g. Items[2], if Moved.
+	To string
+}
+
+// DiffReport returns the differences between a and b, like Diff, as values
+// to count, group or filter rather than as text.
+func DiffReport(a, b interface{}, opts ...DiffOpt) []Difference {
+	var report []Difference
+	d := diffPrinter{
+		labels:      new([]byte),
+		sameDiffers: new(bool),
+		aVisited:    make(map[visit]visit),
+		bVisited:    make(map[visit]visit),
+		report:      &report,
+	}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	d.found = new(int)
+	d.diff(reflect.ValueOf(a), reflect.ValueOf(b))
+	return report
+}
+
+// diffValue returns v as a value of a Difference.
+func diffValue(v reflect.Value) interface{} {
+	switch {
+	case !v.IsValid():
+		return nil
+	case v.CanInterface():
+		return v.Interface()
+	}
+	return fmt.Sprintf("%# v", formatter{v: v, quote: true, methods: DefaultMethods})
 }
 
 type Printfer interface {
@@ -38,14 +130,22 @@ type Printfer interface {
 }
 
 // Pdiff prints to p a description of the differences between a and b.
-// It calls Printf once for each difference, with no trailing newline.
+// It calls Printf once for each difference, with no trailing newline, as
+// soon as it is found, so that the differences of large values needn't be
+// held in memory like by Diff.
 // The standard library log.Logger is a Printfer.
-func Pdiff(p Printfer, a, b interface{}) {
+func Pdiff(p Printfer, a, b interface{}, opts ...DiffOpt) {
 	d := diffPrinter{
-		w:        p,
-		aVisited: make(map[visit]visit),
-		bVisited: make(map[visit]visit),
+		w:           p,
+		labels:      new([]byte),
+		sameDiffers: new(bool),
+		aVisited:    make(map[visit]visit),
+		bVisited:    make(map[visit]visit),
 	}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	d.found = new(int)
 	d.diff(reflect.ValueOf(a), reflect.ValueOf(b))
 }
 
@@ -64,8 +164,8 @@ func (p *logprintfer) Printf(format string, a ...interface{}) {
 // Ldiff prints to l a description of the differences between a and b.
 // It calls Logf once for each difference, with no trailing newline.
 // The standard library testing.T and testing.B are Logfers.
-func Ldiff(l Logfer, a, b interface{}) {
-	Pdiff(&logprintfer{l}, a, b)
+func Ldiff(l Logfer, a, b interface{}, opts ...DiffOpt) {
+	Pdiff(&logprintfer{l}, a, b, opts...)
 }
 
 type diffPrinter struct {
@@ -73,24 +173,201 @@ type diffPrinter struct {
 
 	aVisited map[visit]visit
 	bVisited map[visit]visit
-	l        string // label
+	// The label of the values is labels[:n], of a buffer shared along the
+	// walk: the labels of the values in them are written after it.
+	labels  *[]byte
+	n       int
+	path    []string // elements of the label with IgnorePaths, see splitPath
+	ignore  [][]string
+	epsilon float64 // see FloatTolerance
+
+	// include and exclude match the labels of FilterPath and ExcludePath.
+	include, exclude []*regexp.Regexp
+
+	// equal compares the values of the types of Comparer.
+	equal map[reflect.Type]func(a, b reflect.Value) bool
+	// transforms rewrite the values of the types of Transformer.
+	transforms  map[reflect.Type]transform
+	transformed bool // the values are those of a transform, not to redo
+
+	// less orders the elements of the types of SortSlices.
+	less        map[reflect.Type]func(a, b reflect.Value) bool
+	unordered   bool // see UnorderedSlices
+	memberKeys  bool // label string keys as members, like DiffPatch
+	rawEqual    bool // don't call Equal methods, see EqualMethods
+	strictKinds bool // see StrictKinds
+	jsonStrings bool // see JSONStrings
+
+	report  *[]Difference // collects the differences instead of w, see DiffReport
+	differs *bool         // set by the first difference instead of printing it, see Equal
+	// sameDiffers is the differs of same, shared along the walk.
+	sameDiffers *bool
+	colors      bool // see DiffColors
+
+	maxDiffs int  // see MaxDiffs
+	found    *int // differences reported
+}
+
+// modified reports that av and bv differ, printed as the strings returned by
+// texts. They are only made if printed, see Equal.
+func (d diffPrinter) modified(av, bv reflect.Value, texts func() (a, b string)) {
+	switch {
+	case !d.included():
+	case d.differs != nil:
+		*d.differs = true
+	case !d.count():
+	case d.report != nil:
+		*d.report = append(*d.report, Difference{Path: d.label(), Kind: Modified, A: diffValue(av), B: diffValue(bv)})
+	default:
+		d.print(texts())
+	}
+}
+
+// count counts a difference, and reports whether it is within MaxDiffs. The
+// first one beyond is printed as a summary.
+func (d diffPrinter) count() bool {
+	if d.found == nil {
+		return true
+	}
+	*d.found++
+	if d.maxDiffs <= 0 || *d.found <= d.maxDiffs {
+		return true
+	}
+	if *d.found == d.maxDiffs+1 && d.report == nil {
+		d.w.Printf("%s", "… and more differences")
+	}
+	return false
+}
+
+// full reports whether MaxDiffs differences were found, and more, which
+// ends the comparison.
+func (d diffPrinter) full() bool {
+	return d.maxDiffs > 0 && d.found != nil && *d.found > d.maxDiffs
 }
 
-func (d diffPrinter) printf(f string, a ...interface{}) {
-	var l string
-	if d.l != "" {
-		l = d.l + ": "
+// modifiedLines reports that the strings av and bv differ by the lines of
+// the unified diff returned by lines.
+func (d diffPrinter) modifiedLines(av, bv reflect.Value, lines func() string) {
+	if !d.included() {
+		return
+	}
+	if d.differs != nil || d.report != nil {
+		d.modified(av, bv, nil)
+		return
+	}
+	if !d.count() {
+		return
+	}
+	u := lines()
+	if d.colors {
+		lines := strings.Split(u, "\n")
+		for i, line := range lines {
+			switch line[0] {
+			case '-':
+				lines[i] = RemovedColor + line + ResetColor
+			case '+':
+				lines[i] = AddedColor + line + ResetColor
+			}
+		}
+		u = strings.Join(lines, "\n")
+	}
+	d.printf(u)
+}
+
+// moved reports that the element i of a slice of a, av, is the element j of
+// b.
+func (d diffPrinter) moved(av reflect.Value, i, j int) {
+	if !d.included() {
+		return
+	}
+	if d.differs != nil {
+		*d.differs = true
+		return
+	}
+	if !d.count() {
+		return
+	}
+	if d.report != nil {
+		v := diffValue(av)
+		*d.report = append(*d.report, Difference{Path: d.relabelIndex(i).label(), Kind: Moved, A: v, B: v, To: d.relabelIndex(j).label()})
+		return
+	}
+	d.printf(fmt.Sprintf("moved [%d]→[%d]", i, j))
+}
+
+// removed reports that av is missing from b.
+func (d diffPrinter) removed(av reflect.Value) {
+	if !d.included() {
+		return
+	}
+	if d.differs != nil {
+		*d.differs = true
+		return
+	}
+	if !d.count() {
+		return
+	}
+	if d.report != nil {
+		*d.report = append(*d.report, Difference{Path: d.label(), Kind: Removed, A: diffValue(av)})
+		return
+	}
+	d.print(formatValue(av), "(missing)")
+}
+
+// added reports that bv is missing from a.
+func (d diffPrinter) added(bv reflect.Value) {
+	if !d.included() {
+		return
+	}
+	if d.differs != nil {
+		*d.differs = true
+		return
+	}
+	if !d.count() {
+		return
+	}
+	if d.report != nil {
+		*d.report = append(*d.report, Difference{Path: d.label(), Kind: Added, B: diffValue(bv)})
+		return
 	}
-	d.w.Printf(l+f, a...)
+	d.print("(missing)", formatValue(bv))
+}
+
+// print prints a difference between a and b, in red and green with colors.
+func (d diffPrinter) print(a, b string) {
+	if d.colors {
+		a, b = RemovedColor+a+ResetColor, AddedColor+b+ResetColor
+	}
+	d.printf(a + " != " + b)
+}
+
+// printf prints the difference s after the label of d, dimmed with colors.
+func (d diffPrinter) printf(s string) {
+	switch {
+	case d.n == 0:
+	case d.colors:
+		s = LabelColor + d.label() + ":" + ResetColor + " " + s
+	default:
+		s = d.label() + ": " + s
+	}
+	d.w.Printf("%s", s)
+}
+
+// formatValue returns v printed for a difference.
+func formatValue(v reflect.Value) string {
+	return fmt.Sprintf("%# v", formatter{v: v, quote: true, methods: DefaultMethods})
 }
 
 func (d diffPrinter) diff(av, bv reflect.Value) {
+	if d.differs != nil && *d.differs || d.full() || d.ignored() {
+		return
+	}
 	if !av.IsValid() && bv.IsValid() {
-		d.printf("nil != %# v", formatter{v: bv, quote: true})
+		d.modified(av, bv, func() (string, string) { return "nil", formatValue(bv) })
 		return
 	}
 	if av.IsValid() && !bv.IsValid() {
-		d.printf("%# v != nil", formatter{v: av, quote: true})
+		d.modified(av, bv, func() (string, string) { return formatValue(av), "nil" })
 		return
 	}
 	if !av.IsValid() && !bv.IsValid() {
@@ -100,11 +377,31 @@ func (d diffPrinter) diff(av, bv reflect.Value) {
 	at := av.Type()
 	bt := bv.Type()
 	if at != bt {
-		d.printf("%v != %v", at, bt)
+		d.modified(av, bv, func() (string, string) { return typeMismatch(av, bv) })
+		return
+	}
+
+	if t, ok := d.transforms[at]; ok && !d.transformed && av.CanInterface() && bv.CanInterface() {
+		d1 := d.relabel(t.name + "()")
+		d1.transformed = true
+		d1.diff(t.f(av), t.f(bv))
+		return
+	}
+	d.transformed = false // for the values in av and bv
+
+	if equal, ok := d.equal[at]; ok && av.CanInterface() && bv.CanInterface() {
+		if !equal(av, bv) {
+			d.modified(av, bv, func() (string, string) { return formatValue(av), formatValue(bv) })
+		}
+		return
+	}
+	if !d.rawEqual && equalMethod(av, bv) {
 		return
 	}
 
-	if av.CanAddr() && bv.CanAddr() {
+	// Only values holding references can be met again, so the others aren't
+	// recorded, which would take memory for every element and field.
+	if av.CanAddr() && bv.CanAddr() && mayCycle(at) {
 		avis := visit{v: av.UnsafeAddr(), typ: at}
 		bvis := visit{v: bv.UnsafeAddr(), typ: bt}
 		var cycle bool
@@ -113,11 +410,11 @@ func (d diffPrinter) diff(av, bv reflect.Value) {
 		if vis, ok := d.aVisited[avis]; ok {
 			cycle = true
 			if vis != bvis {
-				d.printf("%# v (previously visited) != %# v", formatter{v: av, quote: true}, formatter{v: bv, quote: true})
+				d.modified(av, bv, func() (string, string) { return formatValue(av) + " (previously visited)", formatValue(bv) })
 			}
 		} else if _, ok := d.bVisited[bvis]; ok {
 			cycle = true
-			d.printf("%# v != %# v (previously visited)", formatter{v: av, quote: true}, formatter{v: bv, quote: true})
+			d.modified(av, bv, func() (string, string) { return formatValue(av), formatValue(bv) + " (previously visited)" })
 		}
 		d.aVisited[avis] = bvis
 		d.bVisited[bvis] = avis
@@ -126,96 +423,771 @@ func (d diffPrinter) diff(av, bv reflect.Value) {
 		}
 	}
 
+	// Both must be formatted, e.g. not a nil *time.Location.
+	if a, ok := formatTime(av); ok {
+		if b, ok := formatTime(bv); ok {
+			if a != b {
+				d.modified(av, bv, func() (string, string) { return a, b })
+			}
+			return
+		}
+	}
+	if a, ok := formatBig(av); ok && at.Kind() == reflect.Struct { // pointers are followed below
+		if b, _ := formatBig(bv); a != b {
+			d.modified(av, bv, func() (string, string) { return a, b })
+		}
+		return
+	}
+	if d.jsonStrings {
+		if a, ok := jsonDocumentOf(av); ok {
+			if b, ok := jsonDocumentOf(bv); ok {
+				d.memberKeys = true
+				d.diff(reflect.ValueOf(a), reflect.ValueOf(b))
+				return
+			}
+		}
+	}
+
 	switch kind := at.Kind(); kind {
 	case reflect.Bool:
 		if a, b := av.Bool(), bv.Bool(); a != b {
-			d.printf("%v != %v", a, b)
+			d.modified(av, bv, func() (string, string) { return fmt.Sprint(a), fmt.Sprint(b) })
 		}
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		if a, b := av.Int(), bv.Int(); a != b {
-			d.printf("%d != %d", a, b)
+			d.modified(av, bv, func() (string, string) { return fmt.Sprint(a), fmt.Sprint(b) })
 		}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		if a, b := av.Uint(), bv.Uint(); a != b {
-			d.printf("%d != %d", a, b)
+			d.modified(av, bv, func() (string, string) { return fmt.Sprint(a), fmt.Sprint(b) })
 		}
 	case reflect.Float32, reflect.Float64:
-		if a, b := av.Float(), bv.Float(); a != b {
-			d.printf("%v != %v", a, b)
+		if a, b := av.Float(), bv.Float(); !d.floatEqual(a, b) {
+			d.modified(av, bv, func() (string, string) { return fmt.Sprint(a), fmt.Sprint(b) })
 		}
 	case reflect.Complex64, reflect.Complex128:
-		if a, b := av.Complex(), bv.Complex(); a != b {
-			d.printf("%v != %v", a, b)
+		if a, b := av.Complex(), bv.Complex(); !d.floatEqual(real(a), real(b)) || !d.floatEqual(imag(a), imag(b)) {
+			d.modified(av, bv, func() (string, string) { return fmt.Sprint(a), fmt.Sprint(b) })
 		}
 	case reflect.Array:
 		n := av.Len()
 		for i := 0; i < n; i++ {
-			d.relabel(fmt.Sprintf("[%d]", i)).diff(av.Index(i), bv.Index(i))
+			d.relabelIndex(i).diff(av.Index(i), bv.Index(i))
 		}
 	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
 		if a, b := av.Pointer(), bv.Pointer(); a != b {
-			d.printf("%#x != %#x", a, b)
+			d.modified(av, bv, func() (string, string) { return fmt.Sprintf("%#x", a), fmt.Sprintf("%#x", b) })
 		}
 	case reflect.Interface:
 		d.diff(av.Elem(), bv.Elem())
 	case reflect.Map:
-		ak, both, bk := keyDiff(av.MapKeys(), bv.MapKeys())
+		ak, both, bk := keyDiff(av.MapKeys(), bv.MapKeys(), d.strictKinds)
+		sortKeys(ak, nil)
+		sortKeys(both, nil)
+		sortKeys(bk, nil)
 		for _, k := range ak {
-			w := d.relabel(fmt.Sprintf("[%#v]", k))
-			w.printf("%q != (missing)", av.MapIndex(k))
+			w := d.relabelKey(k)
+			w.removed(av.MapIndex(k))
 		}
 		for _, k := range both {
-			w := d.relabel(fmt.Sprintf("[%#v]", k))
+			w := d.relabelKey(k)
 			w.diff(av.MapIndex(k), bv.MapIndex(k))
 		}
 		for _, k := range bk {
-			w := d.relabel(fmt.Sprintf("[%#v]", k))
-			w.printf("(missing) != %q", bv.MapIndex(k))
+			w := d.relabelKey(k)
+			w.added(bv.MapIndex(k))
 		}
 	case reflect.Ptr:
 		switch {
 		case av.IsNil() && !bv.IsNil():
-			d.printf("nil != %# v", formatter{v: bv, quote: true})
+			d.modified(av, bv, func() (string, string) { return "nil", formatValue(bv) })
 		case !av.IsNil() && bv.IsNil():
-			d.printf("%# v != nil", formatter{v: av, quote: true})
+			d.modified(av, bv, func() (string, string) { return formatValue(av), "nil" })
 		case !av.IsNil() && !bv.IsNil():
 			d.diff(av.Elem(), bv.Elem())
 		}
 	case reflect.Slice:
-		lenA := av.Len()
-		lenB := bv.Len()
-		if lenA != lenB {
-			d.printf("%s[%d] != %s[%d]", av.Type(), lenA, bv.Type(), lenB)
-			break
-		}
-		for i := 0; i < lenA; i++ {
-			d.relabel(fmt.Sprintf("[%d]", i)).diff(av.Index(i), bv.Index(i))
-		}
+		d.diffSlices(av, bv)
 	case reflect.String:
-		if a, b := av.String(), bv.String(); a != b {
-			d.printf("%q != %q", a, b)
+		a, b := av.String(), bv.String()
+		switch {
+		case a == b:
+		case strings.Contains(a, "\n") || strings.Contains(b, "\n"):
+			// The lines that differ, rather than two long quoted strings,
+			// unless too many of them differ to align them.
+			al, bl := strings.Split(a, "\n"), strings.Split(b, "\n")
+			if lineEditsFit(al, bl) {
+				d.modifiedLines(av, bv, func() string { return unifiedDiff(al, bl) })
+				break
+			}
+			fallthrough
+		default:
+			d.modified(av, bv, func() (string, string) { return strconv.Quote(a), strconv.Quote(b) })
 		}
 	case reflect.Struct:
 		for i := 0; i < av.NumField(); i++ {
-			d.relabel(at.Field(i).Name).diff(av.Field(i), bv.Field(i))
+			if f := at.Field(i); !diffIgnored(f) {
+				d.relabel(f.Name).diff(av.Field(i), bv.Field(i))
+			}
 		}
 	default:
-		panic("unknown reflect Kind: " + kind.String())
+		d.unsupported(av, bv)
 	}
 }
 
-func (d diffPrinter) relabel(name string) (d1 diffPrinter) {
-	d1 = d
-	if d.l != "" && name[0] != '[' {
-		d1.l += "."
+// unsupported reports that av and bv are of a kind unknown to diff, e.g. of
+// a later version of Go, unless they are deeply equal. It panics instead with
+// StrictKinds.
+func (d diffPrinter) unsupported(av, bv reflect.Value) {
+	if d.strictKinds {
+		panic("unknown reflect Kind: " + av.Kind().String())
 	}
-	d1.l += name
+	if av.CanInterface() && bv.CanInterface() && reflect.DeepEqual(av.Interface(), bv.Interface()) {
+		return
+	}
+	switch {
+	case !d.included():
+	case d.differs != nil || d.report != nil:
+		d.modified(av, bv, nil)
+	case d.count():
+		d.printf("unsupported kind " + av.Kind().String())
+	}
+}
+
+// diffSlices aligns the elements of the slices a and b, so that an element
+// inserted or removed is reported as such, rather than as a difference of
+// each element after it. Elements that take the place of others are
+// compared with them.
+func (d diffPrinter) diffSlices(av, bv reflect.Value) {
+	if d.unordered {
+		d.diffUnordered(av, bv)
+		return
+	}
+	if less, ok := d.less[av.Type().Elem()]; ok {
+		av, bv = sortedCopy(av, less), sortedCopy(bv, less)
+	} else if d.differs != nil && !d.filtered() {
+		// Equal needs no alignment to tell whether the slices differ.
+		if av.Len() != bv.Len() {
+			d.modified(av, bv, nil)
+			return
+		}
+		for i := 0; i < av.Len() && !*d.differs; i++ {
+			d.diff(av.Index(i), bv.Index(i))
+		}
+		return
+	}
+	n, m := av.Len(), bv.Len()
+	// The elements common to both ends are equal, which saves comparisons.
+	lo := 0
+	for lo < n && lo < m && d.relabelIndex(lo).same(av.Index(lo), bv.Index(lo)) {
+		lo++
+	}
+	hi := 0
+	for hi < n-lo && hi < m-lo && d.relabelIndex(n-1-hi).same(av.Index(n-1-hi), bv.Index(m-1-hi)) {
+		hi++
+	}
+	a, b := av.Slice(lo, n-hi), bv.Slice(lo, m-hi)
+
+	var edits []edit
+	var movedTo map[int]int // of the removed elements, the inserted ones they are
+	if a.Len()*b.Len() <= maxEditCells {
+		edits = editScript(a.Len(), b.Len(), func(i, j int) bool { return d.relabelIndex(lo+i).same(a.Index(i), b.Index(j)) })
+		movedTo = d.moves(a, b, lo, edits)
+	} else {
+		// Too many to align: element by element, as they come.
+		for i := lo; i < n-hi || i < m-hi; i++ {
+			switch {
+			case i >= m-hi:
+				d.relabelIndex(i).removed(av.Index(i))
+			case i >= n-hi:
+				d.relabelIndex(i).added(bv.Index(i))
+			default:
+				d.relabelIndex(i).diff(av.Index(i), bv.Index(i))
+			}
+		}
+		return
+	}
+
+	// The removals and insertions next to each other are changes of as many
+	// elements as there are of both.
+	var removed, inserted []int
+	flush := func() {
+		for k := 0; k < len(removed) || k < len(inserted); k++ {
+			switch {
+			case k >= len(inserted):
+				i := lo + removed[k]
+				d.relabelIndex(i).removed(av.Index(i))
+			case k >= len(removed):
+				j := lo + inserted[k]
+				d.relabelIndex(j).added(bv.Index(j))
+			default:
+				i, j := lo+removed[k], lo+inserted[k]
+				d.relabelIndex(i).diff(av.Index(i), bv.Index(j))
+			}
+		}
+		removed, inserted = removed[:0], inserted[:0]
+	}
+	var movedFrom map[int]bool
+	for _, j := range movedTo {
+		if movedFrom == nil {
+			movedFrom = make(map[int]bool, len(movedTo))
+		}
+		movedFrom[j] = true
+	}
+	for _, e := range edits {
+		switch {
+		case e.op == '-':
+			if j, ok := movedTo[e.a]; ok {
+				d.moved(av.Index(lo+e.a), lo+e.a, lo+j)
+			} else {
+				removed = append(removed, e.a)
+			}
+		case e.op == '+':
+			if !movedFrom[e.b] {
+				inserted = append(inserted, e.b)
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+}
+
+// moves matches the elements of a removed by the edits with equal elements
+// inserted elsewhere, which were moved there rather than replaced, e.g. by a
+// reordering. The elements of a and b are at lo in the slices being diffed.
+func (d diffPrinter) moves(a, b reflect.Value, lo int, edits []edit) map[int]int {
+	var removed, inserted []int
+	for _, e := range edits {
+		switch e.op {
+		case '-':
+			removed = append(removed, e.a)
+		case '+':
+			inserted = append(inserted, e.b)
+		}
+	}
+	var movedTo map[int]int
+	for _, i := range removed {
+		for k, j := range inserted {
+			if j >= 0 && d.relabelIndex(lo+i).same(a.Index(i), b.Index(j)) {
+				if movedTo == nil {
+					movedTo = make(map[int]int)
+				}
+				movedTo[i] = j
+				inserted[k] = -1 // matched
+				break
+			}
+		}
+	}
+	return movedTo
+}
+
+// diffUnordered compares the slices a and b as multisets: each element of a
+// is matched with an equal element of b, if any, and the elements left over
+// are reported.
+func (d diffPrinter) diffUnordered(av, bv reflect.Value) {
+	matched := make([]bool, bv.Len())
+	for i := 0; i < av.Len(); i++ {
+		j := 0
+		for ; j < bv.Len(); j++ {
+			if !matched[j] && d.relabelIndex(i).same(av.Index(i), bv.Index(j)) {
+				matched[j] = true
+				break
+			}
+		}
+		if j == bv.Len() {
+			d.relabelIndex(i).removed(av.Index(i))
+		}
+	}
+	for j, ok := range matched {
+		if !ok {
+			d.relabelIndex(j).added(bv.Index(j))
+		}
+	}
+}
+
+// sortedCopy returns a copy of the slice v, sorted by less.
+func sortedCopy(v reflect.Value, less func(a, b reflect.Value) bool) reflect.Value {
+	c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	reflect.Copy(c, v)
+	sort.SliceStable(c.Interface(), func(i, j int) bool { return less(c.Index(i), c.Index(j)) })
+	return c
+}
+
+// maxEditCells bounds the product of the lengths of the slices aligned by
+// diffSlices, and of the lines of strings aligned by lineEdits, which takes
+// as much memory and comparisons.
+const maxEditCells = 1 << 16
+
+// mayCycle reports whether values of type t may be met again while diffing
+// them, because they hold pointers, slices, maps or interfaces, directly or
+// in their fields and elements.
+func mayCycle(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	case reflect.Array:
+		return t.Len() > 0 && mayCycle(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if mayCycle(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// same reports whether Diff finds no difference between a and b at the
+// label of d.
+func (d diffPrinter) same(av, bv reflect.Value) bool {
+	if d.sameDiffers == nil {
+		d.sameDiffers = new(bool)
+	}
+	// Calls of same in this one find the flag unset, and leave it so.
+	defer func(differs bool) { *d.sameDiffers = differs }(*d.sameDiffers)
+	*d.sameDiffers = false
+	d.differs, d.report, d.found = d.sameDiffers, nil, nil
+	d.aVisited, d.bVisited = nil, nil
+	if av.IsValid() && mayCycle(av.Type()) {
+		d.aVisited = make(map[visit]visit)
+		d.bVisited = make(map[visit]visit)
+	}
+	d.diff(av, bv)
+	return !*d.sameDiffers
+}
+
+// relabelIndex is relabel for the element i of a slice or array.
+func (d diffPrinter) relabelIndex(i int) diffPrinter {
+	if !d.labeled() {
+		return d
+	}
+	if len(d.ignore) > 0 {
+		return d.relabel("[" + strconv.Itoa(i) + "]")
+	}
+	// Without the allocations of relabel, for each element.
+	d1 := d.child()
+	b := append(*d1.labels, '[')
+	b = strconv.AppendInt(b, int64(i), 10)
+	*d1.labels = append(b, ']')
+	d1.n = len(*d1.labels)
 	return d1
 }
 
+// relabelKey is relabel for the entry k of a map. An empty member is labeled
+// like other keys, e.g. a[""], since a. would read like a typo.
+func (d diffPrinter) relabelKey(k reflect.Value) diffPrinter {
+	if !d.labeled() {
+		return d
+	}
+	if d.memberKeys && k.Kind() == reflect.String && k.Len() > 0 {
+		return d.relabel(k.String())
+	}
+	return d.relabel(fmt.Sprintf("[%#v]", k))
+}
+
+// labeled reports whether the differences need their labels, which Equal
+// doesn't unless their paths are filtered.
+func (d diffPrinter) labeled() bool {
+	return d.differs == nil || d.filtered()
+}
+
+// filtered reports whether some paths are ignored or filtered.
+func (d diffPrinter) filtered() bool {
+	return len(d.ignore) > 0 || len(d.include) > 0 || len(d.exclude) > 0
+}
+
+// relabel returns d for the value named name in those of d, e.g. a field.
+func (d diffPrinter) relabel(name string) diffPrinter {
+	if !d.labeled() {
+		return d
+	}
+	d1 := d.child()
+	if d.n > 0 && !strings.HasPrefix(name, "[") {
+		*d1.labels = append(*d1.labels, '.')
+	}
+	*d1.labels = append(*d1.labels, name...)
+	d1.n = len(*d1.labels)
+	if len(d.ignore) > 0 {
+		d1.path = append(d.path[:len(d.path):len(d.path)], name) // a copy for each child
+	}
+	return d1
+}
+
+// child returns d with the labels after its own cut, to write those of a
+// value in them.
+func (d diffPrinter) child() diffPrinter {
+	if d.labels == nil {
+		d.labels = new([]byte)
+	}
+	*d.labels = (*d.labels)[:d.n]
+	return d
+}
+
+// label returns the label of the values of d, e.g. Items[0].Price.
+func (d diffPrinter) label() string {
+	return string(d.labelBytes())
+}
+
+func (d diffPrinter) labelBytes() []byte {
+	if d.labels == nil {
+		return nil
+	}
+	return (*d.labels)[:d.n]
+}
+
+// Equal reports whether Diff finds no difference between a and b, with the
+// same options. Unlike Diff, it stops at the first difference, and it
+// doesn't print the values, so that it is cheap enough for hot paths or to
+// check values before describing their differences.
+func Equal(a, b interface{}, opts ...DiffOpt) bool {
+	var differs bool
+	d := diffPrinter{
+		labels:      new([]byte),
+		sameDiffers: new(bool),
+		aVisited:    make(map[visit]visit),
+		bVisited:    make(map[visit]visit),
+		differs:     &differs,
+	}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	d.diff(reflect.ValueOf(a), reflect.ValueOf(b))
+	return !differs
+}
+
+// DiffOpt configures Diff, Fdiff, Pdiff and Ldiff.
+type DiffOpt func(d *diffPrinter)
+
+// IgnorePaths leaves the values at the given paths out of the comparison,
+// e.g. timestamps and generated IDs. Paths are written like the labels of
+// the differences, e.g. Meta.UpdatedAt, Items[0].Price or Env["HOME"]. In
+// a path, [*] matches any index or map key, and * any number of field names,
+// indexes and keys, e.g. *.ID matches the ID fields at any depth:
+//
+//	pretty.Diff(got, want, pretty.IgnorePaths("Meta.UpdatedAt", "*.ID"))
+func IgnorePaths(paths ...string) DiffOpt {
+	return func(d *diffPrinter) {
+		for _, p := range paths {
+			d.ignore = append(d.ignore, splitPath(p))
+		}
+	}
+}
+
+// FilterPath makes Diff report only the differences whose labels, like
+// Items[0].Price, match one of the regular expressions of FilterPath, e.g.
+// only the prices of the items with
+//
+//	pretty.FilterPath(regexp.MustCompile(`^Items\[\d+\]\.Price$`))
+//
+// or only the items with ^Items\b. Values are still compared whole, so a
+// slice element missing from the other slice is reported under its own
+// label, e.g. Items[3], and not under the labels of its fields.
+func FilterPath(re *regexp.Regexp) DiffOpt {
+	return func(d *diffPrinter) { d.include = append(d.include, re) }
+}
+
+// ExcludePath makes Diff skip the values whose labels match re, and
+// everything in them, like IgnorePaths does with patterns, e.g. the
+// timestamps of all the nested structs with
+//
+//	pretty.ExcludePath(regexp.MustCompile(`(^|\.)(CreatedAt|UpdatedAt)$`))
+func ExcludePath(re *regexp.Regexp) DiffOpt {
+	return func(d *diffPrinter) { d.exclude = append(d.exclude, re) }
+}
+
+// JSONStrings makes Diff compare the strings and byte slices, like
+// json.RawMessage, that both hold a JSON object or array by their decoded
+// values, so that the order of the members and the spaces between them don't
+// matter. Their differences are labeled with the names of the members, e.g.
+// Payload.items[0].price.
+func JSONStrings(on bool) DiffOpt {
+	return func(d *diffPrinter) { d.jsonStrings = on }
+}
+
+// StrictKinds makes Diff panic on values of a kind it doesn't know, e.g. one
+// added to package reflect by a later version of Go, as it used to. By
+// default, they are compared with reflect.DeepEqual, and reported like
+// "Field: unsupported kind X" if they differ.
+func StrictKinds(on bool) DiffOpt {
+	return func(d *diffPrinter) { d.strictKinds = on }
+}
+
+// transform is a function of Transformer.
+type transform struct {
+	name string
+	f    func(v reflect.Value) reflect.Value
+}
+
+// Transformer makes Diff compare the values of T rewritten by f, e.g.
+// strings in lower case or times rounded to seconds:
+//
+//	pretty.Diff(got, want, pretty.Transformer("round", func(t time.Time) time.Time { return t.Round(time.Second) }))
+//
+// Their differences are labeled with the name of the transformer, like
+// Seen.round(). The values returned by f are not rewritten again if U is T,
+// but the values they hold are. Like with Comparer, values of T in
+// unexported struct fields are compared as they are.
+func Transformer[T, U any](name string, f func(T) U) DiffOpt {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return func(d *diffPrinter) {
+		if d.transforms == nil {
+			d.transforms = make(map[reflect.Type]transform)
+		}
+		d.transforms[t] = transform{name, func(v reflect.Value) reflect.Value {
+			x, _ := v.Interface().(T) // the zero T for nil interfaces
+			return reflect.ValueOf(f(x))
+		}}
+	}
+}
+
+// FloatTolerance makes Diff consider floats equal if they differ by at most
+// eps, or by at most eps times the larger of their magnitudes, so that e.g.
+// 0.1+0.2 and 0.3 are equal with a tolerance of 1e-9. The parts of complex
+// numbers are compared the same way.
+func FloatTolerance(eps float64) DiffOpt {
+	return func(d *diffPrinter) { d.epsilon = eps }
+}
+
+// Comparer makes Diff compare values of T with equal instead of field by
+// field, e.g. decimals by their value or IDs by their canonical form:
+//
+//	pretty.Diff(got, want, pretty.Comparer(func(a, b decimal.Decimal) bool { return a.Equal(b) }))
+//
+// Values that differ are printed whole. Values of T in unexported struct
+// fields are compared as usual, because equal can't be called with them.
+func Comparer[T any](equal func(a, b T) bool) DiffOpt {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return func(d *diffPrinter) {
+		if d.equal == nil {
+			d.equal = make(map[reflect.Type]func(a, b reflect.Value) bool)
+		}
+		d.equal[t] = func(a, b reflect.Value) bool {
+			// The zero T for nil interfaces.
+			x, _ := a.Interface().(T)
+			y, _ := b.Interface().(T)
+			return equal(x, y)
+		}
+	}
+}
+
+// DiffColors prints the values of a in red and those of b in green, and the
+// paths dimmed, which makes large reports scannable in a terminal.
+func DiffColors(on bool) DiffOpt {
+	return func(d *diffPrinter) { d.colors = on }
+}
+
+// MaxDiffs stops the comparison after n differences, of which there may be
+// tens of thousands between two large values. If there are more, Diff,
+// Fdiff, Pdiff and Ldiff end with "… and more differences".
+func MaxDiffs(n int) DiffOpt {
+	return func(d *diffPrinter) { d.maxDiffs = n }
+}
+
+// EqualMethods selects whether Diff considers values equal if their Equal
+// method says so, which it does by default. Values with a method like
+// time.Time.Equal or net.IP.Equal, of their type and returning a bool, are
+// then compared by their meaning: times in different locations or with
+// different monotonic clock readings, or IPv4 addresses in their 4 and 16
+// bytes forms, don't differ. Values that aren't equal are compared as
+// usual, to describe their differences.
+func EqualMethods(on bool) DiffOpt {
+	return func(d *diffPrinter) { d.rawEqual = !on }
+}
+
+// equalMethod reports whether av and bv, of the same type, are equal by the
+// Equal method of their type, and false if they have none.
+func equalMethod(av, bv reflect.Value) bool {
+	t := av.Type()
+	m, ok := t.MethodByName("Equal")
+	if !ok || m.Type.NumIn() != 2 || m.Type.In(1) != t ||
+		m.Type.NumOut() != 1 || m.Type.Out(0).Kind() != reflect.Bool ||
+		!av.CanInterface() || !bv.CanInterface() {
+		return false
+	}
+	if t.Kind() == reflect.Ptr && (av.IsNil() || bv.IsNil()) {
+		return false // which the method may not expect
+	}
+	equal := false
+	func() {
+		defer func() { recover() }()
+		equal = av.Method(m.Index).Call([]reflect.Value{bv})[0].Bool()
+	}()
+	return equal
+}
+
+// UnorderedSlices makes Diff compare slices regardless of the order of their
+// elements, e.g. the rows of a query without an ORDER BY, or slices used as
+// sets. Only the elements of either slice that have no equal in the other
+// are reported, by their index.
+func UnorderedSlices() DiffOpt {
+	return func(d *diffPrinter) { d.unordered = true }
+}
+
+// SortSlices makes Diff sort slices of T by less before comparing them, so
+// that their order doesn't matter. Unlike with UnorderedSlices, elements
+// that take the place of others in the sorted slices are compared with them,
+// and the indexes of the differences are those of the sorted slices.
+func SortSlices[T any](less func(a, b T) bool) DiffOpt {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return func(d *diffPrinter) {
+		if d.less == nil {
+			d.less = make(map[reflect.Type]func(a, b reflect.Value) bool)
+		}
+		d.less[t] = func(a, b reflect.Value) bool {
+			x, _ := a.Interface().(T)
+			y, _ := b.Interface().(T)
+			return less(x, y)
+		}
+	}
+}
+
+// floatEqual reports whether a and b are equal within the tolerance of d.
+func (d diffPrinter) floatEqual(a, b float64) bool {
+	if a == b {
+		return true
+	}
+	delta := math.Abs(a - b)
+	return delta <= d.epsilon || delta <= d.epsilon*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// jsonDocumentOf returns the JSON object or array held by the string or byte
+// slice v, like a json.RawMessage, decoded into maps, slices and scalars.
+func jsonDocumentOf(v reflect.Value) (interface{}, bool) {
+	var data []byte
+	switch {
+	case v.Kind() == reflect.String:
+		data = []byte(v.String())
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		data = v.Bytes()
+	default:
+		return nil, false
+	}
+	if t := bytes.TrimSpace(data); len(t) == 0 || t[0] != '{' && t[0] != '[' {
+		return nil, false
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false
+	}
+	return doc, true
+}
+
+// typeMismatch returns the texts of the values av and bv of different types,
+// e.g. of interfaces: both values, followed by their types and whether they
+// are numerically equal, like float64(1) and int(1) decoded from JSON and
+// set by a struct.
+func typeMismatch(av, bv reflect.Value) (a, b string) {
+	note := av.Type().String() + " != " + bv.Type().String()
+	if x, ok := numericValue(av); ok {
+		if y, ok := numericValue(bv); ok && x.Cmp(y) == 0 {
+			note += ", numerically equal"
+		}
+	}
+	return formatValue(av), formatValue(bv) + " (" + note + ")"
+}
+
+// numericValue returns the exact value of an integer or a float, and false
+// for other values and NaNs.
+func numericValue(v reflect.Value) (*big.Float, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Float).SetInt64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Float).SetUint64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); !math.IsNaN(f) {
+			return new(big.Float).SetFloat64(f), true
+		}
+	}
+	return nil, false
+}
+
+// ignored reports whether the values at the path of d are ignored.
+func (d diffPrinter) ignored() bool {
+	for _, pattern := range d.ignore {
+		if matchPath(pattern, d.path) {
+			return true
+		}
+	}
+	for _, re := range d.exclude {
+		if re.Match(d.labelBytes()) {
+			return true
+		}
+	}
+	return false
+}
+
+// included reports whether a difference at the path of d passes FilterPath.
+func (d diffPrinter) included() bool {
+	if len(d.include) == 0 {
+		return true
+	}
+	for _, re := range d.include {
+		if re.Match(d.labelBytes()) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPath splits a path into its field names, indexes and map keys, e.g.
+// Items[0].Price into Items, [0] and Price.
+func splitPath(p string) []string {
+	var elems []string
+	for p != "" {
+		var n int
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			continue
+		case '[':
+			n = strings.IndexByte(p, ']') + 1
+			if n == 0 {
+				n = len(p)
+			}
+		default:
+			n = strings.IndexAny(p, ".[")
+			if n < 0 {
+				n = len(p)
+			}
+		}
+		elems = append(elems, p[:n])
+		p = p[n:]
+	}
+	return elems
+}
+
+// matchPath reports whether path matches the elements of pattern, see
+// IgnorePaths.
+func matchPath(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "*":
+			for i := 0; i <= len(path); i++ {
+				if matchPath(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		case "[*]":
+			if len(path) == 0 {
+				return false
+			}
+		default:
+			if len(path) == 0 || path[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
 // keyEqual compares a and b for equality.
-// Both a and b must be valid map keys.
-func keyEqual(av, bv reflect.Value) bool {
+// Both a and b must be valid map keys. Keys of unknown kinds are compared
+// with ==, or not equal if they can't be, unless strict, which panics.
+func keyEqual(av, bv reflect.Value, strict bool) bool {
 	if !av.IsValid() && !bv.IsValid() {
 		return true
 	}
@@ -240,7 +1212,7 @@ func keyEqual(av, bv reflect.Value) bool {
 		return a == b
 	case reflect.Array:
 		for i := 0; i < av.Len(); i++ {
-			if !keyEqual(av.Index(i), bv.Index(i)) {
+			if !keyEqual(av.Index(i), bv.Index(i), strict) {
 				return false
 			}
 		}
@@ -249,27 +1221,30 @@ func keyEqual(av, bv reflect.Value) bool {
 		a, b := av.Pointer(), bv.Pointer()
 		return a == b
 	case reflect.Interface:
-		return keyEqual(av.Elem(), bv.Elem())
+		return keyEqual(av.Elem(), bv.Elem(), strict)
 	case reflect.String:
 		a, b := av.String(), bv.String()
 		return a == b
 	case reflect.Struct:
 		for i := 0; i < av.NumField(); i++ {
-			if !keyEqual(av.Field(i), bv.Field(i)) {
+			if !keyEqual(av.Field(i), bv.Field(i), strict) {
 				return false
 			}
 		}
 		return true
 	default:
-		panic("invalid map key type " + av.Type().String())
+		if strict {
+			panic("invalid map key type " + av.Type().String())
+		}
+		return av.Type().Comparable() && av.CanInterface() && bv.CanInterface() && av.Interface() == bv.Interface()
 	}
 }
 
-func keyDiff(a, b []reflect.Value) (ak, both, bk []reflect.Value) {
+func keyDiff(a, b []reflect.Value, strict bool) (ak, both, bk []reflect.Value) {
 	for _, av := range a {
 		inBoth := false
 		for _, bv := range b {
-			if keyEqual(av, bv) {
+			if keyEqual(av, bv, strict) {
 				inBoth = true
 				both = append(both, av)
 				break
@@ -282,7 +1257,7 @@ func keyDiff(a, b []reflect.Value) (ak, both, bk []reflect.Value) {
 	for _, bv := range b {
 		inBoth := false
 		for _, av := range a {
-			if keyEqual(av, bv) {
+			if keyEqual(av, bv, strict) {
 				inBoth = true
 				break
 			}
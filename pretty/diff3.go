@@ -0,0 +1,100 @@
+package pretty
+
+import "strings"
+
+// change is a difference of a side of Diff3 from the base.
+type change struct {
+	Difference
+	text string // as printed by Diff, without the label but of moves
+}
+
+// Diff3 returns the changes of a and of b from their common base, e.g. of
+// two edits of a configuration, one per element like Diff, marked with the
+// side that made them:
+//
+//	a: Port: 80 != 8080
+//	b: Host: "db" != "db.local"
+//	both: Debug: false != true
+//	a (conflict): Items[1]: pretty.Item{…} != (missing)
+//	b (conflict): Items[1].Price: 10 != 12
+//
+// Changes made the same by both sides are marked both. Those of a and b at
+// the same path, or at paths in one another, that differ are conflicts: the
+// changes of both sides there are marked as such. The changes of a come
+// first, followed by those of b not made by a.
+func Diff3(base, a, b interface{}, opts ...DiffOpt) []string {
+	ca := changes(base, a, opts)
+	cb := changes(base, b, opts)
+	conflict := func(x change, others []change) (same, conflicts bool) {
+		for _, y := range others {
+			switch {
+			case x.Path == y.Path && x.Kind == y.Kind && x.text == y.text:
+				same = true
+			case pathsOverlap(x.at(), y.at()):
+				conflicts = true
+			}
+		}
+		return same, conflicts
+	}
+
+	var desc []string
+	for _, x := range ca {
+		switch same, conflicts := conflict(x, cb); {
+		case conflicts:
+			desc = append(desc, "a (conflict): "+x.label())
+		case same:
+			desc = append(desc, "both: "+x.label())
+		default:
+			desc = append(desc, "a: "+x.label())
+		}
+	}
+	for _, y := range cb {
+		switch same, conflicts := conflict(y, ca); {
+		case conflicts:
+			desc = append(desc, "b (conflict): "+y.label())
+		case !same:
+			desc = append(desc, "b: "+y.label())
+		}
+	}
+	return desc
+}
+
+// changes returns the differences of x from base, with their texts.
+func changes(base, x interface{}, opts []DiffOpt) []change {
+	report := DiffReport(base, x, opts...)
+	texts := Diff(base, x, opts...) // in the same order
+	cs := make([]change, len(report))
+	for i, r := range report {
+		cs[i] = change{Difference: r, text: texts[i]}
+		if r.Path != "" && r.Kind != Moved { // moves are labeled with their slice
+			cs[i].text = strings.TrimPrefix(texts[i], r.Path+": ")
+		}
+	}
+	return cs
+}
+
+// at returns the path of the values changed: that of the slice of a move,
+// which reorders it.
+func (c change) at() string {
+	if c.Kind == Moved {
+		return c.Path[:strings.LastIndexByte(c.Path, '[')]
+	}
+	return c.Path
+}
+
+// label returns the change as printed by Diff.
+func (c change) label() string {
+	if c.Path == "" || c.Kind == Moved {
+		return c.text
+	}
+	return c.Path + ": " + c.text
+}
+
+// pathsOverlap reports whether the labels p and q are the same path, or one
+// is in the other, like Items[1] and Items[1].Price.
+func pathsOverlap(p, q string) bool {
+	if len(p) > len(q) {
+		p, q = q, p
+	}
+	return p == "" || q == p || strings.HasPrefix(q, p) && (q[len(p)] == '.' || q[len(p)] == '[')
+}
@@ -0,0 +1,57 @@
+package pretty
+
+import "testing"
+
+func TestDiff3(t *testing.T) {
+	type server struct {
+		Host  string
+		Port  int
+		Debug bool
+		Items []Meta
+	}
+	base := server{Host: "db", Port: 80, Items: []Meta{{ID: 1}, {ID: 2, UpdatedAt: 10}, {ID: 3}}}
+	a := server{Host: "db", Port: 8080, Debug: true, Items: []Meta{{ID: 1}, {ID: 3}}}
+	b := server{Host: "db.local", Port: 80, Debug: true, Items: []Meta{{ID: 1}, {ID: 2, UpdatedAt: 12}, {ID: 3}}}
+	diffdiff(t, Diff3(base, a, b), []string{
+		"a: Port: 80 != 8080",
+		"both: Debug: false != true",
+		"a (conflict): Items[1]: pretty.Meta{ID:2, UpdatedAt:10} != (missing)",
+		`b: Host: "db" != "db.local"`,
+		"b (conflict): Items[1].UpdatedAt: 10 != 12",
+	})
+
+	b = server{Host: "db", Port: 9090, Items: []Meta{{ID: 2, UpdatedAt: 10}, {ID: 1}, {ID: 3}}}
+	diffdiff(t, Diff3(base, a, b), []string{
+		"a (conflict): Port: 80 != 8080",
+		"a: Debug: false != true",
+		"a (conflict): Items[1]: pretty.Meta{ID:2, UpdatedAt:10} != (missing)",
+		"b (conflict): Port: 80 != 9090",
+		"b (conflict): Items: moved [0]→[1]",
+	})
+
+	diffdiff(t, Diff3(1, 2, 3), []string{"a (conflict): 1 != 2", "b (conflict): 1 != 3"})
+	diffdiff(t, Diff3(base, base, b, IgnorePaths("Items")), []string{"b: Port: 80 != 9090"})
+	if got := Diff3(base, a, a); len(got) != 3 || got[0] != "both: Port: 80 != 8080" {
+		t.Errorf("Diff3(base, a, a) = %q", got)
+	}
+}
+
+func TestPathsOverlap(t *testing.T) {
+	cases := []struct {
+		p, q string
+		exp  bool
+	}{
+		{"", "A", true},
+		{"A", "A", true},
+		{"A", "A.B", true},
+		{"A[1]", "A", true},
+		{"A", "AB", false},
+		{"A[1]", "A[10]", false},
+		{"A.B", "A.C", false},
+	}
+	for _, tt := range cases {
+		if got := pathsOverlap(tt.p, tt.q); got != tt.exp {
+			t.Errorf("pathsOverlap(%q, %q) = %t want %t", tt.p, tt.q, got, tt.exp)
+		}
+	}
+}
@@ -2,10 +2,18 @@ package pretty
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"net"
 	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 	"unsafe"
 )
 
@@ -46,18 +54,27 @@ var diffs = []difftest{
 	{a: nil, b: nil},
 	{a: S{A: 1}, b: S{A: 1}},
 
-	{0, "", []string{`int != string`}},
+	{0, "", []string{`int(0) != "" (int != string)`}},
 	{0, 1, []string{`0 != 1`}},
-	{S{}, new(S), []string{`pretty.S != *pretty.S`}},
+	{S{}, new(S), []string{`pretty.S{} != &pretty.S{} (pretty.S != *pretty.S)`}},
 	{"a", "b", []string{`"a" != "b"`}},
 	{S{}, S{A: 1}, []string{`A: 0 != 1`}},
 	{new(S), &S{A: 1}, []string{`A: 0 != 1`}},
 	{S{S: new(S)}, S{S: &S{A: 1}}, []string{`S.A: 0 != 1`}},
 	{S{}, S{I: 0}, []string{`I: nil != int(0)`}},
-	{S{I: 1}, S{I: "x"}, []string{`I: int != string`}},
-	{S{}, S{C: []int{1}}, []string{`C: []int[0] != []int[1]`}},
-	{S{C: []int{}}, S{C: []int{1}}, []string{`C: []int[0] != []int[1]`}},
+	{S{I: 1}, S{I: "x"}, []string{`I: int(1) != "x" (int != string)`}},
+	{S{}, S{C: []int{1}}, []string{`C[0]: (missing) != int(1)`}},
+	{S{C: []int{}}, S{C: []int{1}}, []string{`C[0]: (missing) != int(1)`}},
+	{S{}, S{C: []int{}}, nil},
+	{[]string(nil), []string{}, nil},
+	{map[string]int(nil), map[string]int{}, nil},
+	{map[string]int{"a": 1}, map[string]int{"b": 2}, []string{`["a"]: int(1) != (missing)`, `["b"]: (missing) != int(2)`}},
+	{map[int]S{1: {A: 1}}, map[int]S{}, []string{"[1]: pretty.S{\n    A:  1,\n    S:  (*pretty.S)(nil),\n    I:  nil,\n    C:  nil,\n} != (missing)"}},
 	{S{C: []int{1, 2, 3}}, S{C: []int{1, 2, 4}}, []string{`C[2]: 3 != 4`}},
+	{[]int{1, 2, 3}, []int{0, 1, 2, 3}, []string{`[0]: (missing) != int(0)`}},
+	{[]int{1, 2, 3, 4}, []int{1, 3, 4}, []string{`[1]: int(2) != (missing)`}},
+	{[]int{1, 2, 3, 4}, []int{1, 5, 4, 6}, []string{`[1]: 2 != 5`, `[2]: int(3) != (missing)`, `[3]: (missing) != int(6)`}},
+	{[]S{{A: 1}, {A: 2}}, []S{{A: 0}, {A: 1}, {A: 3}}, []string{`[0]: (missing) != pretty.S{}`, `[1].A: 2 != 3`}},
 	{S{}, S{A: 1, S: new(S)}, []string{`A: 0 != 1`, `S: nil != &pretty.S{}`}},
 
 	// unexported fields of every reflect.Kind (both equal and unequal)
@@ -101,7 +118,7 @@ var diffs = []difftest{
 	{struct{ x func() }{f0}, struct{ x func() }{f1}, []string{fmt.Sprintf("x: %p != %p", f0, f1)}},
 	{struct{ x interface{} }{0}, struct{ x interface{} }{0}, nil},
 	{struct{ x interface{} }{0}, struct{ x interface{} }{1}, []string{`x: 0 != 1`}},
-	{struct{ x interface{} }{0}, struct{ x interface{} }{""}, []string{`x: int != string`}},
+	{struct{ x interface{} }{0}, struct{ x interface{} }{""}, []string{`x: int(0) != "" (int != string)`}},
 	{struct{ x interface{} }{0}, struct{ x interface{} }{nil}, []string{`x: int(0) != nil`}},
 	{struct{ x interface{} }{nil}, struct{ x interface{} }{0}, []string{`x: nil != int(0)`}},
 	{struct{ x map[int]int }{map[int]int{0: 0}}, struct{ x map[int]int }{map[int]int{0: 0}}, nil},
@@ -181,12 +198,45 @@ func TestKeyEqual(t *testing.T) {
 
 	for _, test := range cases {
 		rv := reflect.ValueOf(test).Elem()
-		if !keyEqual(rv, rv) {
+		if !keyEqual(rv, rv, true) {
 			t.Errorf("keyEqual(%s, %s) = false want true", rv.Type(), rv.Type())
 		}
 	}
 }
 
+func TestDiffUnsupportedKinds(t *testing.T) {
+	// No kind is unknown to diff yet, so unsupported is called directly.
+	var got sbuf
+	d := diffPrinter{w: &got}.relabel("X")
+	d.unsupported(reflect.ValueOf(1), reflect.ValueOf(1))
+	d.unsupported(reflect.ValueOf(1), reflect.ValueOf(2))
+	diffdiff(t, got, []string{"X: unsupported kind int"})
+
+	var differs bool
+	d = diffPrinter{differs: &differs}
+	if d.unsupported(reflect.ValueOf(1), reflect.ValueOf(2)); !differs {
+		t.Error("unsupported kind not reported to Equal")
+	}
+
+	s := reflect.ValueOf([]int{1})
+	if keyEqual(s, s, false) {
+		t.Error("keyEqual([]int, []int, false) = true want false")
+	}
+	for _, f := range []func(){
+		func() { StrictKinds(true)(&d); d.unsupported(s, s) },
+		func() { keyEqual(s, s, true) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("no panic in strict mode")
+				}
+			}()
+			f()
+		}()
+	}
+}
+
 func TestFdiff(t *testing.T) {
 	var buf bytes.Buffer
 	Fdiff(&buf, 0, 1)
@@ -215,12 +265,12 @@ func TestDiffCycle(t *testing.T) {
 	b.R = b2
 	expectDiffOutput(t, a, b, []string{`R: pretty.I{
     i:  1,
-    R:  &pretty.I{(CYCLIC REFERENCE)},
+    R:  <cycle to root>,
 } (previously visited) != pretty.I{
     i:  1,
     R:  &pretty.I{
         i:  1,
-        R:  &pretty.I{(CYCLIC REFERENCE)},
+        R:  <cycle to root>,
     },
 }`})
 
@@ -229,11 +279,11 @@ func TestDiffCycle(t *testing.T) {
     i:  1,
     R:  &pretty.I{
         i:  1,
-        R:  &pretty.I{(CYCLIC REFERENCE)},
+        R:  <cycle to root>,
     },
 } != pretty.I{
     i:  1,
-    R:  &pretty.I{(CYCLIC REFERENCE)},
+    R:  <cycle to root>,
 } (previously visited)`})
 }
 
@@ -255,3 +305,439 @@ func minus(t *testing.T, s string, a, b []string) {
 		}
 	}
 }
+
+type Meta struct {
+	ID        int
+	UpdatedAt int
+}
+
+type Doc struct {
+	ID    int
+	Meta  Meta
+	Items []Meta
+	Env   map[string]string
+}
+
+func TestDiffIgnorePaths(t *testing.T) {
+	a := Doc{ID: 1, Meta: Meta{ID: 1, UpdatedAt: 1}, Items: []Meta{{ID: 1, UpdatedAt: 1}}, Env: map[string]string{"HOME": "/a", "a.b": "x"}}
+	b := Doc{ID: 2, Meta: Meta{ID: 2, UpdatedAt: 2}, Items: []Meta{{ID: 2, UpdatedAt: 2}}, Env: map[string]string{"HOME": "/b", "a.b": "y"}}
+	cases := []struct {
+		paths []string
+		exp   []string
+	}{
+		{nil, []string{
+			"ID: 1 != 2", "Meta.ID: 1 != 2", "Meta.UpdatedAt: 1 != 2", "Items[0].ID: 1 != 2",
+			"Items[0].UpdatedAt: 1 != 2", `Env["HOME"]: "/a" != "/b"`, `Env["a.b"]: "x" != "y"`,
+		}},
+		{[]string{"Meta.UpdatedAt", "*.ID"}, []string{
+			"Items[0].UpdatedAt: 1 != 2", `Env["HOME"]: "/a" != "/b"`, `Env["a.b"]: "x" != "y"`,
+		}},
+		{[]string{"Items[*].UpdatedAt", `Env["a.b"]`, "Meta"}, []string{
+			"ID: 1 != 2", "Items[0].ID: 1 != 2", `Env["HOME"]: "/a" != "/b"`,
+		}},
+		{[]string{"Items[1]", "Env[*]", "ID"}, []string{
+			"Meta.ID: 1 != 2", "Meta.UpdatedAt: 1 != 2", "Items[0].ID: 1 != 2", "Items[0].UpdatedAt: 1 != 2",
+		}},
+	}
+	for _, tt := range cases {
+		diffdiff(t, Diff(a, b, IgnorePaths(tt.paths...)), tt.exp)
+	}
+}
+
+func TestDiffFilterPath(t *testing.T) {
+	a := Doc{ID: 1, Meta: Meta{ID: 1, UpdatedAt: 1}, Items: []Meta{{ID: 1, UpdatedAt: 1}, {ID: 2, UpdatedAt: 1}}, Env: map[string]string{"HOME": "/a"}}
+	b := Doc{ID: 2, Meta: Meta{ID: 2, UpdatedAt: 2}, Items: []Meta{{ID: 2, UpdatedAt: 2}, {ID: 3}}, Env: map[string]string{"HOME": "/b"}}
+	re := regexp.MustCompile
+	cases := []struct {
+		opts []DiffOpt
+		exp  []string
+	}{
+		{[]DiffOpt{FilterPath(re(`^Items\[\d+\]\.UpdatedAt$`))}, []string{
+			"Items[0].UpdatedAt: 1 != 2", "Items[1].UpdatedAt: 1 != 0",
+		}},
+		{[]DiffOpt{FilterPath(re(`^Meta\b`)), FilterPath(re(`^Env\b`))}, []string{
+			"Meta.ID: 1 != 2", "Meta.UpdatedAt: 1 != 2", `Env["HOME"]: "/a" != "/b"`,
+		}},
+		{[]DiffOpt{ExcludePath(re(`(^|\.)UpdatedAt$`)), ExcludePath(re(`^Env$`))}, []string{
+			"ID: 1 != 2", "Meta.ID: 1 != 2", "Items[0]: pretty.Meta{ID:1, UpdatedAt:1} != (missing)",
+			"Items[1]: (missing) != pretty.Meta{ID:3, UpdatedAt:0}",
+		}},
+		{[]DiffOpt{FilterPath(re(`^Items\b`)), ExcludePath(re(`UpdatedAt`))}, []string{
+			"Items[0]: pretty.Meta{ID:1, UpdatedAt:1} != (missing)", "Items[1]: (missing) != pretty.Meta{ID:3, UpdatedAt:0}",
+		}},
+	}
+	for _, tt := range cases {
+		diffdiff(t, Diff(a, b, tt.opts...), tt.exp)
+	}
+	if !Equal(a, b, FilterPath(re(`^Env\["PATH"\]`))) {
+		t.Error("Equal(a, b, FilterPath) = false")
+	}
+	if Equal(a, b, FilterPath(re(`^Items\[1\]\.ID`))) {
+		t.Error("Equal(a, b, FilterPath) = true")
+	}
+}
+
+func TestDiffFloatTolerance(t *testing.T) {
+	type point struct {
+		X, Y float64
+		Z    complex128
+	}
+	x, y := 0.1, 0.2
+	a := point{X: x + y, Y: 1e20, Z: complex(x+y, 1)}
+	b := point{X: 0.3, Y: 1e20 + 1e10, Z: complex(0.3, 1)}
+	diffdiff(t, Diff(a, b), []string{
+		"X: 0.30000000000000004 != 0.3",
+		"Y: 1e+20 != 1.0000000001e+20",
+		"Z: (0.30000000000000004+1i) != (0.3+1i)",
+	})
+	diffdiff(t, Diff(a, b, FloatTolerance(1e-9)), nil)
+	diffdiff(t, Diff(point{X: 1}, point{X: 1.1}, FloatTolerance(1e-9)), []string{"X: 1 != 1.1"})
+	diffdiff(t, Diff(math.NaN(), math.NaN(), FloatTolerance(1)), []string{"NaN != NaN"})
+}
+
+type UserID string
+
+type Purchase struct {
+	Buyer UserID
+	At    time.Time
+	Err   error
+	note  UserID
+}
+
+func TestDiffComparer(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := Purchase{Buyer: "ANN", At: at, Err: errors.New("x"), note: "a"}
+	b := Purchase{Buyer: "ann", At: at.In(time.FixedZone("X", 3600)), Err: errors.New("x"), note: "A"}
+	sameID := Comparer(func(a, b UserID) bool { return strings.EqualFold(string(a), string(b)) })
+	sameTime := Comparer(time.Time.Equal)
+	sameErr := Comparer(func(a, b error) bool { return a == nil && b == nil || a != nil && b != nil && a.Error() == b.Error() })
+	diffdiff(t, Diff(a, b, sameID, sameTime, sameErr), []string{
+		`note: "a" != "A"`,
+	})
+	diffdiff(t, Diff(a, Purchase{Buyer: "bob", At: at}, sameID, sameTime, sameErr), []string{
+		`Buyer: "ANN" != "bob"`,
+		`Err: &errors.errorString{s:"x"} != nil`,
+		`note: "a" != ""`,
+	})
+}
+
+func TestDiffLongSlices(t *testing.T) {
+	a, b := make([]int, 1100), make([]int, 1101)
+	for i := range a {
+		a[i], b[i] = i, i+1
+	}
+	// Too long to align, compared element by element.
+	got := Diff(a, b)
+	if len(got) != 1101 || got[0] != "[0]: 0 != 1" || got[1100] != "[1100]: (missing) != int(0)" {
+		t.Errorf("got %d differences: %q ... %q", len(got), got[0], got[len(got)-1])
+	}
+}
+
+func TestDiffUnordered(t *testing.T) {
+	type result struct{ Rows []Meta }
+	a := result{Rows: []Meta{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 3}}}
+	b := result{Rows: []Meta{{ID: 3}, {ID: 2, UpdatedAt: 1}, {ID: 1}, {ID: 4}}}
+	diffdiff(t, Diff(a, b, UnorderedSlices()), []string{
+		"Rows[1]: pretty.Meta{ID:2, UpdatedAt:0} != (missing)",
+		"Rows[3]: pretty.Meta{ID:3, UpdatedAt:0} != (missing)",
+		"Rows[1]: (missing) != pretty.Meta{ID:2, UpdatedAt:1}",
+		"Rows[3]: (missing) != pretty.Meta{ID:4, UpdatedAt:0}",
+	})
+	diffdiff(t, Diff([]string{"a", "b"}, []string{"b", "a"}, UnorderedSlices()), nil)
+
+	byID := SortSlices(func(a, b Meta) bool { return a.ID < b.ID })
+	diffdiff(t, Diff(a, b, byID), []string{
+		"Rows[1].UpdatedAt: 0 != 1",
+		"Rows[2]: pretty.Meta{ID:3, UpdatedAt:0} != (missing)",
+		"Rows[3]: (missing) != pretty.Meta{ID:4, UpdatedAt:0}",
+	})
+	diffdiff(t, Diff([]int{3, 1, 2}, []int{1, 2, 3}, byID), []string{"moved [0]→[2]"})
+}
+
+func TestDiffReport(t *testing.T) {
+	type item struct {
+		Name  string
+		Price int
+		note  string
+	}
+	type cart struct {
+		Items []item
+		Tags  map[string]int
+	}
+	a := cart{Items: []item{{"a", 1, "x"}, {"b", 2, ""}}, Tags: map[string]int{"old": 1}}
+	b := cart{Items: []item{{"a", 3, "y"}}, Tags: map[string]int{"new": 2}}
+	want := []Difference{
+		{Path: "Items[0].Price", Kind: Modified, A: 1, B: 3},
+		{Path: "Items[0].note", Kind: Modified, A: `"x"`, B: `"y"`},
+		{Path: "Items[1]", Kind: Removed, A: item{"b", 2, ""}},
+		{Path: `Tags["old"]`, Kind: Removed, A: 1},
+		{Path: `Tags["new"]`, Kind: Added, B: 2},
+	}
+	if got := DiffReport(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v", want)
+		t.Errorf("got      %v", got)
+	}
+	if got := DiffReport(a, a); got != nil {
+		t.Errorf("DiffReport(a, a) = %v", got)
+	}
+}
+
+func TestDiffMoved(t *testing.T) {
+	a := Doc{Items: []Meta{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}, {ID: 6}}}
+	b := Doc{Items: []Meta{{ID: 1}, {ID: 3}, {ID: 4}, {ID: 5}, {ID: 2}, {ID: 6, UpdatedAt: 1}}}
+	diffdiff(t, Diff(a, b), []string{"Items: moved [1]→[4]", "Items[5].UpdatedAt: 0 != 1"})
+
+	want := []Difference{
+		{Path: "Items[1]", Kind: Moved, A: Meta{ID: 2}, B: Meta{ID: 2}, To: "Items[4]"},
+		{Path: "Items[5].UpdatedAt", Kind: Modified, A: 0, B: 1},
+	}
+	if got := DiffReport(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v", want)
+		t.Errorf("got      %v", got)
+	}
+	if Equal(a, b, IgnorePaths("*.UpdatedAt")) {
+		t.Error("Equal(a, b) = true")
+	}
+
+	// Replaced elements don't move, and equal ones are matched once.
+	diffdiff(t, Diff([]int{1, 2, 3}, []int{1, 5, 3}), []string{"[1]: 2 != 5"})
+	diffdiff(t, Diff([]int{7, 1, 7}, []int{1, 7, 8, 7}), []string{"moved [0]→[1]", "[2]: (missing) != int(8)"})
+	diffdiff(t, Diff([]int{2, 1, 1}, []int{1, 1, 2}), []string{"moved [0]→[2]"})
+}
+
+func TestDiffColors(t *testing.T) {
+	a := Meta{ID: 1}
+	b := Meta{ID: 2}
+	want := []string{"\033[2mID:\033[0m \033[31m1\033[0m != \033[32m2\033[0m"}
+	diffdiff(t, Diff(a, b, DiffColors(true)), want)
+	diffdiff(t, Diff("x\ny", "x\nz", DiffColors(true)), []string{"@@ -1,2 +1,2 @@\n x\n\033[31m-y\033[0m\n\033[32m+z\033[0m"})
+
+	var buf bytes.Buffer
+	t.Setenv("NO_COLOR", "")
+	Cdiff(&buf, a, b)
+	if got := buf.String(); got != want[0]+"\n" {
+		t.Errorf("Cdiff: got %q", got)
+	}
+	buf.Reset()
+	t.Setenv("NO_COLOR", "1")
+	Cdiff(&buf, a, b)
+	if got := buf.String(); got != "ID: 1 != 2\n" {
+		t.Errorf("Cdiff with NO_COLOR: got %q", got)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	for _, tt := range diffs {
+		if got, want := Equal(tt.a, tt.b), len(tt.exp) == 0; got != want {
+			t.Errorf("Equal(%# v, %# v) = %v, want %v", Formatter(tt.a), Formatter(tt.b), got, want)
+		}
+	}
+	a := Doc{ID: 1, Meta: Meta{ID: 1, UpdatedAt: 1}, Items: []Meta{{ID: 1}}}
+	b := Doc{ID: 1, Meta: Meta{ID: 1, UpdatedAt: 2}, Items: []Meta{{ID: 2}}}
+	if Equal(a, b) {
+		t.Error("Equal(a, b) = true")
+	}
+	if !Equal(a, b, IgnorePaths("Meta.UpdatedAt", "Items[*].ID")) {
+		t.Error("Equal(a, b, IgnorePaths(...)) = false")
+	}
+	if !Equal([]float64{0.1 + 0.2}, []float64{0.3}, FloatTolerance(1e-9)) {
+		t.Error("Equal with FloatTolerance = false")
+	}
+}
+
+func TestEqualAllocs(t *testing.T) {
+	a, b := make([]Meta, 100), make([]Meta, 100)
+	same := testing.AllocsPerRun(10, func() { Equal(a, a) })
+	b[99].ID = 1
+	// The difference isn't printed.
+	if n := testing.AllocsPerRun(10, func() { Equal(a, b) }); n > same {
+		t.Errorf("Equal made %v allocations, %v for equal values", n, same)
+	}
+}
+
+func TestMaxDiffs(t *testing.T) {
+	a, b := make([]int, 1000), make([]int, 1000)
+	for i := range b {
+		b[i] = 1
+	}
+	want := []string{"[0]: 0 != 1", "[1]: 0 != 1", "… and more differences"}
+	if got := Diff(a, b, MaxDiffs(2)); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+	if got := Diff(a[:2], b[:2], MaxDiffs(2)); len(got) != 2 {
+		t.Errorf("got %q, want 2 differences", got)
+	}
+	if got := DiffReport(a, b, MaxDiffs(3)); len(got) != 3 {
+		t.Errorf("DiffReport: got %d differences, want 3", len(got))
+	}
+}
+
+func TestDiffEqualMethods(t *testing.T) {
+	type host struct {
+		IP   net.IP
+		Seen time.Time
+	}
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := host{IP: net.IPv4(10, 0, 0, 1), Seen: at}
+	b := host{IP: net.IP{10, 0, 0, 1}, Seen: at.In(time.FixedZone("CET", 3600))}
+	diffdiff(t, Diff(a, b), nil)
+	if !Equal(a, b) {
+		t.Error("Equal(a, b) = false")
+	}
+	if got := Diff(a, b, EqualMethods(false)); len(got) == 0 {
+		t.Error("Diff(a, b, EqualMethods(false)) found no differences")
+	}
+	b.Seen = at.Add(time.Second)
+	diffdiff(t, Diff(a, b), []string{"Seen: 2024-01-02T03:04:05Z != 2024-01-02T03:04:06Z"})
+}
+
+func TestDiffIgnoredTags(t *testing.T) {
+	type session struct {
+		User    string
+		mu      sync.Mutex `diff:"-"`
+		Seen    time.Time  `pretty:"diff:-"`
+		Hits    int        `q:"redact,diff:-"`
+		Version int
+	}
+	a := &session{User: "ann", Seen: time.Unix(1, 0), Hits: 1, Version: 1}
+	b := &session{User: "ann", Seen: time.Unix(2, 0), Hits: 2, Version: 1}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	diffdiff(t, Diff(a, b), nil)
+	if !Equal(a, b) {
+		t.Error("Equal(a, b) = false")
+	}
+	b.User, b.Version = "bob", 2
+	diffdiff(t, Diff(a, b), []string{`User: "ann" != "bob"`, "Version: 1 != 2"})
+}
+
+func TestDiffTypeMismatch(t *testing.T) {
+	decoded := map[string]interface{}{"id": float64(7), "ratio": 0.5, "name": "ann"}
+	typed := map[string]interface{}{"id": 7, "ratio": uint8(1), "name": []byte("ann")}
+	diffdiff(t, Diff(decoded, typed), []string{
+		`["id"]: float64(7) != int(7) (float64 != int, numerically equal)`,
+		`["name"]: "ann" != []uint8("ann") (string != []uint8)`,
+		`["ratio"]: float64(0.5) != uint8(0x1) (float64 != uint8)`,
+	})
+	diffdiff(t, Diff(int64(-1), uint64(math.MaxUint64)), []string{
+		"int64(-1) != uint64(0xffffffffffffffff) (int64 != uint64)",
+	})
+}
+
+func TestDiffJSONStrings(t *testing.T) {
+	type event struct {
+		Name    string
+		Body    string
+		Payload json.RawMessage
+	}
+	a := event{Name: "{x", Body: `{"id": 1, "tags": ["a", "b"]}`, Payload: json.RawMessage(`{"items":[{"price":10,"sku":"x"}]}`)}
+	b := event{Name: "{y", Body: "{\n  \"tags\": [\"a\", \"b\"],\n  \"id\": 1\n}", Payload: json.RawMessage(`{"items": [{"sku": "x", "price": 12}], "note": null}`)}
+	diffdiff(t, Diff(a, b, JSONStrings(true)), []string{
+		`Name: "{x" != "{y"`,
+		"Payload.items[0].price: 10 != 12",
+		"Payload.note: (missing) != nil",
+	})
+	if got := Diff(a, b); len(got) < 2 || !strings.HasPrefix(got[1], "Body: @@") {
+		t.Errorf("Diff without JSONStrings = %q", got)
+	}
+	b.Name, b.Payload = a.Name, json.RawMessage(`{"items":[{"sku":"x","price":10}]}`)
+	if !Equal(a, b, JSONStrings(true)) {
+		t.Error("Equal(a, b, JSONStrings(true)) = false")
+	}
+	// Empty members.
+	a.Body, b.Body = `{"a": {"": 1}, "": 1}`, `{"a": {"": 2}, "": 2}`
+	diffdiff(t, Diff(a, b, JSONStrings(true)), []string{
+		`Body[""]: 1 != 2`,
+		`Body.a[""]: 1 != 2`,
+	})
+}
+
+func TestDiffTransformer(t *testing.T) {
+	type user struct {
+		Email string
+		Tags  []string
+		Seen  time.Time
+	}
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := user{Email: "Ann@Example.com", Tags: []string{"A"}, Seen: at}
+	b := user{Email: "ann@example.com", Tags: []string{"a", "b"}, Seen: at.Add(400 * time.Millisecond)}
+	lower := Transformer("lower", strings.ToLower)
+	round := Transformer("round", func(t time.Time) time.Time { return t.Round(time.Second) })
+	diffdiff(t, Diff(a, b, lower, round), []string{`Tags[1]: (missing) != "b"`})
+
+	b.Seen = at.Add(time.Second)
+	diffdiff(t, Diff(a, b, lower, round), []string{
+		`Tags[1]: (missing) != "b"`,
+		"Seen.round(): 2024-01-02T03:04:05Z != 2024-01-02T03:04:06Z",
+	})
+
+	// To another type, whose values are compared instead.
+	count := Transformer("len", func(s []string) int { return len(s) })
+	diffdiff(t, Diff(a, b, count, IgnorePaths("Seen")), []string{
+		`Email: "Ann@Example.com" != "ann@example.com"`,
+		"Tags.len(): 1 != 2",
+	})
+	if !Equal(a, b, lower, Transformer("len", func(s []string) int { return 0 }), IgnorePaths("Seen")) {
+		t.Error("Equal(a, b) = false")
+	}
+}
+
+// countPrintfer counts the differences printed by Pdiff.
+type countPrintfer int
+
+func (n *countPrintfer) Printf(format string, args ...interface{}) { *n++ }
+
+// largeDocs returns two slices of n documents, of a few hundred MB for n of a
+// million, differing in one of every 1000 documents.
+func largeDocs(n int) (a, b []Doc) {
+	a, b = make([]Doc, n), make([]Doc, n)
+	for i := range a {
+		a[i] = Doc{ID: i, Meta: Meta{ID: i}, Items: []Meta{{ID: i}, {ID: i + 1}}, Env: map[string]string{"HOME": "/home"}}
+		b[i] = Doc{ID: i, Meta: Meta{ID: i}, Items: []Meta{{ID: i}, {ID: i + 1}}, Env: map[string]string{"HOME": "/home"}}
+		if i%1000 == 0 {
+			b[i].Meta.UpdatedAt = 1
+		}
+	}
+	return a, b
+}
+
+func TestPdiffLarge(t *testing.T) {
+	a, b := largeDocs(10000)
+	var n countPrintfer
+	Pdiff(&n, a, b)
+	if n != 10 {
+		t.Errorf("Pdiff printed %d differences want 10", n)
+	}
+	// Values without references, like Metas, aren't recorded for cycles, and
+	// the labels of the elements don't allocate: walking more elements
+	// doesn't take more memory.
+	few, many := make([]Meta, 10), make([]Meta, 10000)
+	fewAllocs := testing.AllocsPerRun(10, func() { Pdiff(&n, few, few) })
+	if allocs := testing.AllocsPerRun(10, func() { Pdiff(&n, many, many) }); allocs > fewAllocs {
+		t.Errorf("Pdiff of 10000 equal Metas: %v allocations, of 10: %v", allocs, fewAllocs)
+	}
+}
+
+func BenchmarkPdiffLarge(b *testing.B) {
+	x, y := largeDocs(1 << 20)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var n countPrintfer
+		Pdiff(&n, x, y)
+	}
+}
+
+func BenchmarkPdiffLargeFlat(b *testing.B) {
+	x, y := make([]Meta, 1<<24), make([]Meta, 1<<24) // 256 MB each
+	for i := range y {
+		y[i].ID = i / 1000
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var n countPrintfer
+		Pdiff(&n, x, y, MaxDiffs(100))
+	}
+}
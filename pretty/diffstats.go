@@ -0,0 +1,42 @@
+package pretty
+
+// DiffStat counts the differences of a kind under a path.
+type DiffStat struct {
+	Added, Removed, Modified, Moved int
+}
+
+// Total returns the number of differences of all kinds.
+func (s DiffStat) Total() int {
+	return s.Added + s.Removed + s.Modified + s.Moved
+}
+
+// DiffStats counts the differences between a and b found by DiffReport, by
+// kind and by top-level field, element or map entry, e.g. TLS, [0] or
+// ["routes"], so that a program can log a summary like
+//
+//	config changed: 3 fields under TLS, 12 under Routes
+//
+// rather than the differences themselves. Those of a and b themselves, like
+// 1 != 2, are counted under "".
+func DiffStats(a, b interface{}, opts ...DiffOpt) map[string]DiffStat {
+	stats := make(map[string]DiffStat)
+	for _, d := range DiffReport(a, b, opts...) {
+		top := ""
+		if path := splitPath(d.Path); len(path) > 0 {
+			top = path[0]
+		}
+		s := stats[top]
+		switch d.Kind {
+		case Added:
+			s.Added++
+		case Removed:
+			s.Removed++
+		case Modified:
+			s.Modified++
+		case Moved:
+			s.Moved++
+		}
+		stats[top] = s
+	}
+	return stats
+}
@@ -0,0 +1,44 @@
+package pretty
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffStats(t *testing.T) {
+	a := Doc{ID: 1, Meta: Meta{ID: 1}, Items: []Meta{{ID: 1}, {ID: 2}, {ID: 3}}, Env: map[string]string{"HOME": "/a", "a.b": "x"}}
+	b := Doc{ID: 2, Meta: Meta{ID: 2, UpdatedAt: 2}, Items: []Meta{{ID: 3}, {ID: 1, UpdatedAt: 1}, {ID: 4}}, Env: map[string]string{"HOME": "/b", "PATH": "/bin"}}
+	want := map[string]DiffStat{
+		"ID":    {Modified: 1},
+		"Meta":  {Modified: 2},
+		"Items": {Added: 2, Removed: 2},
+		"Env":   {Added: 1, Removed: 1, Modified: 1},
+	}
+	got := DiffStats(a, b)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v", want)
+		t.Errorf("got      %v", got)
+	}
+	if n := got["Items"].Total(); n != 4 {
+		t.Errorf("Total() = %d want 4", n)
+	}
+
+	cases := []struct {
+		a, b interface{}
+		opts []DiffOpt
+		want map[string]DiffStat
+	}{
+		{a, a, nil, map[string]DiffStat{}},
+		{a, b, []DiffOpt{IgnorePaths("Items", "Env", "*.ID")}, map[string]DiffStat{"Meta": {Modified: 1}}},
+		{1, 2, nil, map[string]DiffStat{"": {Modified: 1}}},
+		{[]int{1}, []int{1, 2}, nil, map[string]DiffStat{"[1]": {Added: 1}}},
+		{Doc{Items: []Meta{{ID: 1}, {ID: 2}}}, Doc{Items: []Meta{{ID: 2}, {ID: 1}}}, nil, map[string]DiffStat{"Items": {Moved: 1}}},
+		{map[string]int{"a": 1}, map[string]int{"a": 2}, nil, map[string]DiffStat{`["a"]`: {Modified: 1}}},
+	}
+	for _, tt := range cases {
+		if got := DiffStats(tt.a, tt.b, tt.opts...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expected %v", tt.want)
+			t.Errorf("got      %v", got)
+		}
+	}
+}
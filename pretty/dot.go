@@ -0,0 +1,138 @@
+package pretty
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// dotLimits keeps the labels of the nodes of Dot short.
+var dotLimits = Limits{MaxStringLen: 64, MaxElems: 8}
+
+// Dot writes v as a Graphviz DOT graph, to be rendered with e.g.
+// dot -Tsvg. Every struct, map, slice and array is a node labelled with its
+// type and its scalar fields or elements; the others are the targets of
+// edges labelled with the field name, map key or index. A value referred to
+// by several pointers is a single node, so shared and cyclic structures show
+// as such.
+func Dot(w io.Writer, v interface{}) error {
+	d := dotEncoder{
+		p:   &printer{cycles: newCycles(), limits: dotLimits, methods: DefaultMethods, style: style{compact: true}},
+		ids: make(map[visit]string),
+	}
+	d.buf.WriteString("digraph {\n\tnode [shape=box, fontname=monospace];\n")
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Interface && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if d.p.treeLeaf(rv) {
+		fmt.Fprintf(&d.buf, "\tn1 [label=\"%s\"];\n", dotEscape(d.format(rv, true)))
+	} else {
+		d.node(rv)
+	}
+	d.buf.WriteString("}\n")
+	_, err := w.Write(d.buf.Bytes())
+	return err
+}
+
+type dotEncoder struct {
+	buf bytes.Buffer
+	p   *printer // formats the scalars and tells them apart
+	ids map[visit]string
+	n   int // number of nodes
+}
+
+// node writes v, a pointer, map, slice, array or struct, and the nodes it
+// refers to, unless it was written before, and returns its id.
+func (d *dotEncoder) node(v reflect.Value) string {
+	vis, shared := dotVisit(v)
+	if id, ok := d.ids[vis]; ok && shared {
+		return id
+	}
+	d.n++
+	id := "n" + strconv.Itoa(d.n)
+	if shared {
+		d.ids[vis] = id // before the children, which may refer back to it
+	}
+
+	e := v
+	if e.Kind() == reflect.Ptr {
+		e = e.Elem()
+	}
+	lines := []string{v.Type().String()}
+	var refs []treeNode
+	for _, c := range d.p.treeNodes(e) {
+		if c.v.Kind() == reflect.Interface && !c.v.IsNil() {
+			c.v = c.v.Elem()
+		}
+		if (c.f == nil || !IsRedacted(*c.f)) && !d.p.treeLeaf(c.v) {
+			refs = append(refs, c)
+			continue
+		}
+		lines = append(lines, d.label(c)+": "+d.leaf(c))
+	}
+	fmt.Fprintf(&d.buf, "\t%s [label=\"%s\\l\"];\n", id, dotEscape(strings.Join(lines, "\n")))
+
+	for _, c := range refs {
+		fmt.Fprintf(&d.buf, "\t%s -> %s [label=\"%s\"];\n", id, d.node(c.v), dotEscape(d.label(c)))
+	}
+	return id
+}
+
+// dotVisit returns the key of the node of v, and false if v isn't referred
+// to by address and therefore can't be shared.
+func dotVisit(v reflect.Value) (visit, bool) {
+	switch {
+	case v.Kind() == reflect.Ptr:
+		return visit{typ: v.Type().Elem(), v: v.Pointer()}, true
+	case v.Kind() == reflect.Map || v.Kind() == reflect.Slice:
+		return visit{typ: v.Type(), v: v.Pointer()}, true
+	case v.CanAddr():
+		return visit{typ: v.Type(), v: v.UnsafeAddr()}, true
+	}
+	return visit{}, false
+}
+
+// label returns the field name, map key or index of a child.
+func (d *dotEncoder) label(c treeNode) string {
+	switch {
+	case c.f != nil:
+		return c.field
+	case c.key.IsValid():
+		return d.format(c.key, false)
+	}
+	return "[" + strconv.Itoa(c.index) + "]"
+}
+
+// leaf returns the value of a child printed on one line.
+func (d *dotEncoder) leaf(c treeNode) string {
+	if c.f == nil {
+		return d.format(c.v, false)
+	}
+	if IsRedacted(*c.f) {
+		return "***"
+	}
+	var b strings.Builder
+	p := *d.p
+	p.Writer = &b
+	if !p.printHinted(c.v, fieldHint(*c.f), false) {
+		p.printValue(c.v, labelType(c.f.Type), true)
+	}
+	return b.String()
+}
+
+func (d *dotEncoder) format(v reflect.Value, showType bool) string {
+	var b strings.Builder
+	p := *d.p
+	p.Writer = &b
+	p.printValue(v, showType, true)
+	return b.String()
+}
+
+// dotEscape escapes s for a quoted DOT string, with its lines left-aligned.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\l`).Replace(s)
+}
@@ -0,0 +1,62 @@
+package pretty
+
+import (
+	"strings"
+	"testing"
+)
+
+var dottests = []struct {
+	v interface{}
+	s string
+}{
+	{3, `digraph {
+	node [shape=box, fontname=monospace];
+	n1 [label="int(3)"];
+}
+`},
+	{list(1, 2), `digraph {
+	node [shape=box, fontname=monospace];
+	n1 [label="*pretty.DNode\lV: 1\lPrev: (*pretty.DNode)(nil)\l"];
+	n2 [label="*pretty.DNode\lV: 2\lNext: (*pretty.DNode)(nil)\l"];
+	n2 -> n1 [label="Prev"];
+	n1 -> n2 [label="Next"];
+}
+`},
+	{
+		func() interface{} {
+			shared := &T{1, 2}
+			return map[string]interface{}{"a": []*T{shared, shared}, "b": `x"y`, "c": shared}
+		}(),
+		`digraph {
+	node [shape=box, fontname=monospace];
+	n1 [label="map[string]interface {}\l\"b\": \"x\\\"y\"\l"];
+	n2 [label="[]*pretty.T\l"];
+	n3 [label="*pretty.T\lx: 1\ly: 2\l"];
+	n2 -> n3 [label="[0]"];
+	n2 -> n3 [label="[1]"];
+	n1 -> n2 [label="\"a\""];
+	n1 -> n3 [label="\"c\""];
+}
+`,
+	},
+	{Account{ID: 1, Creds: &Credentials{User: "u", Password: "p"}}, `digraph {
+	node [shape=box, fontname=monospace];
+	n1 [label="pretty.Account\lID: 1\l"];
+	n2 [label="*pretty.Credentials\lUser: \"u\"\lPassword: ***\lToken: ***\l"];
+	n1 -> n2 [label="Creds"];
+}
+`},
+}
+
+func TestDot(t *testing.T) {
+	for _, tt := range dottests {
+		var b strings.Builder
+		if err := Dot(&b, tt.v); err != nil {
+			t.Fatal(err)
+		}
+		if s := b.String(); tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+		}
+	}
+}
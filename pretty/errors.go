@@ -0,0 +1,121 @@
+package pretty
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// maxErrorDepth bounds the chains of wrapped errors, should an error wrap
+// itself.
+const maxErrorDepth = 100
+
+// errorCauses returns the errors err wraps, see errors.Unwrap and errors.Join.
+func errorCauses(err error) (causes []error) {
+	defer func() {
+		if recover() != nil {
+			causes = nil
+		}
+	}()
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		if e := x.Unwrap(); e != nil {
+			return []error{e}
+		}
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			if e != nil {
+				causes = append(causes, e)
+			}
+		}
+	}
+	return causes
+}
+
+// wrappingError returns the error v holds, and false if v isn't an error
+// that wraps others, like those made by fmt.Errorf with %w and errors.Join.
+func wrappingError(v reflect.Value) (error, bool) {
+	if !v.IsValid() || !v.CanInterface() || v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, false
+	}
+	err, ok := v.Interface().(error)
+	if !ok || len(errorCauses(err)) == 0 {
+		return nil, false
+	}
+	return err, true
+}
+
+// printError prints the chain of the errors v wraps, and returns false if v
+// doesn't wrap any, see printErrorChain.
+func (p *printer) printError(v reflect.Value) bool {
+	if p.goSyntax {
+		return false
+	}
+	err, ok := wrappingError(v)
+	if !ok {
+		return false
+	}
+	p.printErrorChain(err, "", 0)
+	return true
+}
+
+// printErrorChain prints the type and the message of err, followed by the
+// errors it wraps, each on a line of its own below it:
+//
+//	*fmt.wrapError("load: open app.yaml: no such file or directory")
+//	└── *fs.PathError("open app.yaml: no such file or directory")
+//	    └── syscall.Errno("no such file or directory")
+//
+// Compact prints them on one line, e.g. *fmt.wrapError("load: eof") <-
+// *errors.errorString("eof"), and the errors of errors.Join in parentheses.
+// prefix holds the guides of the errors wrapping err.
+func (p *printer) printErrorChain(err error, prefix string, depth int) {
+	p.writeType(reflect.TypeOf(err))
+	writeByte(p, '(')
+	p.writeColored(StringColor, strconv.Quote(errorText(err)))
+	writeByte(p, ')')
+
+	causes := errorCauses(err)
+	if len(causes) == 0 {
+		return
+	}
+	if depth >= maxErrorDepth {
+		io.WriteString(p, " <- …")
+		return
+	}
+	if p.compact {
+		io.WriteString(p, " <- ")
+		if len(causes) > 1 {
+			writeByte(p, '(')
+		}
+		for i, c := range causes {
+			if i > 0 {
+				io.WriteString(p, ", ")
+			}
+			p.printErrorChain(c, "", depth+1)
+		}
+		if len(causes) > 1 {
+			writeByte(p, ')')
+		}
+		return
+	}
+	for i, c := range causes {
+		guide, next := "├── ", "│   "
+		if i == len(causes)-1 {
+			guide, next = "└── ", "    "
+		}
+		io.WriteString(p, "\n"+prefix+guide)
+		p.printErrorChain(c, prefix+next, depth+1)
+	}
+}
+
+// errorText returns the message of err, or what it panicked with.
+func errorText(err error) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = fmt.Sprintf("PANIC=calling method \"Error\": %v", r)
+		}
+	}()
+	return err.Error()
+}
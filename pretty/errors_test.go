@@ -0,0 +1,56 @@
+package pretty
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"testing"
+)
+
+type Job struct {
+	Name string
+	Err  error
+}
+
+func TestErrorChain(t *testing.T) {
+	open := &fs.PathError{Op: "open", Path: "app.yaml", Err: fs.ErrNotExist}
+	load := fmt.Errorf("load: %w", open)
+	joined := errors.Join(load, io.EOF)
+	cases := []struct {
+		opts []Option
+		v    interface{}
+		s    string
+	}{
+		{nil, load, `*fmt.wrapError("load: open app.yaml: file does not exist")
+└── *fs.PathError("open app.yaml: file does not exist")
+    └── *errors.errorString("file does not exist")`},
+		{nil, joined, `*errors.joinError("load: open app.yaml: file does not exist\nEOF")
+├── *fmt.wrapError("load: open app.yaml: file does not exist")
+│   └── *fs.PathError("open app.yaml: file does not exist")
+│       └── *errors.errorString("file does not exist")
+└── *errors.errorString("EOF")`},
+		{nil, Job{Name: "sync", Err: fmt.Errorf("sync: %w", io.EOF)}, `pretty.Job{
+    Name: "sync",
+    Err:  *fmt.wrapError("sync: EOF")
+    └── *errors.errorString("EOF"),
+}`},
+		{[]Option{Compact(true)}, joined, `*errors.joinError("load: open app.yaml: file does not exist\nEOF") <- ` +
+			`(*fmt.wrapError("load: open app.yaml: file does not exist") <- *fs.PathError("open app.yaml: file does not exist") <- ` +
+			`*errors.errorString("file does not exist"), *errors.errorString("EOF"))`},
+		{[]Option{Tree(true)}, Job{Name: "sync", Err: fmt.Errorf("sync: %w", io.EOF)}, `pretty.Job
+├── Name: "sync"
+└── Err: *fmt.wrapError("sync: EOF")
+    └── *errors.errorString("EOF")`},
+		{nil, io.EOF, `&errors.errorString{s:"EOF"}`},
+	}
+	for _, tt := range cases {
+		s := fmt.Sprint(NewFormatter(tt.v, tt.opts...))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+			t.Errorf("expraw\n%s", tt.s)
+			t.Errorf("gotraw\n%s", s)
+		}
+	}
+}
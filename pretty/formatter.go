@@ -5,15 +5,19 @@ import (
 	"io"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"text/tabwriter"
-
-	"github.com/rogpeppe/go-internal/fmtsort"
+	"unicode/utf8"
 )
 
 type formatter struct {
-	v     reflect.Value
-	force bool
-	quote bool
+	v       reflect.Value
+	force   bool
+	quote   bool
+	limits  Limits
+	methods Methods
+	style
 }
 
 // Formatter makes a wrapper, f, that will format x as go source with line
@@ -26,7 +30,7 @@ type formatter struct {
 // format x according to the usual rules of package fmt.
 // In particular, if x satisfies fmt.Formatter, then x.Format will be called.
 func Formatter(x interface{}) (f fmt.Formatter) {
-	return formatter{v: reflect.ValueOf(x), quote: true}
+	return formatter{v: reflect.ValueOf(x), quote: true, methods: DefaultMethods}
 }
 
 func (fo formatter) String() string {
@@ -52,40 +56,83 @@ func (fo formatter) passThrough(f fmt.State, c rune) {
 
 func (fo formatter) Format(f fmt.State, c rune) {
 	if fo.force || c == 'v' && f.Flag('#') && f.Flag(' ') {
-		w := tabwriter.NewWriter(f, 4, 4, 1, ' ', 0)
-		p := &printer{tw: w, Writer: w, visited: make(map[visit]int)}
-		p.printValue(fo.v, true, fo.quote)
-		w.Flush()
+		fo.write(f)
 		return
 	}
 	fo.passThrough(f, c)
 }
 
+// write writes the value of fo to w, pretty-printed.
+func (fo formatter) write(w io.Writer) {
+	if fo.yaml {
+		fo.writeYAML(w)
+		return
+	}
+	if fo.html {
+		fo.writeHTML(w)
+		return
+	}
+	if fo.tree {
+		fo.writeTree(w)
+		return
+	}
+	if fo.goSyntax {
+		fo = fo.goLiteral()
+	}
+	if fo.lineWidth < 0 {
+		fo.compact = true
+	}
+	tw := tabwriterPool.Get().(*tabwriter.Writer)
+	tw.Init(w, fo.width(), fo.width(), 1, ' ', 0)
+	p := &printer{
+		tw: tw, Writer: tw, cycles: newCycles(),
+		limits: fo.limits, methods: fo.methods, style: fo.style,
+	}
+	p.printValue(fo.v, true, fo.quote)
+	tw.Flush()
+	tw.Init(nil, 4, 4, 1, ' ', 0) // don't keep w alive in the pool
+	tabwriterPool.Put(tw)
+}
+
+// tabwriterPool recycles the top-level tabwriters of Format, which are
+// needed for every formatted value.
+var tabwriterPool = sync.Pool{
+	New: func() interface{} { return new(tabwriter.Writer) },
+}
+
 type printer struct {
 	io.Writer
 	tw      *tabwriter.Writer
-	visited map[visit]int
+	cycles  *cycles
 	depth   int
+	level   int // nesting level of maps, structs, arrays and slices
+	limits  Limits
+	methods Methods
+	style
 }
 
 func (p *printer) indent() *printer {
 	q := *p
-	q.tw = tabwriter.NewWriter(p.Writer, 4, 4, 1, ' ', 0)
+	q.tw = tabwriter.NewWriter(p.Writer, p.width(), p.width(), 1, ' ', 0)
 	q.Writer = NewIndentWriter(q.tw, []byte{'\t'})
 	return &q
 }
 
 func (p *printer) printInline(v reflect.Value, x interface{}, showType bool) {
 	if showType {
-		io.WriteString(p, v.Type().String())
-		fmt.Fprintf(p, "(%#v)", x)
-	} else {
-		fmt.Fprintf(p, "%#v", x)
+		p.writeType(v.Type())
+		writeByte(p, '(')
+	}
+	p.color(NumberColor)
+	fmt.Fprintf(p, "%#v", x)
+	p.endColor()
+	if showType {
+		writeByte(p, ')')
 	}
 }
 
-// printValue must keep track of already-printed pointer values to avoid
-// infinite recursion.
+// printValue must keep track of the pointers, maps and slices being printed
+// to avoid infinite recursion, see cycles.
 type visit struct {
 	typ reflect.Type
 	v   uintptr
@@ -95,12 +142,12 @@ func (p *printer) catchPanic(v reflect.Value, method string) {
 	if r := recover(); r != nil {
 		if v.Kind() == reflect.Ptr && v.IsNil() {
 			writeByte(p, '(')
-			io.WriteString(p, v.Type().String())
+			p.writeType(v.Type())
 			io.WriteString(p, ")(nil)")
 			return
 		}
 		writeByte(p, '(')
-		io.WriteString(p, v.Type().String())
+		p.writeType(v.Type())
 		io.WriteString(p, ")(PANIC=calling method ")
 		io.WriteString(p, strconv.Quote(method))
 		io.WriteString(p, ": ")
@@ -110,18 +157,15 @@ func (p *printer) catchPanic(v reflect.Value, method string) {
 }
 
 func (p *printer) printValue(v reflect.Value, showType, quote bool) {
-	if p.depth > 10 {
+	if p.depth > 10 && !p.goSyntax { // Go syntax can't elide, cycles end the recursion
 		io.WriteString(p, "!%v(DEPTH EXCEEDED)")
 		return
 	}
 
-	if v.IsValid() && v.CanInterface() {
-		i := v.Interface()
-		if goStringer, ok := i.(fmt.GoStringer); ok {
-			defer p.catchPanic(v, "GoString")
-			io.WriteString(p, goStringer.GoString())
-			return
-		}
+	if p.printRegistered(v) || p.printTime(v, showType) || p.printBig(v, showType) || p.printMethod(v, showType) ||
+		p.printError(v) || p.printNull(v, showType, quote) || p.printProtoEnum(v, showType) || p.printBytes(v, showType) ||
+		p.printFitting(v, showType, quote) {
+		return
 	}
 
 	switch v.Kind() {
@@ -132,41 +176,84 @@ func (p *printer) printValue(v reflect.Value, showType, quote bool) {
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		p.printInline(v, v.Uint(), showType)
 	case reflect.Float32, reflect.Float64:
+		if p.goSyntax {
+			p.printGoFloat(v, showType)
+			break
+		}
 		p.printInline(v, v.Float(), showType)
 	case reflect.Complex64, reflect.Complex128:
+		if p.goSyntax {
+			p.printGoFloat(v, showType)
+			break
+		}
+		p.color(NumberColor)
 		fmt.Fprintf(p, "%#v", v.Complex())
+		p.endColor()
 	case reflect.String:
+		if p.goSyntax && showType && v.Type() != stringType {
+			p.writeType(v.Type())
+			writeByte(p, '(')
+			p.fmtString(v.String(), quote)
+			writeByte(p, ')')
+			break
+		}
 		p.fmtString(v.String(), quote)
 	case reflect.Map:
 		t := v.Type()
+		if !v.IsNil() {
+			vis := visit{typ: t, v: v.Pointer()}
+			if p.cycle(vis) {
+				break
+			}
+			p.enter(vis)
+			defer p.leave(vis)
+		}
+		if p.goSyntax && v.IsNil() {
+			p.printGoNil(v, showType)
+			break
+		}
 		if showType {
-			io.WriteString(p, t.String())
+			p.writeType(t)
+		}
+		if p.elideLevel(v, showType) {
+			break
 		}
+		p.level++
+		defer func() { p.level-- }()
 		writeByte(p, '{')
 		if nonzero(v) {
-			expand := !canInline(v.Type())
+			expand := p.expand(v.Type())
 			pp := p
 			if expand {
 				writeByte(p, '\n')
 				pp = p.indent()
 			}
-			sm := fmtsort.Sort(v)
-			for i := 0; i < v.Len(); i++ {
-				k := sm.Key[i]
-				mv := sm.Value[i]
-				pp.printValue(k, false, true)
-				writeByte(pp, ':')
+			keys, values := p.mapEntries(v)
+			n := p.limitElems(v.Len())
+			showKeyType := p.goSyntax && t.Key().Kind() == reflect.Interface
+			for i := 0; i < n; i++ {
+				k := keys[i]
+				mv := values[i]
+				if expand && p.colors {
+					pp.printKeyCell(k, showKeyType)
+				} else {
+					pp.printValue(k, showKeyType, true)
+					writeByte(pp, ':')
+				}
 				if expand {
 					writeByte(pp, '\t')
 				}
 				showTypeInStruct := t.Elem().Kind() == reflect.Interface
+				pp.push(pathElem{key: k})
 				pp.printValue(mv, showTypeInStruct, true)
+				pp.pop()
 				if expand {
 					io.WriteString(pp, ",\n")
 				} else if i < v.Len()-1 {
 					io.WriteString(pp, ", ")
 				}
 			}
+			pp.printMore(v.Len()-n, expand)
 			if expand {
 				pp.tw.Flush()
 			}
@@ -175,41 +262,54 @@ func (p *printer) printValue(v reflect.Value, showType, quote bool) {
 	case reflect.Struct:
 		t := v.Type()
 		if v.CanAddr() {
-			addr := v.UnsafeAddr()
-			vis := visit{v: addr, typ: t}
-			if vd, ok := p.visited[vis]; ok && vd < p.depth {
-				p.fmtString(t.String()+"{(CYCLIC REFERENCE)}", false)
-				break // don't print v again
+			// The pointer to v may have checked v already, see below.
+			vis := visit{typ: t, v: v.UnsafeAddr()}
+			if _, ok := p.cycles.visited[vis]; !ok {
+				p.enter(vis)
+				defer p.leave(vis)
 			}
-			p.visited[vis] = p.depth
 		}
-
 		if showType {
-			io.WriteString(p, t.String())
+			p.writeType(t)
 		}
+		if p.elideLevel(v, showType) {
+			break
+		}
+		p.level++
+		defer func() { p.level-- }()
 		writeByte(p, '{')
-		if nonzero(v) {
-			expand := !canInline(v.Type())
+		if fields := p.fields(v); nonzero(v) && len(fields) > 0 {
+			expand := p.expand(v.Type())
 			pp := p
 			if expand {
 				writeByte(p, '\n')
 				pp = p.indent()
 			}
-			for i := 0; i < v.NumField(); i++ {
+			for i, sf := range fields {
 				showTypeInStruct := true
-				if f := t.Field(i); f.Name != "" {
-					io.WriteString(pp, f.Name)
+				f, fv := sf.f, sf.v
+				if i > 0 && !expand {
+					io.WriteString(pp, ", ")
+				}
+				if sf.name != "" {
+					pp.writeColored(FieldColor, sf.name)
 					writeByte(pp, ':')
 					if expand {
+						pp.padCell(utf8.RuneCountInString(sf.name) + 1)
 						writeByte(pp, '\t')
 					}
-					showTypeInStruct = labelType(f.Type)
+					// Unlike elements, fields need the type of composite literals.
+					showTypeInStruct = labelType(f.Type) || p.goSyntax && isComposite(f.Type)
+				}
+				if IsRedacted(f) {
+					io.WriteString(pp, "***")
+				} else if p.goSyntax || !pp.printHinted(fv, fieldHint(f), showTypeInStruct) {
+					pp.push(pathElem{field: f.Name})
+					pp.printValue(fv, showTypeInStruct, true)
+					pp.pop()
 				}
-				pp.printValue(getField(v, i), showTypeInStruct, true)
 				if expand {
 					io.WriteString(pp, ",\n")
-				} else if i < v.NumField()-1 {
-					io.WriteString(pp, ", ")
 				}
 			}
 			if expand {
@@ -226,13 +326,21 @@ func (p *printer) printValue(v reflect.Value, showType, quote bool) {
 			pp.depth++
 			pp.printValue(e, showType, true)
 		default:
-			io.WriteString(p, v.Type().String())
+			p.writeType(v.Type())
 			io.WriteString(p, "(nil)")
 		}
 	case reflect.Array, reflect.Slice:
 		t := v.Type()
+		if v.Kind() == reflect.Slice && v.Len() > 0 {
+			vis := visit{typ: t, v: v.Pointer()}
+			if p.cycle(vis) {
+				break
+			}
+			p.enter(vis)
+			defer p.leave(vis)
+		}
 		if showType {
-			io.WriteString(p, t.String())
+			p.writeType(t)
 		}
 		if v.Kind() == reflect.Slice && v.IsNil() && showType {
 			io.WriteString(p, "(nil)")
@@ -242,22 +350,31 @@ func (p *printer) printValue(v reflect.Value, showType, quote bool) {
 			io.WriteString(p, "nil")
 			break
 		}
+		if p.elideLevel(v, showType) {
+			break
+		}
+		p.level++
+		defer func() { p.level-- }()
 		writeByte(p, '{')
-		expand := !canInline(v.Type())
+		expand := p.expand(v.Type())
 		pp := p
 		if expand {
 			writeByte(p, '\n')
 			pp = p.indent()
 		}
-		for i := 0; i < v.Len(); i++ {
+		n := p.limitElems(v.Len())
+		for i := 0; i < n; i++ {
 			showTypeInSlice := t.Elem().Kind() == reflect.Interface
+			pp.push(pathElem{index: i})
 			pp.printValue(v.Index(i), showTypeInSlice, true)
+			pp.pop()
 			if expand {
 				io.WriteString(pp, ",\n")
 			} else if i < v.Len()-1 {
 				io.WriteString(pp, ", ")
 			}
 		}
+		pp.printMore(v.Len()-n, expand)
 		if expand {
 			pp.tw.Flush()
 		}
@@ -266,33 +383,88 @@ func (p *printer) printValue(v reflect.Value, showType, quote bool) {
 		e := v.Elem()
 		if !e.IsValid() {
 			writeByte(p, '(')
-			io.WriteString(p, v.Type().String())
+			p.writeType(v.Type())
 			io.WriteString(p, ")(nil)")
-		} else {
-			pp := *p
-			pp.depth++
-			writeByte(pp, '&')
-			pp.printValue(e, true, true)
+			break
+		}
+		// Keyed by the value pointed to, so that cycles through a pointer to
+		// an addressable struct are detected before writing the &.
+		vis := visit{typ: e.Type(), v: v.Pointer()}
+		if p.cycle(vis) {
+			break
+		}
+		p.enter(vis)
+		defer p.leave(vis)
+		pp := *p
+		pp.depth++
+		if p.goSyntax && !p.literal(e) {
+			pp.printGoPointer(e)
+			break
 		}
+		writeByte(pp, '&')
+		pp.printValue(e, true, true)
 	case reflect.Chan:
+		if p.goSyntax {
+			p.printGoNil(v, showType)
+			break
+		}
 		x := v.Pointer()
 		if showType {
 			writeByte(p, '(')
-			io.WriteString(p, v.Type().String())
+			p.writeType(v.Type())
 			fmt.Fprintf(p, ")(%#v)", x)
 		} else {
 			fmt.Fprintf(p, "%#v", x)
 		}
 	case reflect.Func:
-		io.WriteString(p, v.Type().String())
+		if p.goSyntax {
+			p.printGoNil(v, showType)
+			break
+		}
+		p.writeType(v.Type())
 		io.WriteString(p, " {...}")
 	case reflect.UnsafePointer:
+		if p.goSyntax {
+			fmt.Fprintf(p, "unsafe.Pointer(uintptr(%#x))", v.Pointer())
+			break
+		}
 		p.printInline(v, v.Pointer(), showType)
 	case reflect.Invalid:
 		io.WriteString(p, "nil")
 	}
 }
 
+// expand reports whether the elements of a value of type t are printed on
+// lines of their own.
+func (p *printer) expand(t reflect.Type) bool {
+	if p.compact {
+		return false
+	}
+	if p.lineWidth > 0 {
+		return true // the values that fit are printed by printFitting
+	}
+	return !canInline(t)
+}
+
+// printFitting prints v on a single line and returns true if it fits in the
+// line width, see Width.
+func (p *printer) printFitting(v reflect.Value, showType, quote bool) bool {
+	if p.lineWidth <= 0 || p.compact || !v.IsValid() || !canExpand(v.Type()) {
+		return false
+	}
+	var b strings.Builder
+	pp := *p
+	pp.Writer, pp.compact = &b, true
+	pp.printValue(v, showType, quote)
+	s := b.String()
+	if strings.Contains(s, "\n") ||
+		p.level*p.width()+utf8.RuneCountInString(colorStripper.Replace(s)) > p.lineWidth {
+		return false
+	}
+	io.WriteString(p, s)
+	return true
+}
+
 func canInline(t reflect.Type) bool {
 	switch t.Kind() {
 	case reflect.Map:
@@ -317,6 +489,9 @@ func canInline(t reflect.Type) bool {
 }
 
 func canExpand(t reflect.Type) bool {
+	if isRegistered(t) {
+		return false
+	}
 	switch t.Kind() {
 	case reflect.Map, reflect.Struct,
 		reflect.Interface, reflect.Array, reflect.Slice,
@@ -326,6 +501,16 @@ func canExpand(t reflect.Type) bool {
 	return false
 }
 
+// isComposite reports whether values of type t are printed as composite
+// literals.
+func isComposite(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Array, reflect.Slice:
+		return true
+	}
+	return false
+}
+
 func labelType(t reflect.Type) bool {
 	switch t.Kind() {
 	case reflect.Interface, reflect.Struct:
@@ -334,11 +519,29 @@ func labelType(t reflect.Type) bool {
 	return false
 }
 
+// mapEntries returns the keys and values of the map v, sorted by key unless
+// maps are printed unsorted.
+func (p *printer) mapEntries(v reflect.Value) (keys, values []reflect.Value) {
+	keys = make([]reflect.Value, 0, v.Len())
+	values = make([]reflect.Value, 0, v.Len())
+	for it := v.MapRange(); it.Next(); {
+		keys, values = append(keys, it.Key()), append(values, it.Value())
+	}
+	if !p.unsortedMaps {
+		sortKeys(keys, values)
+	}
+	return keys, values
+}
+
 func (p *printer) fmtString(s string, quote bool) {
-	if quote {
+	s, more := p.limitString(s)
+	if quote && !p.rawStrings {
 		s = strconv.Quote(s)
 	}
-	io.WriteString(p, s)
+	p.writeColored(StringColor, s)
+	if more > 0 {
+		fmt.Fprintf(p, "…(+%d more bytes)", more)
+	}
 }
 
 func writeByte(w io.Writer, b byte) {
@@ -169,7 +169,7 @@ var gosyntax = []test{
 		},
 		`[]interface {}{
     pretty.LongStructTypeName{},
-    []uint8{0x1, 0x2, 0x3},
+    []uint8(hex:010203),
     pretty.T{x:3, y:4},
     pretty.LongStructTypeName{
         longFieldName:      "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
@@ -243,7 +243,7 @@ func TestCycle(t *testing.T) {
 
 	p := &A{}
 	s := fmt.Sprintf("%# v", Formatter([]*A{p, p}))
-	if strings.Contains(s, "CYCLIC") {
+	if strings.Contains(s, "cycle") {
 		t.Errorf("Repeated address detected as cyclic reference:\n%s", s)
 	}
 
@@ -337,3 +337,104 @@ func TestCycle(t *testing.T) {
 	*iv = *i
 	t.Logf("Example long interface cycle:\n%# v", Formatter(i))
 }
+
+type DNode struct {
+	V          int
+	Prev, Next *DNode
+}
+
+// list returns a doubly-linked list of the values.
+func list(vs ...int) *DNode {
+	var head, tail *DNode
+	for _, v := range vs {
+		n := &DNode{V: v, Prev: tail}
+		if tail == nil {
+			head = n
+		} else {
+			tail.Next = n
+		}
+		tail = n
+	}
+	return head
+}
+
+func TestCycleMarkers(t *testing.T) {
+	ring := list(1, 2)
+	ring.Prev, ring.Next.Next = ring.Next, ring
+
+	self := map[string]interface{}{"a": 1}
+	self["self"] = self
+
+	nested := []interface{}{0, map[string]interface{}{}}
+	nested[1].(map[string]interface{})["up"] = nested
+
+	cases := []test{
+		{map[string]*DNode{"l": list(1, 2)}, `map[string]*pretty.DNode{
+    "l": &pretty.DNode{
+        V:    1,
+        Prev: (*pretty.DNode)(nil),
+        Next: &pretty.DNode{
+            V:    2,
+            Prev: <cycle to ["l"]>,
+            Next: (*pretty.DNode)(nil),
+        },
+    },
+}`},
+		{list(1, 2, 3), `&pretty.DNode{
+    V:    1,
+    Prev: (*pretty.DNode)(nil),
+    Next: &pretty.DNode{
+        V:    2,
+        Prev: <cycle to root>,
+        Next: &pretty.DNode{
+            V:    3,
+            Prev: <cycle to Next>,
+            Next: (*pretty.DNode)(nil),
+        },
+    },
+}`},
+		{ring, `&pretty.DNode{
+    V:    1,
+    Prev: &pretty.DNode{
+        V:    2,
+        Prev: <cycle to root>,
+        Next: <cycle to root>,
+    },
+    Next: &pretty.DNode{
+        V:    2,
+        Prev: <cycle to root>,
+        Next: <cycle to root>,
+    },
+}`},
+		{self, `map[string]interface {}{
+    "a":    int(1),
+    "self": <cycle to root>,
+}`},
+		{nested, `[]interface {}{
+    int(0),
+    map[string]interface {}{
+        "up": <cycle to root>,
+    },
+}`},
+		{[]*DNode{list(1, 2)}, `[]*pretty.DNode{
+    &pretty.DNode{
+        V:    1,
+        Prev: (*pretty.DNode)(nil),
+        Next: &pretty.DNode{
+            V:    2,
+            Prev: <cycle to [0]>,
+            Next: (*pretty.DNode)(nil),
+        },
+    },
+}`},
+	}
+	for _, tt := range cases {
+		s := fmt.Sprintf("%# v", Formatter(tt.v))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+			t.Errorf("expraw\n%s", tt.s)
+			t.Errorf("gotraw\n%s", s)
+		}
+	}
+}
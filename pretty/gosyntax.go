@@ -0,0 +1,192 @@
+package pretty
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// GoSyntax prints values as Go expressions that always compile, so that they
+// can be pasted into tests as fixtures: values are printed as composite
+// literals with their type names, pointers as &T{…} or &[]T{v}[0], times as
+// time.Date(…) and durations like 90 * time.Minute. Unexported and redacted
+// fields are left out, cycles are printed as nil, and the limits and the
+// methods other than UseGoString are ignored.
+func GoSyntax(on bool) Option {
+	return func(f *formatter) { f.goSyntax = on }
+}
+
+var stringType = reflect.TypeOf("")
+
+// goLiteral returns fo with the settings that would make the output invalid
+// Go turned off.
+func (fo formatter) goLiteral() formatter {
+	fo.quote = true
+	fo.limits = Limits{}
+	fo.methods &= UseGoString
+	fo.unexported = HideUnexported
+	fo.rawStrings, fo.rawTime, fo.yaml = false, false, false
+	return fo
+}
+
+// literal reports whether v is printed as a composite literal, whose address
+// can be taken with &.
+func (p *printer) literal(v reflect.Value) bool {
+	if p.methods&UseGoString != 0 && v.CanInterface() {
+		if _, ok := v.Interface().(fmt.GoStringer); ok {
+			return false
+		}
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return v.Type() != timeType
+	case reflect.Array:
+		return true
+	case reflect.Map:
+		return !v.IsNil()
+	case reflect.Slice:
+		isBytes := p.bytes != BytesList && v.Type().Elem().Kind() == reflect.Uint8 && v.Len() > 0
+		return !v.IsNil() && !isBytes
+	}
+	return false
+}
+
+// printGoPointer prints a pointer to e, which isn't a composite literal, as
+// the address of the only element of a slice literal, e.g. &[]int{1}[0].
+func (p *printer) printGoPointer(e reflect.Value) {
+	io.WriteString(p, "&[]")
+	p.writeType(e.Type())
+	writeByte(p, '{')
+	p.printValue(e, e.Kind() == reflect.Interface, true)
+	io.WriteString(p, "}[0]")
+}
+
+// printGoNil prints a nil map, or a channel or function, whose values can't
+// be written in Go, as nil.
+func (p *printer) printGoNil(v reflect.Value, showType bool) {
+	if showType {
+		writeByte(p, '(')
+		p.writeType(v.Type())
+		io.WriteString(p, ")(")
+	}
+	p.writeColored(NumberColor, "nil")
+	if showType {
+		writeByte(p, ')')
+	}
+}
+
+// printGoFloat prints a float or complex number. NaN and infinities are
+// written with the functions of package math, and always with their type.
+func (p *printer) printGoFloat(v reflect.Value, showType bool) {
+	var s string
+	finite := true
+	if v.Kind() == reflect.Complex64 || v.Kind() == reflect.Complex128 {
+		c := v.Complex()
+		re, okRe := goFloat(real(c))
+		im, okIm := goFloat(imag(c))
+		if finite = okRe && okIm; finite {
+			s = fmt.Sprintf("%#v", c)
+		} else {
+			s = "complex(" + re + ", " + im + ")"
+		}
+	} else {
+		s, finite = goFloat(v.Float())
+	}
+	showType = showType || !finite
+	if showType {
+		p.writeType(v.Type())
+		writeByte(p, '(')
+	}
+	p.writeColored(NumberColor, s)
+	if showType {
+		writeByte(p, ')')
+	}
+}
+
+// goFloat returns f as Go source, and false if f is NaN or infinite.
+func goFloat(f float64) (string, bool) {
+	switch {
+	case math.IsNaN(f):
+		return "math.NaN()", false
+	case math.IsInf(f, 1):
+		return "math.Inf(1)", false
+	case math.IsInf(f, -1):
+		return "math.Inf(-1)", false
+	}
+	return fmt.Sprintf("%#v", f), true
+}
+
+// goTime returns the Go source of a time.Time, a time.Duration or a
+// *time.Location, and false for values of other types.
+func goTime(v reflect.Value) (string, bool) {
+	switch v.Type() {
+	case durationType:
+		return goDuration(time.Duration(v.Int())), true
+	case timeType:
+		if v.CanInterface() {
+			t := v.Interface().(time.Time)
+			if t.IsZero() {
+				return "time.Time{}", true
+			}
+			return fmt.Sprintf("time.Date(%d, time.%s, %d, %d, %d, %d, %d, %s)",
+				t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(),
+				goLocation(t.Location(), t)), true
+		}
+	case locationType:
+		if v.CanInterface() && !v.IsNil() {
+			l := v.Interface().(*time.Location)
+			return goLocation(l, time.Now()), true
+		}
+	}
+	return "", false
+}
+
+// goLocation returns l as Go source. Locations other than UTC and Local are
+// written as the fixed zone they have at t.
+func goLocation(l *time.Location, t time.Time) string {
+	switch l {
+	case time.UTC:
+		return "time.UTC"
+	case time.Local:
+		return "time.Local"
+	}
+	name, offset := t.In(l).Zone()
+	return "time.FixedZone(" + strconv.Quote(name) + ", " + strconv.Itoa(offset) + ")"
+}
+
+var durationUnits = []struct {
+	d    time.Duration
+	name string
+}{
+	{time.Hour, "time.Hour"},
+	{time.Minute, "time.Minute"},
+	{time.Second, "time.Second"},
+	{time.Millisecond, "time.Millisecond"},
+	{time.Microsecond, "time.Microsecond"},
+	{time.Nanosecond, "time.Nanosecond"},
+}
+
+// goDuration returns d as a multiple of the largest unit it is a multiple
+// of, e.g. 90 * time.Minute.
+func goDuration(d time.Duration) string {
+	if d == 0 {
+		return "time.Duration(0)"
+	}
+	for _, u := range durationUnits {
+		if d%u.d != 0 {
+			continue
+		}
+		switch n := d / u.d; n {
+		case 1:
+			return u.name
+		case -1:
+			return "-" + u.name
+		default:
+			return strconv.FormatInt(int64(n), 10) + " * " + u.name
+		}
+	}
+	panic("unreachable")
+}
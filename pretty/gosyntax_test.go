@@ -0,0 +1,65 @@
+package pretty
+
+import (
+	"fmt"
+	"go/parser"
+	"math"
+	"testing"
+	"time"
+)
+
+var gosyntaxtests = []struct {
+	v interface{}
+	s string
+}{
+	{3, `int(3)`},
+	{math.NaN(), `float64(math.NaN())`},
+	{map[string]float32{"a": float32(math.Inf(1)), "b": 1.5}, `map[string]float32{"a":float32(math.Inf(1)), "b":1.5}`},
+	{map[string]int(nil), `(map[string]int)(nil)`},
+	{map[interface{}]int{N{1}: 1}, `map[interface {}]int{pretty.N{N:1}:1}`},
+	{[]interface{}{F(1), "a", nil}, `[]interface {}{pretty.F(1), "a", nil}`},
+	{[]byte("\x89PNG"), `[]uint8("\x89PNG")`},
+	{[2]byte{'a', 'b'}, `[2]uint8{0x61, 0x62}`},
+	{&[]string{"a"}[0], `&[]string{"a"}[0]`},
+	{S{A: 1, C: []int{1}}, `pretty.S{A:1, S:(*pretty.S)(nil), I:nil, C:[]int{1}}`},
+	{
+		&Account{ID: 1, Creds: &Credentials{User: "u", Password: "p", Token: "t"}},
+		`&pretty.Account{ID:1, Creds:&pretty.Credentials{User:"u"}}`,
+	},
+	{
+		Event{
+			At:    time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+			Took:  -90 * time.Second,
+			Zone:  time.FixedZone("CET", 3600),
+			Until: &time.Time{},
+		},
+		`pretty.Event{At:time.Date(2024, time.January, 2, 3, 4, 5, 6, time.UTC), Took:-90 * time.Second, Zone:time.FixedZone("CET", 3600), Until:&[]time.Time{time.Time{}}[0]}`,
+	},
+	{list(1, 2), `&pretty.DNode{V:1, Prev:(*pretty.DNode)(nil), Next:&pretty.DNode{V:2, Prev:nil /* cycle to root */, Next:(*pretty.DNode)(nil)}}`},
+	{struct{ C chan int }{make(chan int)}, `struct { C chan int }{C:nil}`},
+}
+
+func TestGoSyntaxOption(t *testing.T) {
+	for _, tt := range gosyntaxtests {
+		s := fmt.Sprint(NewFormatter(tt.v, GoSyntax(true), Compact(true), MaxSliceLen(1), MaxStringLen(1)))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+		}
+		if _, err := parser.ParseExpr(s); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+}
+
+func TestGoSyntaxExpanded(t *testing.T) {
+	want := `pretty.Account{
+    ID:    1,
+    Creds: &pretty.Credentials{User:"u"},
+}`
+	s := fmt.Sprint(NewFormatter(Account{ID: 1, Creds: &Credentials{User: "u"}}, GoSyntax(true)))
+	if s != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", s)
+	}
+}
@@ -0,0 +1,154 @@
+package pretty
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// HTML prints values as an HTML fragment, e.g. for a web page or a bug
+// report: laid out like Tree, with every non-empty struct, map and slice a
+// collapsible <details> element, and the syntax highlighted with the CSS
+// classes of HTMLStyle rather than with ANSI colors.
+//
+//	<div class="pretty"><details open><summary><span class="pretty-type">pretty.Config</span></summary>
+//	<div><span class="pretty-field">Name</span>: <span class="pretty-string">&#34;api&#34;</span></div>
+//	…
+func HTML(on bool) Option {
+	return func(f *formatter) { f.html = on }
+}
+
+// HTMLStyle is a style sheet for the output of HTML, to include in the
+// <style> element of a page.
+const HTMLStyle = `.pretty { font-family: ui-monospace, Menlo, Consolas, monospace; white-space: pre-wrap; }
+.pretty details > :not(summary) { margin-left: 2ch; }
+.pretty summary { cursor: pointer; }
+.pretty-type { color: #0184bc; }
+.pretty-field { color: #4078f2; }
+.pretty-string { color: #50a14f; }
+.pretty-number { color: #a626a4; }
+`
+
+// htmlReplacer escapes text for HTML, and turns the escape codes of the
+// colors into the spans of the CSS classes of HTMLStyle.
+var htmlReplacer = strings.NewReplacer(
+	TypeColor, `<span class="pretty-type">`,
+	FieldColor, `<span class="pretty-field">`,
+	StringColor, `<span class="pretty-string">`,
+	NumberColor, `<span class="pretty-number">`,
+	ResetColor, `</span>`,
+	"&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&#34;", "'", "&#39;",
+)
+
+// htmlWriter writes the text of the printer to w as HTML, see htmlReplacer.
+type htmlWriter struct{ w io.Writer }
+
+func (hw htmlWriter) Write(b []byte) (int, error) {
+	if _, err := io.WriteString(hw.w, htmlReplacer.Replace(string(b))); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// writeHTML writes v as HTML, without a trailing newline.
+func (fo formatter) writeHTML(w io.Writer) {
+	p := &printer{Writer: htmlWriter{w}, cycles: newCycles(), limits: fo.limits, methods: fo.methods, style: fo.style}
+	p.compact, p.colors = true, true // leaves are printed on their line, colors as classes
+	p.markup(`<div class="pretty">`)
+	p.printHTMLNode(fo.v, func() {}, true)
+	p.markup(`</div>`)
+}
+
+// markup writes the HTML s as it is.
+func (p *printer) markup(s string) {
+	io.WriteString(p.Writer.(htmlWriter).w, s)
+}
+
+// printHTMLNode prints v like printTreeNode, as a <div> line if it is a leaf
+// and as a <details> element with its children if it is a branch, after the
+// label printed by label.
+func (p *printer) printHTMLNode(v reflect.Value, label func(), showType bool) {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	e := v
+	if e.Kind() == reflect.Ptr && !e.IsNil() && !p.treeLeaf(e) {
+		e = e.Elem()
+	}
+	if p.treeLeaf(e) || p.limits.MaxDepth > 0 && p.level >= p.limits.MaxDepth {
+		showType = showType || v.IsValid() && isComposite(v.Type())
+		p.markup("<div>")
+		label()
+		p.printValue(v, showType, true)
+		p.markup("</div>\n")
+		return
+	}
+
+	var vis visit
+	switch {
+	case v.Kind() == reflect.Ptr:
+		vis = visit{typ: e.Type(), v: v.Pointer()}
+	case v.Kind() == reflect.Map || v.Kind() == reflect.Slice:
+		vis = visit{typ: v.Type(), v: v.Pointer()}
+	}
+	if vis.typ != nil {
+		if _, ok := p.cycles.visited[vis]; ok {
+			p.markup("<div>")
+			label()
+			p.cycle(vis)
+			p.markup("</div>\n")
+			return
+		}
+		p.enter(vis)
+		defer p.leave(vis)
+	}
+
+	p.markup("<details open><summary>")
+	label()
+	p.writeType(v.Type())
+	p.markup("</summary>\n")
+	p.level++
+	nodes := p.treeNodes(e)
+	n := p.limitElems(len(nodes))
+	if e.Kind() == reflect.Struct {
+		n = len(nodes) // the limit is for elements
+	}
+	for _, c := range nodes[:n] {
+		p.printHTMLChild(c)
+	}
+	p.level--
+	if n < len(nodes) {
+		p.markup("<div>")
+		fmt.Fprintf(p, "…(+%d more elems)", len(nodes)-n)
+		p.markup("</div>\n")
+	}
+	p.markup("</details>\n")
+}
+
+// printHTMLChild prints a child like printTreeChild.
+func (p *printer) printHTMLChild(c treeNode) {
+	label := func() { p.printTreeLabel(c) }
+	if c.f != nil && IsRedacted(*c.f) {
+		p.markup("<div>")
+		label()
+		io.WriteString(p, "***")
+		p.markup("</div>\n")
+		return
+	}
+	if c.f != nil {
+		// Printed aside first, as the hint may not apply.
+		var b strings.Builder
+		hp := *p
+		hp.Writer = htmlWriter{&b}
+		if hp.printHinted(c.v, fieldHint(*c.f), false) {
+			p.markup("<div>")
+			label()
+			p.markup(b.String() + "</div>\n")
+			return
+		}
+	}
+	p.push(c.step())
+	p.printHTMLNode(c.v, label, false)
+	p.pop()
+}
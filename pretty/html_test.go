@@ -0,0 +1,76 @@
+package pretty
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var htmltests = []struct {
+	opts []Option
+	v    interface{}
+	s    string
+}{
+	{nil, 3, `<div class="pretty"><div><span class="pretty-type">int</span>(<span class="pretty-number">3</span>)</div>
+</div>`},
+	{
+		nil,
+		map[string]interface{}{"a<b>": []int{1}, "empty": []int{}},
+		`<div class="pretty"><details open><summary><span class="pretty-type">map[string]interface {}</span></summary>
+<details open><summary><span class="pretty-string">&#34;a&lt;b&gt;&#34;</span>: <span class="pretty-type">[]int</span></summary>
+<div>[0]: <span class="pretty-number">1</span></div>
+</details>
+<div><span class="pretty-string">&#34;empty&#34;</span>: <span class="pretty-type">[]int</span>{}</div>
+</details>
+</div>`,
+	},
+	{
+		nil,
+		list(1),
+		`<div class="pretty"><details open><summary><span class="pretty-type">*pretty.DNode</span></summary>
+<div><span class="pretty-field">V</span>: <span class="pretty-number">1</span></div>
+<div><span class="pretty-field">Prev</span>: (<span class="pretty-type">*pretty.DNode</span>)(nil)</div>
+<div><span class="pretty-field">Next</span>: (<span class="pretty-type">*pretty.DNode</span>)(nil)</div>
+</details>
+</div>`,
+	},
+	{
+		nil,
+		Account{ID: 1, Creds: &Credentials{User: "u", Password: "x"}},
+		`<div class="pretty"><details open><summary><span class="pretty-type">pretty.Account</span></summary>
+<div><span class="pretty-field">ID</span>: <span class="pretty-number">1</span></div>
+<details open><summary><span class="pretty-field">Creds</span>: <span class="pretty-type">*pretty.Credentials</span></summary>
+<div><span class="pretty-field">User</span>: <span class="pretty-string">&#34;u&#34;</span></div>
+<div><span class="pretty-field">Password</span>: ***</div>
+<div><span class="pretty-field">Token</span>: ***</div>
+</details>
+</details>
+</div>`,
+	},
+	{
+		[]Option{MaxSliceLen(1)},
+		[]int{1, 2},
+		`<div class="pretty"><details open><summary><span class="pretty-type">[]int</span></summary>
+<div>[0]: <span class="pretty-number">1</span></div>
+<div>…(+1 more elems)</div>
+</details>
+</div>`,
+	},
+}
+
+func TestHTML(t *testing.T) {
+	for _, tt := range htmltests {
+		s := fmt.Sprint(NewFormatter(tt.v, append(tt.opts, HTML(true))...))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+		}
+	}
+
+	// Cycles are printed like Tree prints them.
+	s := fmt.Sprint(NewFormatter(list(1, 2), HTML(true)))
+	if exp := `<div><span class="pretty-field">Prev</span>: &lt;cycle to root&gt;</div>`; !strings.Contains(s, exp) {
+		t.Errorf("expected %q", exp)
+		t.Errorf("got      %q", s)
+	}
+}
@@ -0,0 +1,343 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// JSON returns v encoded as indented JSON, configured by the options like
+// NewFormatter. Unlike encoding/json, it encodes any value: cycles are
+// encoded as "<cycle to path>", NaN and infinities as the strings "NaN",
+// "+Inf" and "-Inf", map keys that are not strings as their printed form, and
+// channels and functions as their type. Unexported struct fields are
+// included unless hidden with UnexportedFields, and json tags rename or skip
+// fields like they do for encoding/json.
+func JSON(v interface{}, opts ...Option) ([]byte, error) {
+	f := formatter{quote: true, methods: DefaultMethods}
+	for _, opt := range opts {
+		opt(&f)
+	}
+	e := jsonEncoder{style: f.style, limits: f.limits, cycles: newCycles()}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+type jsonEncoder struct {
+	buf bytes.Buffer
+	style
+	limits Limits
+	cycles *cycles
+	level  int // nesting level of objects and arrays
+}
+
+func (e *jsonEncoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf.WriteString("null")
+		return nil
+	}
+	if f, ok := registered(v); ok {
+		e.string(f(v))
+		return nil
+	}
+	if !e.rawTime {
+		if s, ok := formatTime(v); ok {
+			e.string(s)
+			return nil
+		}
+	}
+	if v.Type().Implements(jsonMarshalerType) && v.CanInterface() &&
+		(v.Kind() != reflect.Ptr || !v.IsNil()) {
+		return e.marshaler(v.Interface().(json.Marshaler))
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		e.buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		e.float(v.Float(), v.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		e.string(strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()))
+	case reflect.String:
+		e.string(v.String())
+	case reflect.Interface:
+		return e.encode(v.Elem())
+	case reflect.Ptr:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		return e.visit(visit{typ: v.Type().Elem(), v: v.Pointer()}, func() error {
+			return e.encode(v.Elem())
+		})
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		return e.visit(visit{typ: v.Type(), v: v.Pointer()}, func() error { return e.object(v) })
+	case reflect.Struct:
+		return e.object(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.string(base64.StdEncoding.EncodeToString(v.Bytes()))
+			return nil
+		}
+		return e.visit(visit{typ: v.Type(), v: v.Pointer()}, func() error { return e.array(v) })
+	case reflect.Array:
+		return e.array(v)
+	default:
+		e.string(v.Type().String())
+	}
+	return nil
+}
+
+// visit calls encode unless vis is an ancestor, which is encoded as
+// "<cycle to path>".
+func (e *jsonEncoder) visit(vis visit, encode func() error) error {
+	return e.cycles.walk(vis, func(path string) { e.string("<cycle to " + path + ">") }, encode)
+}
+
+// marshaler writes the JSON of a json.Marshaler, indented like the rest.
+func (e *jsonEncoder) marshaler(m json.Marshaler) error {
+	b, err := m.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("pretty: json: %w", err)
+	}
+	var out bytes.Buffer
+	if e.compact {
+		err = json.Compact(&out, b)
+	} else {
+		err = json.Indent(&out, b, e.indentString(e.level), e.indentString(1))
+	}
+	if err != nil {
+		return fmt.Errorf("pretty: json: invalid output of %T: %w", m, err)
+	}
+	e.buf.Write(out.Bytes())
+	return nil
+}
+
+// object encodes a map or struct as a JSON object.
+func (e *jsonEncoder) object(v reflect.Value) error {
+	members := e.members(v, "json")
+	if e.elide() {
+		return nil
+	}
+	e.buf.WriteByte('{')
+	e.level++
+	for i, m := range members {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.buf.WriteString(e.newline(e.level))
+		e.string(m.name)
+		e.buf.WriteByte(':')
+		if !e.compact {
+			e.buf.WriteByte(' ')
+		}
+		if err := e.cycles.within(m.path, func() error { return e.encode(m.v) }); err != nil {
+			return err
+		}
+	}
+	e.level--
+	if len(members) > 0 {
+		e.buf.WriteString(e.newline(e.level))
+	}
+	e.buf.WriteByte('}')
+	return nil
+}
+
+// member is an entry of a map or a field of a struct, encoded as a member of
+// a JSON object or a YAML mapping.
+type member struct {
+	name string
+	v    reflect.Value
+	path pathElem
+}
+
+// members returns the entries of a map, sorted unless maps are unsorted, or
+// the fields of a struct, named by the struct tag with the given key.
+func (s style) members(v reflect.Value, tagKey string) []member {
+	var members []member
+	if v.Kind() == reflect.Map {
+		var keys, values []reflect.Value
+		for it := v.MapRange(); it.Next(); {
+			keys, values = append(keys, it.Key()), append(values, it.Value())
+		}
+		if !s.unsortedMaps {
+			sortKeys(keys, values)
+		}
+		for i, k := range keys {
+			members = append(members, member{jsonKey(k), values[i], pathElem{key: k}})
+		}
+		return members
+	}
+
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := s.fieldName(f, tagKey)
+		if !ok {
+			continue
+		}
+		fv := v.Field(i)
+		if IsRedacted(f) {
+			fv = reflect.ValueOf("***")
+		} else if s, ok := hintString(fv, fieldHint(f)); ok {
+			fv = reflect.ValueOf(s)
+		}
+		members = append(members, member{name, fv, pathElem{field: f.Name}})
+	}
+	return members
+}
+
+// fieldName returns the member name of a struct field, and false if the field
+// is skipped.
+func (s style) fieldName(f reflect.StructField, tagKey string) (string, bool) {
+	if IsSkipped(f) {
+		return "", false
+	}
+	if !f.IsExported() {
+		switch s.unexported {
+		case HideUnexported:
+			return "", false
+		case MarkUnexported:
+			return "~" + f.Name, true
+		}
+	}
+	tag, _, _ := strings.Cut(f.Tag.Get(tagKey), ",")
+	switch {
+	case tag == "-":
+		return "", false
+	case tag == "" && tagKey == "yaml":
+		return strings.ToLower(f.Name), true // like gopkg.in/yaml.v3
+	case tag == "":
+		return f.Name, true
+	}
+	return tag, true
+}
+
+func (e *jsonEncoder) array(v reflect.Value) error {
+	if e.elide() {
+		return nil
+	}
+	e.buf.WriteByte('[')
+	e.level++
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.buf.WriteString(e.newline(e.level))
+		if err := e.cycles.within(pathElem{index: i}, func() error { return e.encode(v.Index(i)) }); err != nil {
+			return err
+		}
+	}
+	e.level--
+	if v.Len() > 0 {
+		e.buf.WriteString(e.newline(e.level))
+	}
+	e.buf.WriteByte(']')
+	return nil
+}
+
+// elide writes "…" and returns true if an object or array is nested deeper
+// than the depth limit.
+func (e *jsonEncoder) elide() bool {
+	if e.limits.MaxDepth <= 0 || e.level < e.limits.MaxDepth {
+		return false
+	}
+	e.string("…")
+	return true
+}
+
+func (e *jsonEncoder) newline(level int) string {
+	if e.compact {
+		return ""
+	}
+	return "\n" + e.indentString(level)
+}
+
+func (e *jsonEncoder) indentString(level int) string {
+	return strings.Repeat(" ", level*e.width())
+}
+
+// float writes f as a number, or as a string if JSON has no number for it.
+func (e *jsonEncoder) float(f float64, bits int) {
+	switch {
+	case math.IsNaN(f):
+		e.string("NaN")
+	case math.IsInf(f, 1):
+		e.string("+Inf")
+	case math.IsInf(f, -1):
+		e.string("-Inf")
+	default:
+		e.buf.WriteString(strconv.FormatFloat(f, 'g', -1, bits))
+	}
+}
+
+// string writes s as a JSON string. Invalid UTF-8 is replaced by U+FFFD.
+func (e *jsonEncoder) string(s string) {
+	const hexDigits = "0123456789abcdef"
+	e.buf.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			e.buf.WriteByte('\\')
+			e.buf.WriteRune(r)
+		case r == '\n':
+			e.buf.WriteString(`\n`)
+		case r == '\r':
+			e.buf.WriteString(`\r`)
+		case r == '\t':
+			e.buf.WriteString(`\t`)
+		case r < 0x20:
+			e.buf.WriteString(`\u00`)
+			e.buf.WriteByte(hexDigits[r>>4])
+			e.buf.WriteByte(hexDigits[r&0xf])
+		case r == utf8.RuneError:
+			e.buf.WriteString(`�`)
+		default:
+			e.buf.WriteRune(r)
+		}
+	}
+	e.buf.WriteByte('"')
+}
+
+// jsonKey returns the member name of a map key: strings as they are, other
+// keys as they are printed.
+func jsonKey(k reflect.Value) string {
+	for k.Kind() == reflect.Interface && !k.IsNil() {
+		k = k.Elem()
+	}
+	switch k.Kind() {
+	case reflect.String:
+		return k.String()
+	case reflect.Bool:
+		return strconv.FormatBool(k.Bool())
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(k.Float(), 'g', -1, k.Type().Bits())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10)
+	}
+	return formatKey(k)
+}
@@ -0,0 +1,116 @@
+package pretty
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+type Config struct {
+	Name    string            `json:"name"`
+	Port    int               `json:"port,omitempty"`
+	Skip    bool              `json:"-"`
+	Ratio   float64           `json:"ratio"`
+	Limits  map[int]string    `json:"limits"`
+	Tags    []string          `json:"tags"`
+	Secret  string            `q:"redact"`
+	Timeout time.Duration     `json:"timeout"`
+	Raw     json.RawMessage   `json:"raw"`
+	Extra   map[string]string `json:"extra"`
+	id      int
+}
+
+func TestJSON(t *testing.T) {
+	c := Config{
+		Name:    "srv",
+		Port:    80,
+		Skip:    true,
+		Ratio:   math.NaN(),
+		Limits:  map[int]string{10: "b", 2: "a"},
+		Secret:  "pw",
+		Timeout: time.Second,
+		Raw:     json.RawMessage(`{"a":[1,2]}`),
+		id:      7,
+	}
+	cases := []struct {
+		opts []Option
+		v    interface{}
+		s    string
+	}{
+		{nil, nil, `null`},
+		{nil, "a\"\n\x01", `"a\"\n\u0001"`},
+		{nil, []float64{1.5, math.Inf(1), math.Inf(-1)}, `[
+    1.5,
+    "+Inf",
+    "-Inf"
+]`},
+		{nil, []byte("hi"), `"aGk="`},
+		{nil, map[interface{}]int{true: 1, "k": 2}, `{
+    "true": 1,
+    "k": 2
+}`},
+		{nil, c, `{
+    "name": "srv",
+    "port": 80,
+    "ratio": "NaN",
+    "limits": {
+        "2": "a",
+        "10": "b"
+    },
+    "tags": null,
+    "Secret": "***",
+    "timeout": "1s",
+    "raw": {
+        "a": [
+            1,
+            2
+        ]
+    },
+    "extra": null,
+    "id": 7
+}`},
+		{[]Option{Compact(true), UnexportedFields(HideUnexported)}, c, `{"name":"srv","port":80,"ratio":"NaN","limits":{"2":"a","10":"b"},"tags":null,"Secret":"***","timeout":"1s","raw":{"a":[1,2]},"extra":null}`},
+		{[]Option{IndentWidth(2), UnexportedFields(MarkUnexported)}, struct{ a []int }{[]int{}}, `{
+  "~a": []
+}`},
+		{[]Option{MaxDepth(1), Compact(true)}, [][]int{{1}}, `["…"]`},
+		{[]Option{Compact(true)}, struct{ F func() }{}, `{"F":"func()"}`},
+	}
+	for _, tt := range cases {
+		b, err := JSON(tt.v, tt.opts...)
+		if err != nil {
+			t.Fatalf("JSON(%#v): %v", tt.v, err)
+		}
+		if s := string(b); s != tt.s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+		}
+		if !json.Valid(b) {
+			t.Errorf("invalid JSON: %s", b)
+		}
+	}
+}
+
+func TestJSONCycle(t *testing.T) {
+	l := list(1, 2)
+	b, err := JSON(l, Compact(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"V":1,"Prev":null,"Next":{"V":2,"Prev":"<cycle to root>","Next":null}}`
+	if string(b) != want {
+		t.Errorf("expected %s", want)
+		t.Errorf("got      %s", b)
+	}
+}
+
+type badMarshaler struct{}
+
+func (badMarshaler) MarshalJSON() ([]byte, error) { return []byte("{"), nil }
+
+func TestJSONError(t *testing.T) {
+	if _, err := JSON([]badMarshaler{{}}); err == nil {
+		t.Fatal("JSON() of invalid MarshalJSON output succeeded")
+	}
+}
@@ -0,0 +1,166 @@
+package pretty
+
+import (
+	"fmt"
+	"strings"
+)
+
+// edit is a step of an edit script turning a sequence a into b: keep the
+// element a[a], which equals b[b], delete a[a] or insert b[b].
+type edit struct {
+	op   byte // ' ', '-' or '+'
+	a, b int  // the positions in a and b
+}
+
+// editScript returns the shortest edit script from a sequence of n elements
+// to one of m elements, of which eq tells whether the elements i of a and j
+// of b are equal. Deletions come before the insertions they are next to.
+func editScript(n, m int, eq func(i, j int) bool) []edit {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case eq(i, j):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && eq(i, j):
+			edits = append(edits, edit{' ', i, j})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', i, j})
+			i++
+		default:
+			edits = append(edits, edit{'+', i, j})
+			j++
+		}
+	}
+	return edits
+}
+
+// lineEdits returns the edit script from the lines a to the lines b, of which
+// only those between their common first and last lines are aligned.
+func lineEdits(a, b []string) []edit {
+	pre, suf := commonLines(a, b)
+	edits := make([]edit, 0, len(a)+len(b)-pre-suf)
+	for i := 0; i < pre; i++ {
+		edits = append(edits, edit{' ', i, i})
+	}
+	mid := editScript(len(a)-pre-suf, len(b)-pre-suf, func(i, j int) bool { return a[pre+i] == b[pre+j] })
+	for _, e := range mid {
+		edits = append(edits, edit{e.op, pre + e.a, pre + e.b})
+	}
+	for k := suf; k > 0; k-- {
+		edits = append(edits, edit{' ', len(a) - k, len(b) - k})
+	}
+	return edits
+}
+
+// lineEditsFit reports whether the lines of a and b between their common
+// first and last lines are few enough for lineEdits to align, see
+// maxEditCells.
+func lineEditsFit(a, b []string) bool {
+	pre, suf := commonLines(a, b)
+	return (len(a)-pre-suf)*(len(b)-pre-suf) <= maxEditCells
+}
+
+// commonLines returns the number of first and of last lines a and b have in
+// common, not counting a line twice.
+func commonLines(a, b []string) (pre, suf int) {
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	return pre, suf
+}
+
+// diffContext is the number of unchanged lines around the changes of
+// unifiedDiff.
+const diffContext = 3
+
+// unifiedDiff returns the lines of a and b that differ in the unified format
+// of diff -u, without the file names: hunks with a @@ header, of the lines
+// removed from a marked with -, the lines of b added marked with +, and up
+// to diffContext unchanged lines around them.
+func unifiedDiff(a, b []string) string {
+	edits := lineEdits(a, b)
+	var sb strings.Builder
+	for start := 0; start < len(edits); {
+		// The next change and the changes close enough to share its hunk.
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for k := first + 1; k < len(edits) && k-last <= 2*diffContext; k++ {
+			if edits[k].op != ' ' {
+				last = k
+			}
+		}
+		from := first - diffContext
+		if from < start {
+			from = start
+		}
+		to := last + diffContext + 1
+		if to > len(edits) {
+			to = len(edits)
+		}
+
+		hunk := edits[from:to]
+		var na, nb int
+		for _, e := range hunk {
+			if e.op != '+' {
+				na++
+			}
+			if e.op != '-' {
+				nb++
+			}
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@", hunkRange(hunk[0].a, na), hunkRange(hunk[0].b, nb))
+		for _, e := range hunk {
+			line := a[e.a:]
+			if e.op == '+' {
+				line = b[e.b:]
+			}
+			sb.WriteString("\n" + string(e.op) + line[0])
+		}
+		start = to
+	}
+	return sb.String()
+}
+
+// hunkRange returns the range of n lines from the index i in a hunk header,
+// e.g. 3,2 for the third and the fourth line.
+func hunkRange(i, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", i)
+	}
+	if n == 1 {
+		return fmt.Sprint(i + 1)
+	}
+	return fmt.Sprintf("%d,%d", i+1, n)
+}
@@ -0,0 +1,70 @@
+package pretty
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(s string) []string { return strings.Split(s, " ") }
+	cases := []struct {
+		a, b string
+		exp  string
+	}{
+		{"a b c", "a x c", "@@ -1,3 +1,3 @@\n a\n-b\n+x\n c"},
+		{"a b c", "a b c d", "@@ -1,3 +1,4 @@\n a\n b\n c\n+d"},
+		{"a b c", "b c", "@@ -1,3 +1,2 @@\n-a\n b\n c"},
+		{"x", "", "@@ -1 +1 @@\n-x\n+"},
+		{
+			"1 2 3 4 5 6 7 8 9 10 11 12 13 14 15",
+			"1 2 3 4 X 6 7 8 9 10 11 12 13 Y 15",
+			"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+X\n 6\n 7\n 8\n" +
+				"@@ -11,5 +11,5 @@\n 11\n 12\n 13\n-14\n+Y\n 15",
+		},
+		{
+			"1 2 3 4 5 6 7 8 9",
+			"1 2 X 4 5 6 Y 8 9",
+			"@@ -1,9 +1,9 @@\n 1\n 2\n-3\n+X\n 4\n 5\n 6\n-7\n+Y\n 8\n 9",
+		},
+	}
+	for _, tt := range cases {
+		if got := unifiedDiff(lines(tt.a), lines(tt.b)); got != tt.exp {
+			t.Errorf("unifiedDiff(%q, %q)", tt.a, tt.b)
+			t.Errorf("expected\n%s", tt.exp)
+			t.Errorf("got\n%s", got)
+		}
+	}
+}
+
+func TestDiffMultiline(t *testing.T) {
+	type page struct{ Body string }
+	a := page{Body: "<html>\n<p>hello</p>\n</html>\n"}
+	b := page{Body: "<html>\n<p>world</p>\n</html>\n"}
+	diffdiff(t, Diff(a, b), []string{
+		"Body: @@ -1,4 +1,4 @@\n <html>\n-<p>hello</p>\n+<p>world</p>\n </html>\n ",
+	})
+}
+
+func TestDiffMultilineLarge(t *testing.T) {
+	const n = 4000
+	a, b, c := make([]string, n), make([]string, n), make([]string, n)
+	for i := range a {
+		a[i] = "line " + strconv.Itoa(i)
+		b[i] = a[i]
+		c[i] = "other " + strconv.Itoa(i)
+	}
+	b[n/2] = "changed"
+
+	// The common first and last lines are not aligned.
+	diffdiff(t, Diff(strings.Join(a, "\n"), strings.Join(b, "\n")), []string{
+		"@@ -1998,7 +1998,7 @@\n line 1997\n line 1998\n line 1999\n-line 2000\n+changed\n line 2001\n line 2002\n line 2003",
+	})
+
+	// Too many lines differ to align them.
+	got := Diff(strings.Join(a, "\n"), strings.Join(c, "\n"))
+	if len(got) != 1 || strings.Contains(got[0], "@@") || !strings.HasPrefix(got[0], `"line 0\nline 1\n`) {
+		t.Errorf("expected %q", `"line 0\nline 1\n…" != "other 0\nother 1\n…"`)
+		t.Errorf("got      %.100q", got)
+	}
+}
@@ -0,0 +1,87 @@
+package pretty
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"unicode/utf8"
+)
+
+// Limits bounds the amount of output produced for a single value. Content
+// beyond a limit is replaced by an elision marker such as …(+1234 more elems).
+// Zero fields mean no limit.
+type Limits struct {
+	MaxDepth     int // nesting levels of maps, structs, arrays and slices
+	MaxElems     int // elements printed per map, array or slice
+	MaxStringLen int // bytes printed per string
+}
+
+// Formatter is like the package-level Formatter, but elides content beyond
+// the limits.
+func (l Limits) Formatter(x interface{}) fmt.Formatter {
+	return formatter{v: reflect.ValueOf(x), quote: true, limits: l, methods: DefaultMethods}
+}
+
+// Sprint is like the package-level Sprint, but elides content beyond the
+// limits.
+func (l Limits) Sprint(a ...interface{}) string {
+	w := make([]interface{}, len(a))
+	for i, x := range a {
+		if f, ok := x.(formatter); ok {
+			// Keep the methods of a value wrapped by Methods.Formatter.
+			f.force, f.limits = true, l
+			w[i] = f
+			continue
+		}
+		w[i] = formatter{v: reflect.ValueOf(x), force: true, limits: l, methods: DefaultMethods}
+	}
+	return fmt.Sprint(w...)
+}
+
+// elideLevel writes {…} and returns true if v is a non-empty map, struct,
+// array or slice nested deeper than the depth limit. The type is written
+// first unless the caller already did, so that elided values stay
+// recognizable.
+func (p *printer) elideLevel(v reflect.Value, showType bool) bool {
+	if p.limits.MaxDepth <= 0 || p.level < p.limits.MaxDepth || !nonzero(v) {
+		return false
+	}
+	if !showType {
+		p.writeType(v.Type())
+	}
+	io.WriteString(p, "{…}")
+	return true
+}
+
+// limitElems returns how many of n elements may be printed.
+func (p *printer) limitElems(n int) int {
+	if p.limits.MaxElems > 0 && n > p.limits.MaxElems {
+		return p.limits.MaxElems
+	}
+	return n
+}
+
+// printMore writes the elision marker for n elements that were not printed,
+// as the last element of the map, array or slice.
+func (p *printer) printMore(n int, expand bool) {
+	if n <= 0 {
+		return
+	}
+	fmt.Fprintf(p, "…(+%d more elems)", n)
+	if expand {
+		writeByte(p, '\n')
+	}
+}
+
+// limitString truncates s to the string length limit, without splitting a
+// UTF-8 sequence, and returns the number of bytes cut off.
+func (p *printer) limitString(s string) (string, int) {
+	max := p.limits.MaxStringLen
+	if max <= 0 || len(s) <= max {
+		return s, 0
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max], len(s) - max
+}
@@ -0,0 +1,86 @@
+package pretty
+
+import (
+	"fmt"
+	"testing"
+)
+
+type tree struct {
+	V    int
+	Kids []tree
+}
+
+type node struct {
+	ID   int
+	Next *node
+}
+
+type graph struct {
+	Name string
+	Node *node
+}
+
+var limittests = []struct {
+	limits Limits
+	v      interface{}
+	s      string
+}{
+	{Limits{MaxElems: 3}, []int{1, 2, 3, 4, 5}, `[]int{1, 2, 3, …(+2 more elems)}`},
+	{Limits{MaxElems: 3}, []int{1, 2, 3}, `[]int{1, 2, 3}`},
+	{Limits{MaxElems: 1}, map[string]int{"a": 1, "b": 2}, `map[string]int{"a":1, …(+1 more elems)}`},
+	{Limits{MaxStringLen: 5}, "hello world", `"hello"…(+6 more bytes)`},
+	{Limits{MaxStringLen: 2}, "héllo", `"h"…(+5 more bytes)`},
+	{Limits{MaxDepth: 1}, map[string][]int{"a": {1}}, `map[string][]int{
+    "a": []int{…},
+}`},
+	{
+		Limits{MaxDepth: 2},
+		tree{V: 1, Kids: []tree{{V: 2, Kids: []tree{{V: 3}}}}},
+		`pretty.tree{
+    V:    1,
+    Kids: {
+        pretty.tree{…},
+    },
+}`,
+	},
+	{
+		Limits{MaxDepth: 1},
+		graph{Name: "g", Node: &node{ID: 1, Next: &node{ID: 2}}},
+		`pretty.graph{
+    Name: "g",
+    Node: &pretty.node{…},
+}`,
+	},
+	{
+		Limits{MaxElems: 1},
+		[]LongStructTypeName{{1, 2}, {3, 4}},
+		`[]pretty.LongStructTypeName{
+    {
+        longFieldName:      int(1),
+        otherLongFieldName: int(2),
+    },
+    …(+1 more elems)
+}`,
+	},
+}
+
+func TestLimits(t *testing.T) {
+	for _, tt := range limittests {
+		s := fmt.Sprintf("%# v", tt.limits.Formatter(tt.v))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+			t.Errorf("expraw\n%s", tt.s)
+			t.Errorf("gotraw\n%s", s)
+		}
+	}
+}
+
+func TestLimitsSprint(t *testing.T) {
+	l := Limits{MaxStringLen: 5, MaxElems: 1}
+	want := `hello…(+6 more bytes) []string{"hello"…(+1 more bytes), …(+1 more elems)}`
+	if got := l.Sprint("hello world", []string{"hello!", "x"}); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+}
@@ -0,0 +1,155 @@
+package pretty
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// Methods selects the methods that print values of the types implementing
+// them, instead of printing their fields. Many domain types, like IDs and
+// addresses, have a better string form than their fields.
+type Methods uint8
+
+const (
+	// UseGoString prints fmt.GoStringer values with GoString().
+	UseGoString Methods = 1 << iota
+	// UseError prints errors with Error(), as their type and quoted message.
+	UseError
+	// UseString prints fmt.Stringer values with String(), as their type and
+	// quoted string.
+	UseString
+)
+
+// DefaultMethods are the methods used by Formatter, Sprint and the other
+// functions of the package.
+const DefaultMethods = UseGoString
+
+// maxMethodDepth bounds the nesting of method calls, e.g. of a String method
+// that pretty-prints its own value again.
+const maxMethodDepth = 8
+
+// methodDepth is the number of method calls in progress of each goroutine, by
+// goroutine id. A nested call comes through a new printer, e.g. of a Sprint
+// within a String method, so the depth can't be kept on the printer.
+var methodDepth = struct {
+	sync.Mutex
+	m map[uint64]int
+}{m: map[uint64]int{}}
+
+// enterMethod counts a method call of the calling goroutine, and returns its
+// id and false if it has too many calls in progress already.
+func enterMethod() (uint64, bool) {
+	id := goroutineID()
+
+	methodDepth.Lock()
+	defer methodDepth.Unlock()
+
+	if methodDepth.m[id] >= maxMethodDepth {
+		return id, false
+	}
+	methodDepth.m[id]++
+	return id, true
+}
+
+// leaveMethod ends a method call counted by enterMethod.
+func leaveMethod(id uint64) {
+	methodDepth.Lock()
+	defer methodDepth.Unlock()
+
+	if methodDepth.m[id]--; methodDepth.m[id] == 0 {
+		delete(methodDepth.m, id)
+	}
+}
+
+// goroutineID returns the id of the calling goroutine, parsed from the first
+// line of its stack trace, e.g. "goroutine 18 [running]:".
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// Formatter is like the package-level Formatter, but prints values with the
+// selected methods.
+func (m Methods) Formatter(x interface{}) fmt.Formatter {
+	return formatter{v: reflect.ValueOf(x), quote: true, methods: m}
+}
+
+// Sprint is like the package-level Sprint, but prints values with the selected
+// methods.
+func (m Methods) Sprint(a ...interface{}) string {
+	w := make([]interface{}, len(a))
+	for i, x := range a {
+		w[i] = formatter{v: reflect.ValueOf(x), force: true, methods: m}
+	}
+	return fmt.Sprint(w...)
+}
+
+// printMethod prints v with the first of the selected methods it implements,
+// and returns false if it implements none of them or the methods are nested
+// too deeply. A panicking method is printed as well, see catchPanic.
+func (p *printer) printMethod(v reflect.Value, showType bool) (printed bool) {
+	if p.methods == 0 || !v.IsValid() || !v.CanInterface() {
+		return false
+	}
+
+	id, ok := enterMethod()
+	if !ok {
+		return false
+	}
+	defer leaveMethod(id)
+
+	switch x := v.Interface().(type) {
+	case fmt.GoStringer:
+		if p.methods&UseGoString != 0 {
+			printed = true
+			defer p.catchPanic(v, "GoString")
+			io.WriteString(p, x.GoString())
+			return
+		}
+	}
+
+	switch x := v.Interface().(type) {
+	case error:
+		if p.methods&UseError != 0 {
+			printed = true
+			defer p.catchPanic(v, "Error")
+			p.printMethodString(v, x.Error(), showType)
+			return
+		}
+	}
+
+	switch x := v.Interface().(type) {
+	case fmt.Stringer:
+		if p.methods&UseString != 0 {
+			printed = true
+			defer p.catchPanic(v, "String")
+			p.printMethodString(v, x.String(), showType)
+			return
+		}
+	}
+
+	return false
+}
+
+// printMethodString prints the result s of a method of v as a quoted string,
+// preceded by the type of v if showType is set, e.g. uuid.UUID("f47ac10b-…").
+func (p *printer) printMethodString(v reflect.Value, s string, showType bool) {
+	if showType {
+		p.writeType(v.Type())
+		writeByte(p, '(')
+	}
+	p.writeColored(StringColor, strconv.Quote(s))
+	if showType {
+		writeByte(p, ')')
+	}
+}
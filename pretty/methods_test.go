@@ -0,0 +1,106 @@
+package pretty
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type ID int
+
+func (id ID) String() string { return fmt.Sprintf("id-%d", int(id)) }
+
+type Node struct {
+	Name string
+}
+
+func (n *Node) String() string { return UseString.Sprint(n) }
+
+var methodtests = []struct {
+	methods Methods
+	v       interface{}
+	s       string
+}{
+	{DefaultMethods, ID(7), `pretty.ID(7)`},
+	{UseString, ID(7), `pretty.ID("id-7")`},
+	{UseString, []ID{1, 2}, `[]pretty.ID{"id-1", "id-2"}`},
+	{UseString, map[string]ID{"a": 1}, `map[string]pretty.ID{"a":"id-1"}`},
+	{UseString, struct{ ID ID }{3}, `struct { ID pretty.ID }{ID:"id-3"}`},
+	{UseError, errors.New("boom"), `*errors.errorString("boom")`},
+	{UseError, struct{ Err error }{errors.New("boom")}, `struct { Err error }{
+    Err: *errors.errorString("boom"),
+}`},
+	{UseError, ID(7), `pretty.ID(7)`},
+	{UseGoString | UseString, &Stringer{}, `*pretty.Stringer("foo")`},
+}
+
+func TestMethods(t *testing.T) {
+	for _, tt := range methodtests {
+		s := fmt.Sprintf("%# v", tt.methods.Formatter(tt.v))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+		}
+	}
+}
+
+func TestMethodsSprint(t *testing.T) {
+	want := `pretty.ID("id-7") []pretty.ID{"id-1"}`
+	if got := UseString.Sprint(ID(7), []ID{1}); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+}
+
+func TestMethodsRecursion(t *testing.T) {
+	// String pretty-prints its own value, which would call String again.
+	s := fmt.Sprintf("%# v", UseString.Formatter(&Node{Name: "n"}))
+	methodDepth.Lock()
+	depth := len(methodDepth.m)
+	methodDepth.Unlock()
+	if s == "" || depth != 0 {
+		t.Errorf("got %q, depth %d", s, depth)
+	}
+}
+
+// Gate is a Stringer whose String waits until gateCalls calls are in
+// progress, or a second has passed.
+type Gate struct{ calls *atomic.Int32 }
+
+const gateCalls = 2 * maxMethodDepth
+
+func (g Gate) String() string {
+	g.calls.Add(1)
+	for deadline := time.Now().Add(time.Second); g.calls.Load() < gateCalls && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	return "gate"
+}
+
+func TestMethodsConcurrent(t *testing.T) {
+	// The depth of each goroutine is bounded, not that of all of them.
+	var (
+		calls atomic.Int32
+		wg    sync.WaitGroup
+	)
+	got := make([]string, gateCalls)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = UseString.Sprint(Gate{&calls})
+		}(i)
+	}
+	wg.Wait()
+
+	want := `pretty.Gate("gate")`
+	for _, s := range got {
+		if s != want {
+			t.Errorf("expected %q", want)
+			t.Errorf("got      %q", s)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package pretty
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Nullable prints the sql.Null types, like sql.NullString, and pointers to
+// booleans, numbers and strings as their value, or null if it isn't set,
+// instead of as a struct or a pointer. Rows read from a database print as
+// e.g. {Name:"ann", Email:null, Age:42}.
+func Nullable(on bool) Option {
+	return func(f *formatter) { f.nullable = on }
+}
+
+// nullValue returns the value v holds, or an invalid value if v is null, and
+// false if v isn't a sql.Null type or a pointer to a scalar.
+func nullValue(v reflect.Value) (reflect.Value, bool) {
+	t := v.Type()
+	switch {
+	case t.Kind() == reflect.Ptr && scalarKind(t.Elem().Kind()):
+		if v.IsNil() {
+			return reflect.Value{}, true
+		}
+		return v.Elem(), true
+	case isSQLNull(t):
+		if !v.Field(1).Bool() {
+			return reflect.Value{}, true
+		}
+		return v.Field(0), true
+	}
+	return reflect.Value{}, false
+}
+
+// isSQLNull reports whether t is one of the sql.Null types, all structs of
+// the value and its Valid flag.
+func isSQLNull(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" &&
+		strings.HasPrefix(t.Name(), "Null") && t.NumField() == 2 &&
+		t.Field(1).Name == "Valid" && t.Field(1).Type.Kind() == reflect.Bool
+}
+
+// scalarKind reports whether values of kind k are booleans, numbers or strings.
+func scalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+// printNull prints v with nullValue, and returns false if v isn't nullable.
+func (p *printer) printNull(v reflect.Value, showType, quote bool) bool {
+	if !p.nullable || p.goSyntax || !v.IsValid() {
+		return false
+	}
+	e, ok := nullValue(v)
+	if !ok {
+		return false
+	}
+	if !e.IsValid() {
+		p.writeColored(NumberColor, "null")
+		return true
+	}
+	p.printValue(e, showType, quote)
+	return true
+}
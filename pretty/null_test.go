@@ -0,0 +1,57 @@
+package pretty
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type DBRow struct {
+	Name    string
+	Email   sql.NullString
+	Age     *int
+	Score   sql.NullFloat64
+	Joined  sql.NullTime
+	Manager *string
+}
+
+func TestNullable(t *testing.T) {
+	age := 42
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	row := DBRow{
+		Name:   "ann",
+		Email:  sql.NullString{String: "ann@example.com", Valid: true},
+		Age:    &age,
+		Score:  sql.NullFloat64{Float64: 1.5},
+		Joined: sql.NullTime{Time: at, Valid: true},
+	}
+	cases := []struct {
+		opts []Option
+		v    interface{}
+		s    string
+	}{
+		{[]Option{Nullable(true)}, row, `pretty.DBRow{
+    Name:    "ann",
+    Email:   "ann@example.com",
+    Age:     42,
+    Score:   null,
+    Joined:  time.Time(2024-01-02T03:04:05Z),
+    Manager: null,
+}`},
+		{[]Option{Nullable(true)}, &age, `int(42)`},
+		{[]Option{Nullable(true), Compact(true)}, []*string{nil}, `[]*string{null}`},
+		{[]Option{Nullable(true)}, sql.NullInt64{Int64: 7, Valid: true}, `int64(7)`},
+		{[]Option{Nullable(true), Compact(true)}, []sql.NullBool{{Bool: true, Valid: true}, {}}, `[]sql.NullBool{true, null}`},
+		{nil, sql.NullInt64{Int64: 7, Valid: true}, `sql.NullInt64{Int64:7, Valid:true}`},
+	}
+	for _, tt := range cases {
+		s := fmt.Sprint(NewFormatter(tt.v, tt.opts...))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+			t.Errorf("expraw\n%s", tt.s)
+			t.Errorf("gotraw\n%s", s)
+		}
+	}
+}
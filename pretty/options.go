@@ -0,0 +1,180 @@
+package pretty
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// defaultIndentWidth is the indentation of nested values unless set by
+// IndentWidth.
+const defaultIndentWidth = 4
+
+// style holds the layout set by the options of NewFormatter. The zero style
+// is the layout of Formatter.
+type style struct {
+	indentWidth  int  // 0 means defaultIndentWidth
+	compact      bool // print everything on one line
+	rawStrings   bool // don't quote strings
+	unsortedMaps bool // print map entries in iteration order
+	unexported   Unexported
+	rawTime      bool // print times like other values, see RawTime
+	rawBig       bool // print big numbers like other structs, see RawBig
+	nullable     bool // print sql.Null types and pointers to scalars as values, see Nullable
+	bytes        BytesFormat
+	maxBytes     int  // bytes printed in hex or base64. 0 means defaultMaxBytes
+	yaml         bool // print YAML instead of Go syntax
+	colors       bool // highlight the syntax with ANSI colors
+	goSyntax     bool // print compilable Go, see GoSyntax
+	tree         bool // print a tree instead of Go syntax
+	html         bool // print HTML, see HTML
+	lineWidth    int  // see Width. 0 means by type, negative never wrap
+}
+
+// width returns the indentation of nested values.
+func (s style) width() int {
+	if s.indentWidth > 0 {
+		return s.indentWidth
+	}
+	return defaultIndentWidth
+}
+
+// Option configures a formatter made by NewFormatter.
+type Option func(f *formatter)
+
+// IndentWidth indents nested values by n spaces instead of 4.
+func IndentWidth(n int) Option {
+	return func(f *formatter) { f.indentWidth = n }
+}
+
+// Compact prints values on a single line, like Go's %#v but with the other
+// options of the formatter applied.
+func Compact(on bool) Option {
+	return func(f *formatter) { f.compact = on }
+}
+
+// QuoteStrings quotes strings with Go syntax, the default. Without quoting,
+// strings are printed as they are, which is easier to read for text but
+// ambiguous for strings containing commas or braces.
+func QuoteStrings(on bool) Option {
+	return func(f *formatter) { f.quote, f.rawStrings = on, !on }
+}
+
+// MaxDepth limits the nesting of printed maps, structs, arrays and slices to
+// n levels, like Limits.MaxDepth.
+func MaxDepth(n int) Option {
+	return func(f *formatter) { f.limits.MaxDepth = n }
+}
+
+// MaxStringLen truncates strings longer than n bytes, followed by
+// …(+1234 more bytes), like Limits.MaxStringLen.
+func MaxStringLen(n int) Option {
+	return func(f *formatter) { f.limits.MaxStringLen = n }
+}
+
+// MaxSliceLen prints at most n elements of each map, array or slice,
+// followed by …(+1234 more elems), like Limits.MaxElems.
+func MaxSliceLen(n int) Option {
+	return func(f *formatter) { f.limits.MaxElems = n }
+}
+
+// Width prints nested values on a single line if they fit in n columns,
+// counting their indentation, and over several lines otherwise. Without it,
+// values are broken into lines by their type: structs and slices of structs
+// always span several lines, for example. Width(0) never breaks lines, like
+// Compact, which keeps each value on one line for grep.
+func Width(n int) Option {
+	return func(f *formatter) {
+		f.lineWidth = n
+		if n <= 0 {
+			f.lineWidth = -1
+		}
+	}
+}
+
+// SortMaps prints map entries sorted by key, the default. Unsorted maps are
+// printed in iteration order, which is faster for huge maps but changes from
+// one call to the next.
+func SortMaps(on bool) Option {
+	return func(f *formatter) { f.unsortedMaps = !on }
+}
+
+// Unexported selects how unexported struct fields are printed.
+type Unexported uint8
+
+const (
+	// ShowUnexported prints unexported fields like the exported ones, the
+	// default.
+	ShowUnexported Unexported = iota
+	// HideUnexported skips unexported fields, printing a value like the
+	// users of its package see it.
+	HideUnexported
+	// MarkUnexported prints unexported fields prefixed with ~, e.g.
+	// {Name:"a", ~id:1}.
+	MarkUnexported
+)
+
+// UnexportedFields selects how unexported struct fields are printed.
+func UnexportedFields(u Unexported) Option {
+	return func(f *formatter) { f.unexported = u }
+}
+
+// hasVisibleFields reports whether any field of the struct type t is printed.
+func (s style) hasVisibleFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if s.visible(t.Field(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// structField is a field of a struct as it is printed.
+type structField struct {
+	f    reflect.StructField
+	name string // marked if unexported, see MarkUnexported
+	v    reflect.Value
+}
+
+// fields returns the printed fields of the struct v. The fields of protobuf
+// messages are named as in the .proto file, see protoFields.
+func (p *printer) fields(v reflect.Value) []structField {
+	if !p.goSyntax && isProto(v.Type()) {
+		return p.protoFields(v)
+	}
+	t := v.Type()
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !p.visible(f) || p.goSyntax && IsRedacted(f) {
+			continue
+		}
+		fields = append(fields, structField{f, p.fieldLabel(f, f.Name), getField(v, i)})
+	}
+	return fields
+}
+
+// fieldLabel returns name, the name of the field f, marked with ~ if f is
+// unexported and unexported fields are marked.
+func (s style) fieldLabel(f reflect.StructField, name string) string {
+	if !f.IsExported() && s.unexported == MarkUnexported {
+		return "~" + name
+	}
+	return name
+}
+
+// visible reports whether the struct field f is printed: it is neither a
+// hidden unexported field nor skipped with a tag.
+func (s style) visible(f reflect.StructField) bool {
+	return (s.unexported != HideUnexported || f.IsExported()) && !IsSkipped(f)
+}
+
+// NewFormatter makes a wrapper, f, that formats x as go source, configured by
+// the options. Unlike Formatter, f pretty-prints x with every verb and flags,
+// e.g. fmt.Sprint(pretty.NewFormatter(x, pretty.Compact(true))).
+func NewFormatter(x interface{}, opts ...Option) fmt.Formatter {
+	f := formatter{v: reflect.ValueOf(x), force: true, quote: true, methods: DefaultMethods}
+	for _, opt := range opts {
+		opt(&f)
+	}
+	return f
+}
@@ -0,0 +1,82 @@
+package pretty
+
+import (
+	"fmt"
+	"testing"
+)
+
+var optiontests = []struct {
+	opts []Option
+	v    interface{}
+	s    string
+}{
+	{nil, "a", `"a"`},
+	{nil, map[string]int{"b": 2, "a": 1}, `map[string]int{"a":1, "b":2}`},
+	{
+		nil,
+		[]T{{1, 2}},
+		`[]pretty.T{
+    {x:1, y:2},
+}`,
+	},
+	{
+		[]Option{IndentWidth(2)},
+		[]T{{1, 2}},
+		`[]pretty.T{
+  {x:1, y:2},
+}`,
+	},
+	{
+		[]Option{IndentWidth(2)},
+		SA{t: &T{1, 2}},
+		`pretty.SA{
+  t: &pretty.T{x:1, y:2},
+  v: pretty.T{},
+}`,
+	},
+	{[]Option{Compact(true)}, []T{{1, 2}}, `[]pretty.T{{x:1, y:2}}`},
+	{[]Option{Compact(true)}, SA{t: &T{1, 2}}, `pretty.SA{t:&pretty.T{x:1, y:2}, v:pretty.T{}}`},
+	{[]Option{QuoteStrings(false)}, "a", `a`},
+	{[]Option{QuoteStrings(false)}, map[string]string{"k": "v"}, `map[string]string{k:v}`},
+	{[]Option{MaxDepth(1), Compact(true)}, [][]int{{1}}, `[][]int{[]int{…}}`},
+	{[]Option{MaxStringLen(3)}, "abcdef", `"abc"…(+3 more bytes)`},
+	{[]Option{MaxSliceLen(2), Compact(true)}, []int{1, 2, 3, 4}, `[]int{1, 2, …(+2 more elems)}`},
+	{[]Option{MaxStringLen(2), MaxSliceLen(2), Compact(true)}, []string{"hello", "world", "!"}, `[]string{"he"…(+3 more bytes), "wo"…(+3 more bytes), …(+1 more elems)}`},
+	{[]Option{SortMaps(false)}, map[string]int{"a": 1}, `map[string]int{"a":1}`},
+	{[]Option{Width(80)}, []T{{1, 2}}, `[]pretty.T{{x:1, y:2}}`},
+	{[]Option{Width(0)}, SA{t: &T{1, 2}}, `pretty.SA{t:&pretty.T{x:1, y:2}, v:pretty.T{}}`},
+	{
+		[]Option{Width(30)},
+		map[string][]string{"a": {"short"}, "b": {"a long string", "that doesn't fit"}},
+		`map[string][]string{
+    "a": {"short"},
+    "b": {
+        "a long string",
+        "that doesn't fit",
+    },
+}`,
+	},
+}
+
+func TestNewFormatter(t *testing.T) {
+	for _, tt := range optiontests {
+		s := fmt.Sprint(NewFormatter(tt.v, tt.opts...))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+			t.Errorf("expraw\n%s", tt.s)
+			t.Errorf("gotraw\n%s", s)
+		}
+	}
+}
+
+func TestSortMaps(t *testing.T) {
+	m := map[int]bool{}
+	for i := 0; i < 100; i++ {
+		m[i] = true
+	}
+	f := NewFormatter(m, SortMaps(false), Compact(true))
+	if s := fmt.Sprint(f); len(s) != len(fmt.Sprint(NewFormatter(m, Compact(true)))) {
+		t.Errorf("unsorted map %q has other entries", s)
+	}
+}
@@ -0,0 +1,170 @@
+package pretty
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// patchOp is an operation of a JSON Patch.
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// DiffPatch returns the differences between a and b as a JSON Patch (RFC
+// 6902): the add, remove and replace operations that turn the encoding of a
+// by JSON into that of b, at the JSON Pointers of the changed members and
+// elements. The options apply to the JSON values, e.g. IgnorePaths to the
+// names of their members, like meta.updated_at.
+func DiffPatch(a, b interface{}, opts ...DiffOpt) ([]byte, error) {
+	ja, err := jsonDocument(a)
+	if err != nil {
+		return nil, err
+	}
+	jb, err := jsonDocument(b)
+	if err != nil {
+		return nil, err
+	}
+	p := patcher{d: diffPrinter{labels: new([]byte), sameDiffers: new(bool), memberKeys: true}, ops: []patchOp{}}
+	for _, opt := range opts {
+		opt(&p.d)
+	}
+	p.diff(p.d, "", ja, jb)
+	return json.Marshal(p.ops)
+}
+
+// jsonDocument returns v encoded by JSON and decoded into maps, slices and
+// scalars.
+func jsonDocument(v interface{}) (interface{}, error) {
+	data, err := JSON(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	err = json.Unmarshal(data, &doc)
+	return doc, err
+}
+
+type patcher struct {
+	d   diffPrinter // compares the values and labels them for IgnorePaths
+	ops []patchOp
+}
+
+// diff adds the operations turning a into b at the JSON Pointer path.
+func (p *patcher) diff(d diffPrinter, path string, a, b interface{}) {
+	if d.ignored() || d.same(reflect.ValueOf(a), reflect.ValueOf(b)) {
+		return
+	}
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			p.diffObjects(d, path, a, b)
+			return
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok && !d.unordered {
+			p.diffArrays(d, path, a, b)
+			return
+		}
+	}
+	if d.included() {
+		p.add("replace", path, b)
+	}
+}
+
+func (p *patcher) diffObjects(d diffPrinter, path string, a, b map[string]interface{}) {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		dk := d.relabel(k)
+		if dk.ignored() {
+			continue
+		}
+		av, inA := a[k]
+		bv, inB := b[k]
+		switch {
+		case !inB:
+			if dk.included() {
+				p.ops = append(p.ops, patchOp{Op: "remove", Path: path + "/" + pointerToken(k)})
+			}
+		case !inA:
+			if dk.included() {
+				p.add("add", path+"/"+pointerToken(k), bv)
+			}
+		default:
+			p.diff(dk, path+"/"+pointerToken(k), av, bv)
+		}
+	}
+}
+
+// diffArrays aligns the elements of a and b like diffSlices. The operations
+// are applied in order, so their indexes are those of the array being
+// patched: elements removed before shift the others down, and elements added
+// shift them up.
+func (p *patcher) diffArrays(d diffPrinter, path string, a, b []interface{}) {
+	edits := editScript(len(a), len(b), func(i, j int) bool {
+		return d.relabel("["+strconv.Itoa(i)+"]").same(reflect.ValueOf(a[i]), reflect.ValueOf(b[j]))
+	})
+	pos := 0 // in the array being patched
+	var removed, inserted []int
+	flush := func() {
+		for k := 0; k < len(removed) || k < len(inserted); k++ {
+			at := path + "/" + strconv.Itoa(pos)
+			switch {
+			case k >= len(inserted):
+				if di := d.relabel("[" + strconv.Itoa(removed[k]) + "]"); !di.ignored() && di.included() {
+					p.ops = append(p.ops, patchOp{Op: "remove", Path: at})
+					continue
+				}
+			case k >= len(removed):
+				if !d.relabel("[" + strconv.Itoa(inserted[k]) + "]").included() {
+					continue // not added, so not at pos
+				}
+				p.add("add", at, b[inserted[k]])
+			default:
+				i := removed[k]
+				p.diff(d.relabel("["+strconv.Itoa(i)+"]"), at, a[i], b[inserted[k]])
+			}
+			pos++
+		}
+		removed, inserted = removed[:0], inserted[:0]
+	}
+	for _, e := range edits {
+		switch e.op {
+		case '-':
+			removed = append(removed, e.a)
+		case '+':
+			inserted = append(inserted, e.b)
+		default:
+			flush()
+			pos++
+		}
+	}
+	flush()
+}
+
+// add adds the operation op of the value v at path.
+func (p *patcher) add(op, path string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data = []byte("null") // decoded from JSON, v encodes
+	}
+	p.ops = append(p.ops, patchOp{Op: op, Path: path, Value: data})
+}
+
+// pointerToken escapes s for a JSON Pointer (RFC 6901).
+func pointerToken(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
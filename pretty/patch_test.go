@@ -0,0 +1,54 @@
+package pretty
+
+import (
+	"regexp"
+	"testing"
+)
+
+type Ticket struct {
+	Title  string            `json:"title"`
+	Tags   []string          `json:"tags"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Meta   Meta              `json:"meta"`
+}
+
+func TestDiffPatch(t *testing.T) {
+	a := Ticket{Title: "bug", Tags: []string{"a", "b", "c"}, Labels: map[string]string{"a/b": "1", "x": "2"}}
+	b := Ticket{Title: "Bug", Tags: []string{"b", "c", "d", "e"}, Labels: map[string]string{"a/b": "1", "y~": "3"}, Meta: Meta{ID: 7}}
+	cases := []struct {
+		a, b interface{}
+		opts []DiffOpt
+		want string
+	}{
+		{a, a, nil, `[]`},
+		{a, b, nil, `[` +
+			`{"op":"remove","path":"/labels/x"},` +
+			`{"op":"add","path":"/labels/y~0","value":"3"},` +
+			`{"op":"replace","path":"/meta/ID","value":7},` +
+			`{"op":"remove","path":"/tags/0"},` +
+			`{"op":"add","path":"/tags/2","value":"d"},` +
+			`{"op":"add","path":"/tags/3","value":"e"},` +
+			`{"op":"replace","path":"/title","value":"Bug"}]`},
+		{a, b, []DiffOpt{IgnorePaths("meta", "labels", "tags")}, `[{"op":"replace","path":"/title","value":"Bug"}]`},
+		{a, b, []DiffOpt{ExcludePath(regexp.MustCompile(`^(labels|meta|tags)$`))}, `[{"op":"replace","path":"/title","value":"Bug"}]`},
+		{a, b, []DiffOpt{FilterPath(regexp.MustCompile(`^tags\[3\]$`))}, `[{"op":"add","path":"/tags/3","value":"e"}]`},
+		{a, b, []DiffOpt{FilterPath(regexp.MustCompile(`^labels\b`))}, `[` +
+			`{"op":"remove","path":"/labels/x"},` +
+			`{"op":"add","path":"/labels/y~0","value":"3"}]`},
+		{[]int{1, 2}, []int{2, 1}, []DiffOpt{UnorderedSlices()}, `[]`},
+		{[]int{1, 2}, []int{2, 3}, []DiffOpt{UnorderedSlices()}, `[{"op":"replace","path":"","value":[2,3]}]`},
+		{[]int{1, 2, 3}, []int{1, 5, 3}, nil, `[{"op":"replace","path":"/1","value":5}]`},
+		{nil, 1, nil, `[{"op":"replace","path":"","value":1}]`},
+		{map[string]interface{}{"a": map[string]int{"": 1}}, map[string]interface{}{"a": map[string]int{"": 2}}, nil, `[{"op":"replace","path":"/a/","value":2}]`},
+	}
+	for _, tt := range cases {
+		got, err := DiffPatch(tt.a, tt.b, tt.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("expected %s", tt.want)
+			t.Errorf("got      %s", got)
+		}
+	}
+}
@@ -102,7 +102,13 @@ func Sprintf(format string, a ...interface{}) string {
 func wrap(a []interface{}, force bool) []interface{} {
 	w := make([]interface{}, len(a))
 	for i, x := range a {
-		w[i] = formatter{v: reflect.ValueOf(x), force: force}
+		if f, ok := x.(formatter); ok {
+			// Keep the methods of a value wrapped by Methods.Formatter.
+			f.force = f.force || force
+			w[i] = f
+			continue
+		}
+		w[i] = formatter{v: reflect.ValueOf(x), force: force, methods: DefaultMethods}
 	}
 	return w
 }
@@ -0,0 +1,105 @@
+package pretty
+
+import (
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Protobuf messages are recognized by the methods of the generated code,
+// without depending on the protobuf module: their fields are printed by the
+// names of the .proto file, without the bookkeeping of the runtime (state,
+// sizeCache, unknownFields and the like), and their enums by the names of
+// their values.
+
+// isProto reports whether t is the struct of a generated protobuf message.
+func isProto(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	pt := reflect.PtrTo(t)
+	_, ok := pt.MethodByName("ProtoReflect")
+	if !ok {
+		_, ok = pt.MethodByName("ProtoMessage")
+	}
+	return ok
+}
+
+// isProtoEnum reports whether t is a generated protobuf enum.
+func isProtoEnum(t reflect.Type) bool {
+	if t.Kind() != reflect.Int32 {
+		return false
+	}
+	_, hasEnum := t.MethodByName("Enum")
+	_, hasString := t.MethodByName("String")
+	return hasEnum && hasString
+}
+
+// protoName returns the name of the field f in the .proto file, and false if
+// f is not a field of the message, e.g. the sizeCache of the runtime.
+func protoName(f reflect.StructField) (string, bool) {
+	if name, ok := f.Tag.Lookup("protobuf_oneof"); ok {
+		return name, true
+	}
+	tag, ok := f.Tag.Lookup("protobuf")
+	if !ok {
+		return "", false
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		if strings.HasPrefix(opt, "name=") {
+			return strings.TrimPrefix(opt, "name="), true
+		}
+	}
+	return f.Name, true
+}
+
+// protoFields returns the fields of the message v. A oneof is printed as its
+// field that is set, and not at all if none is.
+func (p *printer) protoFields(v reflect.Value) []structField {
+	t := v.Type()
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := protoName(f)
+		if !ok || !p.visible(f) {
+			continue
+		}
+		fv := getField(v, i)
+		if _, ok := f.Tag.Lookup("protobuf_oneof"); ok {
+			// The interface holds a pointer to a wrapper of the field.
+			if fv.Kind() != reflect.Ptr || fv.IsNil() || fv.Elem().Kind() != reflect.Struct || fv.Elem().NumField() != 1 {
+				continue
+			}
+			w := fv.Elem()
+			f = w.Type().Field(0)
+			fv = getField(w, 0)
+			if name, ok = protoName(f); !ok {
+				continue
+			}
+		}
+		fields = append(fields, structField{f, p.fieldLabel(f, name), fv})
+	}
+	return fields
+}
+
+// printProtoEnum prints a protobuf enum by the name of its value, e.g.
+// Status(ACTIVE).
+func (p *printer) printProtoEnum(v reflect.Value, showType bool) (printed bool) {
+	if p.goSyntax || !v.IsValid() || !v.CanInterface() || !isProtoEnum(v.Type()) {
+		return false
+	}
+	s, ok := v.Interface().(interface{ String() string })
+	if !ok {
+		return false
+	}
+	defer p.catchPanic(v, "String")
+	if showType {
+		p.writeType(v.Type())
+		writeByte(p, '(')
+	}
+	io.WriteString(p, s.String())
+	if showType {
+		writeByte(p, ')')
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package pretty
+
+import (
+	"fmt"
+	"testing"
+)
+
+// The types below are shaped like the code generated by protoc-gen-go.
+
+type protoImpl struct{ atomicMessageInfo *int }
+
+type Status int32
+
+const (
+	Status_UNKNOWN Status = 0
+	Status_ACTIVE  Status = 1
+)
+
+func (x Status) Enum() *Status { return &x }
+
+func (x Status) String() string {
+	switch x {
+	case Status_UNKNOWN:
+		return "UNKNOWN"
+	case Status_ACTIVE:
+		return "ACTIVE"
+	}
+	return fmt.Sprint(int32(x))
+}
+
+type User struct {
+	state         protoImpl
+	sizeCache     int32
+	unknownFields []byte
+
+	DisplayName string `protobuf:"bytes,1,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Status      Status `protobuf:"varint,2,opt,name=status,proto3,enum=Status" json:"status,omitempty"`
+	// Types that are assignable to Contact:
+	//	*User_Email
+	//	*User_Phone
+	Contact isUser_Contact `protobuf_oneof:"contact"`
+}
+
+func (*User) ProtoMessage() {}
+
+type isUser_Contact interface{ isUser_Contact() }
+
+type User_Email struct {
+	Email string `protobuf:"bytes,3,opt,name=email,proto3,oneof"`
+}
+
+type User_Phone struct {
+	Phone string `protobuf:"bytes,4,opt,name=phone,proto3,oneof"`
+}
+
+func (*User_Email) isUser_Contact() {}
+func (*User_Phone) isUser_Contact() {}
+
+func TestProto(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{
+			&User{sizeCache: 12, DisplayName: "ann", Status: Status_ACTIVE, Contact: &User_Email{Email: "ann@example.com"}},
+			`&pretty.User{display_name:"ann", status:ACTIVE, email:"ann@example.com"}`,
+		},
+		{User{DisplayName: "bob"}, `pretty.User{display_name:"bob", status:UNKNOWN}`},
+		{User{sizeCache: 12}, `pretty.User{display_name:"", status:UNKNOWN}`},
+		{[]Status{Status_ACTIVE, 7}, `[]pretty.Status{ACTIVE, 7}`},
+		{Status_ACTIVE, `pretty.Status(ACTIVE)`},
+	}
+	for _, tt := range cases {
+		if got := fmt.Sprintf("%v", NewFormatter(tt.v, Compact(true))); got != tt.want {
+			t.Errorf("expected %q", tt.want)
+			t.Errorf("got      %q", got)
+		}
+	}
+}
+
+func TestProtoGoSyntax(t *testing.T) {
+	v := User{DisplayName: "ann", Status: Status_ACTIVE}
+	want := `pretty.User{DisplayName:"ann", Status:1, Contact:nil}`
+	if got := fmt.Sprintf("%v", NewFormatter(v, Compact(true), GoSyntax(true))); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+}
+
+func TestProtoTree(t *testing.T) {
+	v := &User{DisplayName: "ann", Contact: &User_Phone{Phone: "555"}}
+	want := `*pretty.User
+├── display_name: "ann"
+├── status: UNKNOWN
+└── phone: "555"`
+	if got := fmt.Sprintf("%v", NewFormatter(v, Tree(true))); got != want {
+		t.Errorf("expected:\n%s", want)
+		t.Errorf("got:\n%s", got)
+	}
+}
@@ -0,0 +1,107 @@
+package pretty
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// typeFormatter is a function registered for a type, see RegisterFormatter.
+type typeFormatter struct {
+	f func(v reflect.Value) string
+	// iface is set if f calls v.Interface(), which panics for values read
+	// from unexported struct fields.
+	iface bool
+}
+
+var (
+	registryMu sync.Mutex // serializes updates of registry
+	// registry maps types to their formatters. It is replaced on every
+	// update, so that printers read it without locking.
+	registry atomic.Pointer[map[reflect.Type]typeFormatter]
+)
+
+// RegisterFormatter makes every formatter of the package print values of type
+// t as the string returned by f, e.g. UUIDs or decimals, which are unreadable
+// as their fields. The string is written as is, also where t is nested in
+// other values. A nil f removes the formatter of t.
+func RegisterFormatter(t reflect.Type, f func(v reflect.Value) string) {
+	register(t, typeFormatter{f: f})
+}
+
+// RegisterFormatterFunc is like RegisterFormatter for the type parameter T,
+// e.g.
+//
+//	pretty.RegisterFormatterFunc(func(d decimal.Decimal) string { return d.String() })
+//
+// Values of T in unexported struct fields are printed as usual, because f
+// can't be called with them.
+func RegisterFormatterFunc[T any](f func(T) string) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if f == nil {
+		register(t, typeFormatter{})
+		return
+	}
+	register(t, typeFormatter{
+		f:     func(v reflect.Value) string { return f(v.Interface().(T)) },
+		iface: true,
+	})
+}
+
+func register(t reflect.Type, tf typeFormatter) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	m := make(map[reflect.Type]typeFormatter)
+	if old := registry.Load(); old != nil {
+		for k, v := range *old {
+			m[k] = v
+		}
+	}
+	if tf.f == nil {
+		delete(m, t)
+	} else {
+		m[t] = tf
+	}
+	registry.Store(&m)
+}
+
+// isRegistered reports whether values of type t are printed by a registered
+// formatter, which prints them on a single line.
+func isRegistered(t reflect.Type) bool {
+	m := registry.Load()
+	if m == nil {
+		return false
+	}
+	_, ok := (*m)[t]
+	return ok
+}
+
+// registered returns the formatter registered for the type of v, if it can
+// be called with v.
+func registered(v reflect.Value) (func(v reflect.Value) string, bool) {
+	m := registry.Load()
+	if m == nil || !v.IsValid() {
+		return nil, false
+	}
+	tf, ok := (*m)[v.Type()]
+	if !ok || tf.iface && !v.CanInterface() {
+		return nil, false
+	}
+	return tf.f, true
+}
+
+// printRegistered prints v with the formatter registered for its type, and
+// returns false if there is none.
+func (p *printer) printRegistered(v reflect.Value) (printed bool) {
+	f, ok := registered(v)
+	if !ok || p.goSyntax {
+		return false
+	}
+
+	printed = true
+	defer p.catchPanic(v, "formatter")
+	io.WriteString(p, f(v))
+	return
+}
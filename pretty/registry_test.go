@@ -0,0 +1,73 @@
+package pretty
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type UUID [4]byte
+
+type Money struct {
+	cents int64
+}
+
+type Order struct {
+	ID    UUID
+	Total *Money
+	price Money
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	RegisterFormatter(reflect.TypeOf(UUID{}), func(v reflect.Value) string {
+		return fmt.Sprintf("%x", v.Interface())
+	})
+	RegisterFormatterFunc(func(m Money) string {
+		return fmt.Sprintf("$%d.%02d", m.cents/100, m.cents%100)
+	})
+	defer RegisterFormatter(reflect.TypeOf(UUID{}), nil)
+	defer RegisterFormatterFunc[Money](nil)
+
+	cases := []test{
+		{UUID{0xde, 0xad, 0xbe, 0xef}, `deadbeef`},
+		{[]UUID{{1, 2, 3, 4}}, `[]pretty.UUID{01020304}`},
+		{Money{1250}, `$12.50`},
+		{
+			Order{ID: UUID{1, 2, 3, 4}, Total: &Money{99}, price: Money{1}},
+			`pretty.Order{
+    ID:    01020304,
+    Total: &$0.99,
+    price: pretty.Money{cents:1},
+}`,
+		},
+	}
+	for _, tt := range cases {
+		s := fmt.Sprintf("%# v", Formatter(tt.v))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+		}
+	}
+}
+
+func TestRegisterFormatterPanic(t *testing.T) {
+	RegisterFormatterFunc(func(m Money) string { panic("boom") })
+	defer RegisterFormatterFunc[Money](nil)
+
+	want := `(pretty.Money)(PANIC=calling method "formatter": boom)`
+	if got := fmt.Sprintf("%# v", Formatter(Money{1})); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+}
+
+func TestUnregisterFormatter(t *testing.T) {
+	RegisterFormatterFunc(func(m Money) string { return "money" })
+	RegisterFormatterFunc[Money](nil)
+
+	want := `pretty.Money{cents:1}`
+	if got := fmt.Sprintf("%# v", Formatter(Money{1})); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+package pretty
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// sortKeys sorts map keys, and the values at the same indexes if values is
+// not nil, so that maps print the same in every run: numbers
+// numerically, strings lexically, false before true, arrays and structs
+// element by element, and interface values by their dynamic type first.
+// Other keys, like pointers and channels, whose addresses change between
+// runs, are sorted by their formatted values.
+func sortKeys(keys, values []reflect.Value) {
+	sort.Stable(entries{keys, values})
+}
+
+// entries sorts map keys with their values, see sortKeys.
+type entries struct {
+	keys, values []reflect.Value
+}
+
+func (e entries) Len() int { return len(e.keys) }
+
+func (e entries) Less(i, j int) bool { return compareKeys(e.keys[i], e.keys[j]) < 0 }
+
+func (e entries) Swap(i, j int) {
+	e.keys[i], e.keys[j] = e.keys[j], e.keys[i]
+	if e.values != nil {
+		e.values[i], e.values[j] = e.values[j], e.values[i]
+	}
+}
+
+// compareKeys returns -1, 0 or 1 as a is less than, equal to or greater than
+// b. The keys have the same type, except for the elements of interface keys.
+func compareKeys(a, b reflect.Value) int {
+	if !a.IsValid() || !b.IsValid() {
+		return cmp.Compare(validity(a), validity(b))
+	}
+	if a.Type() != b.Type() {
+		if c := cmp.Compare(a.Type().String(), b.Type().String()); c != 0 {
+			return c
+		}
+		return compareFormatted(a, b)
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float()) // NaNs first
+	case reflect.Complex64, reflect.Complex128:
+		a, b := a.Complex(), b.Complex()
+		if c := cmp.Compare(real(a), real(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(imag(a), imag(b))
+	case reflect.Bool:
+		return cmp.Compare(boolInt(a.Bool()), boolInt(b.Bool()))
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if c := compareKeys(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if c := compareKeys(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Interface:
+		return compareKeys(a.Elem(), b.Elem())
+	}
+	return compareFormatted(a, b)
+}
+
+// compareFormatted compares the pretty-printed keys, the fallback for keys
+// without a natural order.
+func compareFormatted(a, b reflect.Value) int {
+	return cmp.Compare(formatKey(a), formatKey(b))
+}
+
+func formatKey(v reflect.Value) string {
+	return fmt.Sprint(formatter{v: v, force: true, quote: true, methods: DefaultMethods, style: style{compact: true}})
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// validity orders nil interface keys before the others.
+func validity(v reflect.Value) int {
+	if v.IsValid() {
+		return 1
+	}
+	return 0
+}
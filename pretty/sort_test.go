@@ -0,0 +1,53 @@
+package pretty
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+type key struct {
+	A int
+	B string
+}
+
+func TestSortedMapKeys(t *testing.T) {
+	x, y := &T{x: 2}, &T{x: 1}
+	cases := []test{
+		{map[int]bool{10: true, -1: true, 2: true}, `map[int]bool{-1:true, 2:true, 10:true}`},
+		{map[uint8]int{200: 1, 3: 2}, `map[uint8]int{0x3:2, 0xc8:1}`},
+		{map[string]int{"b": 1, "B": 2, "a": 3}, `map[string]int{"B":2, "a":3, "b":1}`},
+		{map[float64]int{2.5: 1, math.NaN(): 2, -1: 3}, `map[float64]int{NaN:2, -1:3, 2.5:1}`},
+		{map[bool]int{true: 1, false: 2}, `map[bool]int{false:2, true:1}`},
+		{map[[2]int]int{{1, 2}: 1, {0, 9}: 2}, `map[[2]int]int{{0, 9}:2, {1, 2}:1}`},
+		{map[key]int{{1, "b"}: 1, {1, "a"}: 2, {0, "z"}: 3}, `map[pretty.key]int{{A:0, B:"z"}:3, {A:1, B:"a"}:2, {A:1, B:"b"}:1}`},
+		{map[interface{}]int{"a": 1, 2: 2, nil: 3, 1: 4}, `map[interface {}]int{nil:3, 1:4, 2:2, "a":1}`},
+		{map[*T]int{x: 1, y: 2}, `map[*pretty.T]int{&pretty.T{x:1, y:0}:2, &pretty.T{x:2, y:0}:1}`},
+	}
+	for _, tt := range cases {
+		for i := 0; i < 10; i++ { // map iteration order differs between runs
+			s := fmt.Sprintf("%# v", Formatter(tt.v))
+			if tt.s != s {
+				t.Fatalf("expected %q\ngot      %q", tt.s, s)
+			}
+		}
+	}
+}
+
+func TestDiffSortedKeys(t *testing.T) {
+	a := map[int]int{}
+	b := map[int]int{}
+	for i := 0; i < 20; i++ {
+		a[i], b[i] = i, -i
+	}
+	want := Diff(a, b)
+	for i := 0; i < 10; i++ {
+		if got := Diff(a, b); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Fatalf("Diff() differs between calls:\n%s\n%s", got, want)
+		}
+	}
+	if want[0] != "[1]: 1 != -1" {
+		t.Fatalf("Diff()[0] = %q, want %q", want[0], "[1]: 1 != -1")
+	}
+}
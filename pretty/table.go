@@ -0,0 +1,85 @@
+package pretty
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Table writes slice, a slice or array of structs or of pointers to structs,
+// as an aligned text table: a header with the names of the exported fields,
+// and a row per element with the values of the fields, printed like with
+// Compact. Struct tags are honored like by Formatter, and nil pointers are
+// printed as a row of just nil.
+func Table(w io.Writer, slice interface{}) error {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("pretty: Table of %T, want a slice of structs", slice)
+	}
+	t := v.Type().Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("pretty: Table of %s, want a slice of structs", v.Type())
+	}
+
+	s := style{compact: true, unexported: HideUnexported}
+	var fields []int
+	var header []string
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); s.visible(f) {
+			fields = append(fields, i)
+			header = append(header, f.Name)
+		}
+	}
+
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	io.WriteString(tw, strings.Join(header, "\t")+"\n")
+	cells := make([]string, len(fields))
+	for i := 0; i < v.Len(); i++ {
+		e := v.Index(i)
+		if e.Kind() == reflect.Ptr {
+			if e.IsNil() {
+				// All the cells, so that the columns stay aligned.
+				io.WriteString(tw, "nil"+strings.Repeat("\t", len(fields))+"\n")
+				continue
+			}
+			e = e.Elem()
+		}
+		for j, fi := range fields {
+			cells[j] = s.cell(e, fi)
+		}
+		io.WriteString(tw, strings.Join(cells, "\t")+"\n")
+	}
+	tw.Flush()
+	// Without the padding of the empty cells of nil rows.
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		if _, err := io.WriteString(w, strings.TrimRight(line, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cell returns the value of the field i of the struct v, printed like by the
+// struct case of printValue.
+func (s style) cell(v reflect.Value, i int) string {
+	var b strings.Builder
+	p := &printer{Writer: &b, cycles: newCycles(), methods: DefaultMethods, style: s}
+	f := v.Type().Field(i)
+	fv := getField(v, i)
+	if IsRedacted(f) {
+		return "***"
+	}
+	if !p.printHinted(fv, fieldHint(f), false) {
+		p.push(pathElem{field: f.Name})
+		p.printValue(fv, labelType(f.Type), true)
+	}
+	// Escape what would break the row, e.g. the newlines of a GoString.
+	return strings.NewReplacer("\t", `\t`, "\n", `\n`).Replace(b.String())
+}
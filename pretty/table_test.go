@@ -0,0 +1,47 @@
+package pretty
+
+import (
+	"strings"
+	"testing"
+)
+
+type Row struct {
+	ID     int
+	Name   string
+	Tags   []string
+	Secret string `pretty:"redact"`
+	Cache  []int  `pretty:"-"`
+	Mask   uint8  `pretty:"hex"`
+	note   string
+}
+
+func TestTable(t *testing.T) {
+	rows := []*Row{
+		{ID: 1, Name: "alice", Tags: []string{"admin"}, Secret: "pw", Mask: 0xff, note: "x"},
+		nil,
+		{ID: 22, Name: "bob\tby", Cache: []int{1}},
+	}
+	want := `ID   Name       Tags       Secret  Mask
+1    "alice"    {"admin"}  ***     0xff
+nil
+22   "bob\tby"  nil        ***     0x0
+`
+	var b strings.Builder
+	if err := Table(&b, rows); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+		t.Errorf("expraw\n%s", want)
+		t.Errorf("gotraw\n%s", got)
+	}
+}
+
+func TestTableError(t *testing.T) {
+	for _, v := range []interface{}{nil, 1, []int{1}} {
+		if err := Table(&strings.Builder{}, v); err == nil {
+			t.Errorf("Table(%#v) succeeded, want an error", v)
+		}
+	}
+}
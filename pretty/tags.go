@@ -0,0 +1,248 @@
+package pretty
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tagNames are the struct tags read by the formatter. The q tag is honored so
+// that types only need to mention the q package.
+var tagNames = []string{"pretty", "q"}
+
+// hasTagOption reports whether the comma-separated pretty or q tag of the
+// field contains opt, e.g. `pretty:"redact"`.
+func hasTagOption(f reflect.StructField, opt string) bool {
+	for _, name := range tagNames {
+		// Without strings.Split, which would allocate for every field.
+		for tag := f.Tag.Get(name); tag != ""; {
+			var o string
+			o, tag, _ = strings.Cut(tag, ",")
+			if strings.TrimSpace(o) == opt {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsRedacted reports whether the struct field is tagged with `pretty:"redact"`
+// or `q:"redact"`. The values of such fields are printed as ***.
+func IsRedacted(f reflect.StructField) bool {
+	return hasTagOption(f, "redact")
+}
+
+// IsSkipped reports whether the struct field is tagged with `pretty:"-"` or
+// `q:"-"`. Such fields are not printed at all, like with encoding/json.
+func IsSkipped(f reflect.StructField) bool {
+	return hasTagOption(f, "-")
+}
+
+// diffIgnored reports whether the struct field is tagged with
+// `pretty:"diff:-"`, `q:"diff:-"` or `diff:"-"`. Diff leaves such fields,
+// e.g. caches, mutexes and timestamps, out of the comparison.
+func diffIgnored(f reflect.StructField) bool {
+	return hasTagOption(f, "diff:-") || f.Tag.Get("diff") == "-"
+}
+
+// fieldHint returns the tag option that selects how the value of the struct
+// field is printed, or "" if there is none:
+//
+//   - `pretty:"string"` prints it with its String or Error method, or
+//     formatted by package fmt if it has neither, as a quoted string.
+//     Byte slices are printed as text.
+//   - `pretty:"hex"` prints integers in hex, e.g. 0x2a, and bytes in hex,
+//     whatever the Bytes option.
+//
+// Hints that don't apply to the value of the field are ignored.
+func fieldHint(f reflect.StructField) string {
+	for _, h := range []string{"string", "hex"} {
+		if hasTagOption(f, h) {
+			return h
+		}
+	}
+	return ""
+}
+
+// hintString returns the value of a field in the form selected by its hint,
+// and false if the hint doesn't apply to v. A nil value, also within an
+// interface, has no string form, so that it is printed as nil.
+func hintString(v reflect.Value, hint string) (string, bool) {
+	switch hint {
+	case "string":
+		if !v.CanInterface() || isNil(v) || v.Kind() == reflect.Interface && isNil(v.Elem()) {
+			return "", false
+		}
+		switch x := v.Interface().(type) {
+		case error:
+			return x.Error(), true
+		case fmt.Stringer:
+			return x.String(), true
+		case []byte:
+			return string(x), true
+		}
+		return fmt.Sprint(v.Interface()), true
+	case "hex":
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return fmt.Sprintf("%#x", v.Int()), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return fmt.Sprintf("%#x", v.Uint()), true
+		case reflect.Slice, reflect.Array:
+			if v.Type().Elem().Kind() == reflect.Uint8 {
+				return hex.EncodeToString(bytesOf(v)), true
+			}
+		}
+	}
+	return "", false
+}
+
+// isNil reports whether v is nil, e.g. a nil pointer or interface.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Map, reflect.Interface, reflect.Slice, reflect.Ptr, reflect.Chan, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+// printHinted prints the value v of a struct field as its hint asks, see
+// fieldHint, and returns false if the hint doesn't apply to v.
+func (p *printer) printHinted(v reflect.Value, hint string, showType bool) (printed bool) {
+	if hint == "hex" && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) {
+		pp := *p
+		pp.bytes = BytesHex
+		return pp.printBytes(v, showType)
+	}
+	if hint == "string" {
+		// The String and Error methods may panic, see catchPanic.
+		printed = true
+		defer p.catchPanic(v, "String")
+	}
+	s, ok := hintString(v, hint)
+	if !ok {
+		return false
+	}
+	if hint == "string" {
+		p.printMethodString(v, s, showType)
+		return true
+	}
+	if showType {
+		p.writeType(v.Type())
+		writeByte(p, '(')
+	}
+	p.writeColored(NumberColor, s)
+	if showType {
+		writeByte(p, ')')
+	}
+	return true
+}
+
+// HasRedacted reports whether values of type t may contain redacted struct
+// fields, directly or through pointers, slices, arrays, maps and nested
+// structs. Interface values are not followed, see ContainsRedacted.
+func HasRedacted(t reflect.Type) bool {
+	return hasRedacted(t, make(map[reflect.Type]bool))
+}
+
+func hasRedacted(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasRedacted(t.Elem(), seen)
+	case reflect.Map:
+		return hasRedacted(t.Key(), seen) || hasRedacted(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if IsRedacted(f) || hasRedacted(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ContainsRedacted reports whether the value v contains redacted struct
+// fields, like HasRedacted of its type, but also through the values of
+// interfaces, e.g. the elements of a map[string]interface{}.
+func ContainsRedacted(v interface{}) bool {
+	return containsRedacted(reflect.ValueOf(v), make(map[visit]bool))
+}
+
+func containsRedacted(v reflect.Value, seen map[visit]bool) bool {
+	if !v.IsValid() {
+		return false
+	}
+	if !hasInterface(v.Type(), make(map[reflect.Type]bool)) {
+		return HasRedacted(v.Type())
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		return !v.IsNil() && containsRedacted(v.Elem(), seen)
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+		vis := visit{typ: v.Type(), v: v.Pointer()}
+		if seen[vis] {
+			return false
+		}
+		seen[vis] = true
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		return containsRedacted(v.Elem(), seen)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if containsRedacted(v.Index(i), seen) {
+				return true
+			}
+		}
+	case reflect.Map:
+		it := v.MapRange()
+		for it.Next() {
+			if containsRedacted(it.Key(), seen) || containsRedacted(it.Value(), seen) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if IsRedacted(t.Field(i)) || containsRedacted(v.Field(i), seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasInterface reports whether values of type t may hold interfaces, whose
+// values HasRedacted does not know.
+func hasInterface(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasInterface(t.Elem(), seen)
+	case reflect.Map:
+		return hasInterface(t.Key(), seen) || hasInterface(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasInterface(t.Field(i).Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
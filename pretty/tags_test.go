@@ -0,0 +1,136 @@
+package pretty
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Credentials struct {
+	User     string
+	Password string `pretty:"redact"`
+	Token    string `q:"redact"`
+}
+
+type Account struct {
+	ID    int
+	Creds *Credentials
+}
+
+func TestRedact(t *testing.T) {
+	c := Credentials{User: "root", Password: "hunter2", Token: "abc"}
+	want := `pretty.Credentials{User:"root", Password:***, Token:***}`
+	if got := fmt.Sprintf("%# v", Formatter(c)); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+}
+
+func TestHasRedacted(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want bool
+	}{
+		{Credentials{}, true},
+		{[]*Account{}, true},
+		{map[string]Account{}, true},
+		{T{}, false},
+		{[]int{}, false},
+		{S{}, false},
+	}
+	for _, tt := range cases {
+		typ := reflect.TypeOf(tt.v)
+		if got := HasRedacted(typ); got != tt.want {
+			t.Errorf("HasRedacted(%s) = %v want %v", typ, got, tt.want)
+		}
+	}
+}
+
+func TestContainsRedacted(t *testing.T) {
+	c := Credentials{User: "root", Password: "hunter2"}
+	cyclic := []interface{}{nil}
+	cyclic[0] = cyclic
+	cases := []struct {
+		v    interface{}
+		want bool
+	}{
+		{nil, false},
+		{c, true},
+		{map[string]interface{}{"c": c}, true},
+		{[]interface{}{1, &Account{Creds: &c}}, true},
+		{struct{ V interface{} }{[]interface{}{c}}, true},
+		{map[string]interface{}{"a": 1, "b": []interface{}{"x"}}, false},
+		{[]interface{}{T{}}, false},
+		{cyclic, false},
+	}
+	for _, tt := range cases {
+		if got := ContainsRedacted(tt.v); got != tt.want {
+			t.Errorf("ContainsRedacted(%#v) = %v want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+type Packet struct {
+	Src     net.IP `pretty:"string"`
+	Flags   uint16 `pretty:"hex"`
+	Payload []byte `q:"hex"`
+	Err     error  `pretty:"string"`
+	Cache   []int  `pretty:"-"`
+	Name    string `pretty:"hex"`
+}
+
+func TestTagHints(t *testing.T) {
+	p := Packet{
+		Src:     net.IPv4(10, 0, 0, 1),
+		Flags:   0x2a,
+		Payload: []byte("hi"),
+		Err:     errors.New("timeout"),
+		Cache:   []int{1, 2},
+		Name:    "eth0",
+	}
+	want := `pretty.Packet{Src:"10.0.0.1", Flags:0x2a, Payload:hex:6869, Err:*errors.errorString("timeout"), Name:"eth0"}`
+	if got := fmt.Sprint(NewFormatter(p, Compact(true))); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+
+	want = `{"Src":"10.0.0.1","Flags":"0x2a","Payload":"6869","Err":"timeout","Name":"eth0"}`
+	if got, _ := JSON(p, Compact(true)); string(got) != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+
+	// Nil errors and Stringers have no string form.
+	var nilErr *net.OpError
+	for _, err := range []error{nil, nilErr} {
+		p := Packet{Err: err, Name: "eth0"}
+		want := "pretty.Packet{\n    Src:     nil,\n    Flags:   0x0,\n    Payload: nil,\n    Err:     nil,\n    Name:    \"eth0\",\n}"
+		if err != nil {
+			want = strings.Replace(want, "Err:     nil", "Err:     (*net.OpError)(nil)", 1)
+		}
+		if got := Sprint(p); got != want {
+			t.Errorf("expected %q", want)
+			t.Errorf("got      %q", got)
+		}
+
+		want = `{"Src":null,"Flags":"0x0","Payload":"","Err":null,"Name":"eth0"}`
+		if got, _ := JSON(p, Compact(true)); string(got) != want {
+			t.Errorf("expected %q", want)
+			t.Errorf("got      %q", got)
+		}
+	}
+}
+
+func TestSkippedOnly(t *testing.T) {
+	v := struct {
+		A int `q:"-"`
+	}{1}
+	want := `struct { A int "q:\"-\"" }{}`
+	if got := fmt.Sprint(NewFormatter(v)); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+}
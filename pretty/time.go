@@ -0,0 +1,80 @@
+package pretty
+
+import (
+	"reflect"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	locationType = reflect.TypeOf((*time.Location)(nil))
+)
+
+// RawTime prints time.Time, time.Duration and *time.Location values like
+// other values of their kinds, instead of as a timestamp, a duration like
+// 1.5s or the name of the location.
+func RawTime(on bool) Option {
+	return func(f *formatter) { f.rawTime = on }
+}
+
+// formatTime returns the readable form of a time.Time, in RFC 3339 format
+// with nanoseconds, a time.Duration or a *time.Location, and false for
+// values of other types.
+func formatTime(v reflect.Value) (string, bool) {
+	switch v.Type() {
+	case durationType:
+		// Int works for values of unexported fields as well.
+		return time.Duration(v.Int()).String(), true
+	case timeType:
+		if v.CanInterface() {
+			return v.Interface().(time.Time).Format(time.RFC3339Nano), true
+		}
+	case locationType:
+		if v.CanInterface() && !v.IsNil() {
+			return v.Interface().(*time.Location).String(), true
+		}
+	}
+	return "", false
+}
+
+// printTime prints v, or the time.Time v points to, with formatTime, and
+// returns false if v isn't a time.
+func (p *printer) printTime(v reflect.Value, showType bool) bool {
+	if p.rawTime || !v.IsValid() {
+		return false
+	}
+	if p.goSyntax {
+		if v.Kind() == reflect.Ptr && v.Type().Elem() == timeType && !v.IsNil() {
+			// Before the GoString method of *time.Time, which isn't Go syntax
+			// for most locations.
+			p.printGoPointer(v.Elem())
+			return true
+		}
+		s, ok := goTime(v)
+		if ok {
+			p.writeColored(NumberColor, s)
+		}
+		return ok
+	}
+	ptr := v.Kind() == reflect.Ptr && v.Type().Elem() == timeType && !v.IsNil()
+	if ptr {
+		v, showType = v.Elem(), true
+	}
+	s, ok := formatTime(v)
+	if !ok {
+		return false
+	}
+	if ptr {
+		writeByte(p, '&')
+	}
+	if showType {
+		p.writeType(v.Type())
+		writeByte(p, '(')
+	}
+	p.writeColored(NumberColor, s)
+	if showType {
+		writeByte(p, ')')
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package pretty
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type Event struct {
+	At    time.Time
+	Took  time.Duration
+	Zone  *time.Location
+	Until *time.Time
+	every time.Duration
+}
+
+func TestTime(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	ev := Event{At: at, Took: 1500 * time.Millisecond, Zone: time.UTC, Until: &at, every: time.Minute}
+	cases := []struct {
+		opts []Option
+		v    interface{}
+		s    string
+	}{
+		{nil, at, `time.Time(2024-01-02T03:04:05.000000006Z)`},
+		{nil, time.Second, `time.Duration(1s)`},
+		{nil, time.UTC, `*time.Location(UTC)`},
+		{nil, &at, `&time.Time(2024-01-02T03:04:05.000000006Z)`},
+		{nil, (*time.Location)(nil), `(*time.Location)(nil)`},
+		{nil, ev, `pretty.Event{
+    At:    time.Time(2024-01-02T03:04:05.000000006Z),
+    Took:  1.5s,
+    Zone:  UTC,
+    Until: &time.Time(2024-01-02T03:04:05.000000006Z),
+    every: 1m0s,
+}`},
+		{nil, []time.Duration{time.Second, time.Hour}, `[]time.Duration{1s, 1h0m0s}`},
+		{[]Option{RawTime(true)}, time.Second, `time.Duration(1000000000)`},
+		{[]Option{RawTime(true)}, at, `time.Date(2024, time.January, 2, 3, 4, 5, 6, time.UTC)`},
+	}
+	for _, tt := range cases {
+		s := fmt.Sprint(NewFormatter(tt.v, tt.opts...))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+			t.Errorf("expraw\n%s", tt.s)
+			t.Errorf("gotraw\n%s", s)
+		}
+	}
+}
+
+func TestDiffTime(t *testing.T) {
+	a := Event{At: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Took: time.Second}
+	b := Event{At: a.At.Add(time.Hour), Took: 2 * time.Second}
+	want := []string{
+		"At: 2024-01-02T03:04:05Z != 2024-01-02T04:04:05Z",
+		"Took: 1s != 2s",
+	}
+	diffdiff(t, Diff(a, b), want)
+
+	type zone struct{ Z *time.Location }
+	diffdiff(t, Diff(zone{time.UTC}, zone{nil}), []string{"Z: *time.Location(UTC) != nil"})
+	diffdiff(t, Diff(zone{nil}, zone{time.UTC}), []string{"Z: nil != *time.Location(UTC)"})
+}
@@ -0,0 +1,224 @@
+package pretty
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// Tree prints values as a tree instead of Go syntax: every field, map entry
+// and element on a line of its own below its parent, joined by box-drawing
+// branch guides. This is easier to navigate than nested braces for deeply
+// nested values, like configs or a map[string]interface{} decoded from JSON.
+//
+//	pretty.Config
+//	├── Name: "api"
+//	└── Ports: []int
+//	    ├── [0]: 80
+//	    └── [1]: 443
+func Tree(on bool) Option {
+	return func(f *formatter) { f.tree = on }
+}
+
+// treeNode is a child of a branch of the tree, printed as "label: value".
+type treeNode struct {
+	field string        // struct field name, see structField
+	key   reflect.Value // valid for map keys
+	index int
+	v     reflect.Value
+	f     *reflect.StructField // of struct fields, for their tags
+}
+
+// writeTree writes v as a tree, without the trailing newline.
+func (fo formatter) writeTree(w io.Writer) {
+	p := &printer{Writer: w, cycles: newCycles(), limits: fo.limits, methods: fo.methods, style: fo.style}
+	p.compact = true // leaves are printed on their line
+	p.printTreeNode(fo.v, "", true)
+}
+
+// printTreeNode prints v after its label, followed by its children if it is a
+// branch. prefix holds the guides of the ancestors of the children.
+func (p *printer) printTreeNode(v reflect.Value, prefix string, showType bool) {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	e := v
+	if e.Kind() == reflect.Ptr && !e.IsNil() && !p.treeLeaf(e) {
+		e = e.Elem()
+	}
+	if err, ok := wrappingError(v); ok && !p.hasMethod(v) {
+		// A branch of its own, with the guides of the tree.
+		pp := *p
+		pp.compact = false
+		pp.printErrorChain(err, prefix, 0)
+		return
+	}
+	if p.treeLeaf(e) || p.limits.MaxDepth > 0 && p.level >= p.limits.MaxDepth {
+		// With the type of empty maps, structs and slices, which are
+		// ambiguous on their own.
+		showType = showType || v.IsValid() && isComposite(v.Type())
+		p.printValue(v, showType, true)
+		return
+	}
+
+	var vis visit
+	switch {
+	case v.Kind() == reflect.Ptr:
+		vis = visit{typ: e.Type(), v: v.Pointer()}
+	case v.Kind() == reflect.Map || v.Kind() == reflect.Slice:
+		vis = visit{typ: v.Type(), v: v.Pointer()}
+	}
+	if vis.typ != nil {
+		if p.cycle(vis) {
+			return
+		}
+		p.enter(vis)
+		defer p.leave(vis)
+	}
+
+	p.writeType(v.Type())
+	p.level++
+	defer func() { p.level-- }()
+	nodes := p.treeNodes(e)
+	n := p.limitElems(len(nodes))
+	if e.Kind() == reflect.Struct {
+		n = len(nodes) // the limit is for elements
+	}
+	for i, c := range nodes[:n] {
+		guide, next := "├── ", "│   "
+		if i == len(nodes)-1 {
+			guide, next = "└── ", "    "
+		}
+		io.WriteString(p, "\n"+prefix+guide)
+		p.printTreeChild(c, prefix+next)
+	}
+	if n < len(nodes) {
+		fmt.Fprintf(p, "\n%s└── …(+%d more elems)", prefix, len(nodes)-n)
+	}
+}
+
+// printTreeChild prints the label and the value of a child.
+func (p *printer) printTreeChild(c treeNode, prefix string) {
+	p.printTreeLabel(c)
+	if c.f != nil && IsRedacted(*c.f) {
+		io.WriteString(p, "***")
+		return
+	}
+	if c.f != nil && p.printHinted(c.v, fieldHint(*c.f), false) {
+		return
+	}
+	p.push(c.step())
+	p.printTreeNode(c.v, prefix, false)
+	p.pop()
+}
+
+// printTreeLabel prints the label of a child followed by a colon.
+func (p *printer) printTreeLabel(c treeNode) {
+	switch {
+	case c.f != nil:
+		p.writeColored(FieldColor, c.field)
+	case c.key.IsValid():
+		p.printValue(c.key, false, true)
+	default:
+		io.WriteString(p, "["+strconv.Itoa(c.index)+"]")
+	}
+	io.WriteString(p, ": ")
+}
+
+// step returns the step from the branch to the child.
+func (c treeNode) step() pathElem {
+	switch {
+	case c.f != nil:
+		return pathElem{field: c.f.Name}
+	case c.key.IsValid():
+		return pathElem{key: c.key}
+	}
+	return pathElem{index: c.index}
+}
+
+// treeNodes returns the children of the branch v.
+func (p *printer) treeNodes(v reflect.Value) []treeNode {
+	var nodes []treeNode
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, sf := range p.fields(v) {
+			f := sf.f
+			nodes = append(nodes, treeNode{field: sf.name, v: sf.v, f: &f})
+		}
+	case reflect.Map:
+		keys, values := p.mapEntries(v)
+		for i, k := range keys {
+			nodes = append(nodes, treeNode{key: k, v: values[i]})
+		}
+	default:
+		for i := 0; i < v.Len(); i++ {
+			nodes = append(nodes, treeNode{index: i, v: v.Index(i)})
+		}
+	}
+	return nodes
+}
+
+// treeLeaf reports whether v is printed on a single line: it isn't a
+// non-empty map, struct, array or slice, or it is printed by other means than
+// its elements, like a time, bytes or a value with a method.
+func (p *printer) treeLeaf(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	if _, ok := registered(v); ok {
+		return true
+	}
+	if _, ok := formatTime(v); ok && !p.rawTime {
+		return true
+	}
+	if _, ok := formatBig(v); ok && !p.rawBig {
+		return true
+	}
+	if _, ok := nullValue(v); ok && p.nullable {
+		return true
+	}
+	if p.hasMethod(v) {
+		return true
+	}
+	if _, ok := wrappingError(v); ok {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		return v.IsNil() || v.Elem().Kind() == reflect.Ptr || p.treeLeaf(v.Elem())
+	case reflect.Struct:
+		return len(p.fields(v)) == 0
+	case reflect.Map:
+		return v.Len() == 0
+	case reflect.Slice, reflect.Array:
+		isBytes := p.bytes != BytesList && v.Type().Elem().Kind() == reflect.Uint8
+		return v.Len() == 0 || isBytes
+	}
+	return true
+}
+
+// hasMethod reports whether v is printed by one of the selected methods, see
+// printMethod.
+func (p *printer) hasMethod(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	switch v.Interface().(type) {
+	case fmt.GoStringer:
+		if p.methods&UseGoString != 0 {
+			return true
+		}
+	}
+	switch v.Interface().(type) {
+	case error:
+		if p.methods&UseError != 0 {
+			return true
+		}
+	}
+	switch v.Interface().(type) {
+	case fmt.Stringer:
+		return p.methods&UseString != 0
+	}
+	return false
+}
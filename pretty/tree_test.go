@@ -0,0 +1,81 @@
+package pretty
+
+import (
+	"fmt"
+	"testing"
+)
+
+var treetests = []struct {
+	opts []Option
+	v    interface{}
+	s    string
+}{
+	{nil, 3, `int(3)`},
+	{
+		nil,
+		map[string]interface{}{
+			"name": "api",
+			"db":   map[string]interface{}{"host": "localhost", "ports": []interface{}{5432.0, 5433.0}},
+			"tags": []string{},
+			"nil":  nil,
+		},
+		`map[string]interface {}
+├── "db": map[string]interface {}
+│   ├── "host": "localhost"
+│   └── "ports": []interface {}
+│       ├── [0]: 5432
+│       └── [1]: 5433
+├── "name": "api"
+├── "nil": nil
+└── "tags": []string{}`,
+	},
+	{
+		nil,
+		list(1, 2),
+		`*pretty.DNode
+├── V: 1
+├── Prev: (*pretty.DNode)(nil)
+└── Next: *pretty.DNode
+    ├── V: 2
+    ├── Prev: <cycle to root>
+    └── Next: (*pretty.DNode)(nil)`,
+	},
+	{
+		nil,
+		Account{ID: 1, Creds: &Credentials{User: "u", Password: "x"}},
+		`pretty.Account
+├── ID: 1
+└── Creds: *pretty.Credentials
+    ├── User: "u"
+    ├── Password: ***
+    └── Token: ***`,
+	},
+	{
+		[]Option{MaxSliceLen(2)},
+		[][]int{{1}, {2}, {3}},
+		`[][]int
+├── [0]: []int
+│   └── [0]: 1
+├── [1]: []int
+│   └── [0]: 2
+└── …(+1 more elems)`,
+	},
+	{
+		[]Option{MaxDepth(1)},
+		map[string][]int{"a": {1}},
+		`map[string][]int
+└── "a": []int{…}`,
+	},
+}
+
+func TestTree(t *testing.T) {
+	for _, tt := range treetests {
+		s := fmt.Sprint(NewFormatter(tt.v, append(tt.opts, Tree(true))...))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+			t.Errorf("expraw\n%s", tt.s)
+			t.Errorf("gotraw\n%s", s)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package pretty
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Printer pretty-prints values of the type parameter T, configured once by the
+// options of NewPrinter, e.g.
+//
+//	var configs = pretty.NewPrinter[Config](pretty.Compact(true))
+//	...
+//	log.Print(configs.Sprint(cfg))
+//
+// Unlike Sprint and NewFormatter, the type of the values is checked at
+// compile time, and they aren't converted to interface{} on the way: values
+// are printed straight from a pointer to them, without the fmt machinery.
+type Printer[T any] struct {
+	f formatter
+}
+
+// NewPrinter makes a Printer of values of T, configured by the options like
+// NewFormatter.
+func NewPrinter[T any](opts ...Option) Printer[T] {
+	f := formatter{force: true, quote: true, methods: DefaultMethods}
+	for _, opt := range opts {
+		opt(&f)
+	}
+	return Printer[T]{f: f}
+}
+
+// Sprint returns x pretty-printed, like Sprint.
+func (p Printer[T]) Sprint(x T) string {
+	var b strings.Builder
+	p.write(&b, &x)
+	return b.String()
+}
+
+// Fprint writes x pretty-printed to w.
+func (p Printer[T]) Fprint(w io.Writer, x T) error {
+	// Written at once, like by fmt.
+	var b strings.Builder
+	p.write(&b, &x)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Print writes x pretty-printed to standard output.
+func (p Printer[T]) Print(x T) error {
+	return p.Fprint(os.Stdout, x)
+}
+
+func (p Printer[T]) write(w io.Writer, x *T) {
+	f := p.f
+	f.v = reflect.ValueOf(x).Elem()
+	if f.v.Kind() == reflect.Interface {
+		// The dynamic value, as if x had been passed as an interface{}.
+		f.v = f.v.Elem()
+	}
+	f.write(w)
+}
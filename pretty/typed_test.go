@@ -0,0 +1,43 @@
+package pretty
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPrinter(t *testing.T) {
+	cfg := Service{Name: "api", Ports: []int{80, 443}}
+	if got, want := NewPrinter[Service]().Sprint(cfg), fmt.Sprint(NewFormatter(cfg)); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+	if got, want := NewPrinter[*Service](Compact(true)).Sprint(&cfg), fmt.Sprint(NewFormatter(&cfg, Compact(true))); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+	if got, want := NewPrinter[time.Duration]().Sprint(time.Second), `time.Duration(1s)`; got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+	// Interfaces are printed as their dynamic value.
+	if got, want := NewPrinter[error]().Sprint(errors.New("x")), `&errors.errorString{s:"x"}`; got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+	if got, want := NewPrinter[error]().Sprint(nil), `nil`; got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+
+	var b bytes.Buffer
+	if err := NewPrinter[[]string](YAML(true)).Fprint(&b, []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "- a\n- b"; got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+}
@@ -0,0 +1,96 @@
+package pretty
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type inner struct {
+	secret string
+	ID     ID
+}
+
+type Outer struct {
+	Name  string
+	id    int
+	any   interface{}
+	err   error
+	inner *inner
+	Pub   interface{}
+}
+
+type hidden struct {
+	a, b []int
+}
+
+func TestUnexportedFields(t *testing.T) {
+	o := Outer{
+		Name:  "o",
+		id:    1,
+		any:   inner{secret: "s", ID: 2},
+		err:   errors.New("e"),
+		inner: &inner{secret: "t"},
+		Pub:   &inner{secret: "u"},
+	}
+	cases := []struct {
+		u Unexported
+		v interface{}
+		s string
+	}{
+		{ShowUnexported, o, `pretty.Outer{
+    Name:  "o",
+    id:    1,
+    any:   pretty.inner{secret:"s", ID:2},
+    err:   &errors.errorString{s:"e"},
+    inner: &pretty.inner{secret:"t", ID:0},
+    Pub:   &pretty.inner{secret:"u", ID:0},
+}`},
+		{HideUnexported, o, `pretty.Outer{
+    Name: "o",
+    Pub:  &pretty.inner{ID:0},
+}`},
+		{MarkUnexported, o, `pretty.Outer{
+    Name:   "o",
+    ~id:    1,
+    ~any:   pretty.inner{~secret:"s", ID:2},
+    ~err:   &errors.errorString{~s:"e"},
+    ~inner: &pretty.inner{~secret:"t", ID:0},
+    Pub:    &pretty.inner{~secret:"u", ID:0},
+}`},
+		{HideUnexported, inner{secret: "s", ID: 1}, `pretty.inner{ID:1}`},
+		{HideUnexported, hidden{a: []int{1}}, `pretty.hidden{}`},
+		{MarkUnexported, hidden{a: []int{1}}, `pretty.hidden{
+    ~a: {1},
+    ~b: nil,
+}`},
+	}
+	for _, tt := range cases {
+		s := fmt.Sprint(NewFormatter(tt.v, UnexportedFields(tt.u)))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+			t.Errorf("expraw\n%s", tt.s)
+			t.Errorf("gotraw\n%s", s)
+		}
+	}
+}
+
+func TestUnexportedMethods(t *testing.T) {
+	// Methods can't be called on values of unexported fields, even when
+	// they are reached through an interface, so they are printed as usual.
+	v := struct {
+		id  ID
+		any interface{}
+		ID  ID
+	}{1, ID(2), 3}
+	want := `struct { id pretty.ID; any interface {}; ID pretty.ID }{
+    id:  1,
+    any: pretty.ID(2),
+    ID:  "id-3",
+}`
+	if got := fmt.Sprintf("%# v", UseString.Formatter(v)); got != want {
+		t.Errorf("expected %q", want)
+		t.Errorf("got      %q", got)
+	}
+}
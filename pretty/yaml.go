@@ -0,0 +1,288 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// defaultYAMLIndent is the indentation of nested YAML blocks unless set by
+// IndentWidth.
+const defaultYAMLIndent = 2
+
+// YAML prints values as YAML instead of Go syntax, which reads better for
+// deeply nested, config-like values and can be pasted into fixtures. Struct
+// fields are named like in gopkg.in/yaml.v3: by their yaml tags, or else by
+// their lowercased names.
+func YAML(on bool) Option {
+	return func(f *formatter) { f.yaml = on }
+}
+
+type yamlEncoder struct {
+	buf bytes.Buffer
+	style
+	limits Limits
+	cycles *cycles
+	level  int // nesting level of mappings and sequences
+}
+
+// writeYAML writes v as a YAML document, without the trailing newline.
+func (fo formatter) writeYAML(w io.Writer) {
+	e := yamlEncoder{style: fo.style, limits: fo.limits, cycles: newCycles()}
+	if fo.indentWidth == 0 {
+		e.indentWidth = defaultYAMLIndent
+	}
+	e.document(fo.v)
+	w.Write(bytes.TrimSuffix(e.buf.Bytes(), []byte{'\n'}))
+}
+
+// document writes a top-level value.
+func (e *yamlEncoder) document(v reflect.Value) {
+	v, cycle := e.deref(v)
+	if cycle != "" {
+		e.buf.WriteString(cycle)
+		return
+	}
+	e.level++
+	switch {
+	case e.isMapping(v) && e.nonEmpty(v):
+		e.visit(v, func() { e.mapping(v, 0, false) })
+	case e.isSequence(v) && v.Len() > 0:
+		e.visit(v, func() { e.sequence(v, 0, false) })
+	default:
+		e.buf.WriteString(e.scalar(v))
+	}
+}
+
+// node writes v after a "key:" or "-" at column col. Mappings following a
+// dash start on the same line, other blocks on the next one.
+func (e *yamlEncoder) node(v reflect.Value, col int, afterDash bool) {
+	v, cycle := e.deref(v)
+	if cycle != "" {
+		e.buf.WriteString(" " + cycle + "\n")
+		return
+	}
+	block := e.isMapping(v) && e.nonEmpty(v) || e.isSequence(v) && v.Len() > 0
+	if !block {
+		e.buf.WriteString(" " + e.scalar(v) + "\n")
+		return
+	}
+	if e.limits.MaxDepth > 0 && e.level >= e.limits.MaxDepth {
+		e.buf.WriteString(" …\n")
+		return
+	}
+
+	e.visit(v, func() {
+		e.level++
+		defer func() { e.level-- }()
+		switch {
+		case afterDash:
+			e.buf.WriteByte(' ')
+			if e.isMapping(v) {
+				e.mapping(v, col+2, true)
+			} else {
+				e.sequence(v, col+2, true)
+			}
+		case e.isMapping(v):
+			e.buf.WriteByte('\n')
+			e.mapping(v, col+e.width(), false)
+		default:
+			e.buf.WriteByte('\n')
+			e.sequence(v, col+e.width(), false)
+		}
+	})
+}
+
+// visit calls f unless v, a map, slice or the target of a pointer, is an
+// ancestor, which is written as <cycle to path>.
+func (e *yamlEncoder) visit(v reflect.Value, f func()) {
+	if !v.CanAddr() && v.Kind() != reflect.Map && v.Kind() != reflect.Slice {
+		f()
+		return
+	}
+	var vis visit
+	if v.Kind() == reflect.Map || v.Kind() == reflect.Slice {
+		vis = visit{typ: v.Type(), v: v.Pointer()}
+	} else {
+		vis = visit{typ: v.Type(), v: v.UnsafeAddr()}
+	}
+	_ = e.cycles.walk(vis, func(path string) {
+		e.buf.WriteString("<cycle to " + path + ">\n")
+	}, func() error { f(); return nil })
+}
+
+// deref follows pointers and interfaces to the value they hold. A pointer to
+// an ancestor returns its cycle marker.
+func (e *yamlEncoder) deref(v reflect.Value) (reflect.Value, string) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		if _, ok := registered(v); ok {
+			break
+		}
+		if _, ok := formatTime(v); ok && !e.rawTime {
+			break
+		}
+		if v.Kind() == reflect.Ptr {
+			if n, ok := e.cycles.visited[visit{typ: v.Type().Elem(), v: v.Pointer()}]; ok {
+				return v, e.quote("<cycle to " + formatPath(e.cycles.path[:n]) + ">")
+			}
+		}
+		v = v.Elem()
+	}
+	return v, ""
+}
+
+func (e *yamlEncoder) mapping(v reflect.Value, col int, inline bool) {
+	for i, m := range e.members(v, "yaml") {
+		if i > 0 || !inline {
+			e.buf.WriteString(strings.Repeat(" ", col))
+		}
+		e.buf.WriteString(e.quote(m.name))
+		e.buf.WriteByte(':')
+		_ = e.cycles.within(m.path, func() error { e.node(m.v, col, false); return nil })
+	}
+}
+
+func (e *yamlEncoder) sequence(v reflect.Value, col int, inline bool) {
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 || !inline {
+			e.buf.WriteString(strings.Repeat(" ", col))
+		}
+		e.buf.WriteByte('-')
+		_ = e.cycles.within(pathElem{index: i}, func() error { e.node(v.Index(i), col, true); return nil })
+	}
+}
+
+func (e *yamlEncoder) isMapping(v reflect.Value) bool {
+	if !v.IsValid() || e.isScalar(v) {
+		return false
+	}
+	return v.Kind() == reflect.Map || v.Kind() == reflect.Struct
+}
+
+func (e *yamlEncoder) isSequence(v reflect.Value) bool {
+	if !v.IsValid() || e.isScalar(v) {
+		return false
+	}
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+}
+
+// isScalar reports whether v is printed as a single value although it is a
+// composite value, e.g. a time.Time or a []byte.
+func (e *yamlEncoder) isScalar(v reflect.Value) bool {
+	if _, ok := registered(v); ok {
+		return true
+	}
+	if _, ok := formatTime(v); ok && !e.rawTime {
+		return true
+	}
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() == reflect.Uint8
+}
+
+// nonEmpty reports whether the map or struct v has members.
+func (e *yamlEncoder) nonEmpty(v reflect.Value) bool {
+	if v.Kind() == reflect.Map {
+		return v.Len() > 0
+	}
+	return e.hasVisibleFields(v.Type())
+}
+
+// scalar returns v, which is not a block, as a YAML scalar.
+func (e *yamlEncoder) scalar(v reflect.Value) string {
+	if !v.IsValid() {
+		return "null"
+	}
+	if f, ok := registered(v); ok {
+		return e.quote(f(v))
+	}
+	if !e.rawTime {
+		if s, ok := formatTime(v); ok {
+			return e.quote(s)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		switch f := v.Float(); {
+		case math.IsNaN(f):
+			return ".nan"
+		case math.IsInf(f, 1):
+			return ".inf"
+		case math.IsInf(f, -1):
+			return "-.inf"
+		default:
+			return strconv.FormatFloat(f, 'g', -1, v.Type().Bits())
+		}
+	case reflect.Complex64, reflect.Complex128:
+		return e.quote(strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()))
+	case reflect.String:
+		return e.quote(v.String())
+	case reflect.Ptr, reflect.Interface:
+		return "null" // nil, see deref
+	case reflect.Map:
+		if v.IsNil() {
+			return "null"
+		}
+		return "{}"
+	case reflect.Struct:
+		return "{}"
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return "null"
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Len() > 0 {
+			if b := bytesOf(v); !isText(b) {
+				return "!!binary " + base64.StdEncoding.EncodeToString(b)
+			}
+			return e.quote(string(bytesOf(v)))
+		}
+		return "[]"
+	}
+	return e.quote(v.Type().String())
+}
+
+// quote returns s as a plain scalar if YAML reads it back as the same
+// string, and as a double-quoted scalar otherwise.
+func (e *yamlEncoder) quote(s string) string {
+	if s == "" || !plainSafe(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// plainSafe reports whether s can be written as a plain YAML scalar: it must
+// not look like another type, start with an indicator character or contain
+// characters that end or comment the scalar.
+func plainSafe(s string) bool {
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n", ".nan", ".inf", "-.inf", "+.inf":
+		return false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return false
+	}
+	if _, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return false
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@` \t") || strings.HasSuffix(s, " ") {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f || r == '\u0085' || r == '\u2028' || r == '\u2029' || r == '\ufeff' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package pretty
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+type Service struct {
+	Name    string `yaml:"name"`
+	Ports   []int
+	Env     map[string]string
+	Backend *Service `yaml:"backend,omitempty"`
+	Skip    bool     `yaml:"-"`
+	Points  []T
+	Matrix  [][]int
+}
+
+func TestYAML(t *testing.T) {
+	s := Service{
+		Name:    "web",
+		Ports:   []int{80, 443},
+		Env:     map[string]string{"A": "1", "B": "yes", "C": "a: b", "D": "plain text"},
+		Backend: &Service{Name: "db", Env: map[string]string{}},
+		Points:  []T{{1, 2}},
+		Matrix:  [][]int{{1, 2}, {3}},
+	}
+	cases := []struct {
+		opts []Option
+		v    interface{}
+		s    string
+	}{
+		{nil, s, `name: web
+ports:
+  - 80
+  - 443
+env:
+  A: "1"
+  B: "yes"
+  C: "a: b"
+  D: plain text
+backend:
+  name: db
+  ports: null
+  env: {}
+  backend: null
+  points: null
+  matrix: null
+points:
+  - x: 1
+    "y": 2
+matrix:
+  - - 1
+    - 2
+  - - 3`},
+		{nil, []interface{}{1, "", nil, math.NaN(), []byte{0xff}, []byte("hi")}, `- 1
+- ""
+- null
+- .nan
+- !!binary /w==
+- hi`},
+		{nil, "true", `"true"`},
+		{nil, map[int][]int{1: {}}, `"1": []`},
+		{[]Option{IndentWidth(4)}, map[string]map[string]int{"a": {"b": 1}}, `a:
+    b: 1`},
+		{[]Option{MaxDepth(1)}, map[string]map[string]int{"a": {"b": 1}}, `a: …`},
+		{[]Option{UnexportedFields(HideUnexported)}, struct {
+			A int
+			b int
+		}{1, 2}, `a: 1`},
+		{nil, list(1, 2), `v: 1
+prev: null
+next:
+  v: 2
+  prev: <cycle to root>
+  next: null`},
+	}
+	for _, tt := range cases {
+		s := fmt.Sprint(NewFormatter(tt.v, append(tt.opts, YAML(true))...))
+		if tt.s != s {
+			t.Errorf("expected %q", tt.s)
+			t.Errorf("got      %q", s)
+			t.Errorf("expraw\n%s", tt.s)
+			t.Errorf("gotraw\n%s", s)
+		}
+	}
+}
+
+func TestPlainSafe(t *testing.T) {
+	cases := map[string]bool{
+		"abc": true, "a b": true, "a:b": true, "1": false, "0x1f": false, "1.5e3": false,
+		"null": false, "No": false, "- a": false, "#x": false, "a #b": false, "a:": false,
+		"a\nb": false, " a": false, "a ": false, "*ref": false,
+	}
+	for s, want := range cases {
+		if got := plainSafe(s); got != want {
+			t.Errorf("plainSafe(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
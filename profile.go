@@ -0,0 +1,52 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+)
+
+// SetProfileLabels makes Q and its variants label the calling goroutine with
+// the q call site while they look up argument names and format the record,
+// so that CPU profiles attribute the time spent in q to the call sites. The
+// labels are q.site, e.g. main/main.go:42, and q.func, e.g. main.main.
+// The goroutine keeps the labels of the context passed to Ctx, and gets them
+// back after the call. The other calls have no context to take the labels
+// from, so they replace the labels the program set with pprof.Do; use Ctx
+// with the labeled context in code that sets its own labels.
+func SetProfileLabels(enabled bool) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.profileLabels = enabled
+}
+
+// WithProfileLabels makes the Logger label the calling goroutine with the q
+// call site, like SetProfileLabels.
+func WithProfileLabels(enabled bool) Option {
+	return func(l *logger) { l.profileLabels = enabled }
+}
+
+// labeled calls f, with the goroutine labeled with the call site of the
+// record if profile labels are enabled, in addition to the labels of the
+// context of Ctx, if any. l.mu must be held.
+func (l *logger) labeled(r Record, f func()) {
+	if !l.profileLabels || r.File == "" {
+		f()
+		return
+	}
+
+	ctx := l.labelCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	labels := pprof.Labels(
+		"q.site", shortFile(r.File)+":"+strconv.Itoa(r.Line),
+		"q.func", r.Func)
+	pprof.Do(ctx, labels, func(context.Context) { f() })
+}
@@ -0,0 +1,65 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+// TestProfileLabels verifies that the goroutine is labeled with the call site
+// while a record is written, and only if profile labels are enabled.
+func TestProfileLabels(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		var profile bytes.Buffer
+		sink := SinkFunc(func(r Record) error {
+			return pprof.Lookup("goroutine").WriteTo(&profile, 1)
+		})
+		l := New(WithOutput(io.Discard), WithSink(sink), WithProfileLabels(enabled))
+
+		l.Q(enabled)
+
+		want := `"q.func":"github.com/bingoohuang/q.TestProfileLabels"`
+		if got := strings.Contains(profile.String(), want); got != enabled {
+			t.Fatalf("\nWithProfileLabels(%v)\ngoroutine profile has labels: %v\nwant: %v", enabled, got, enabled)
+		}
+
+		if enabled && !strings.Contains(profile.String(), `"q.site":"`) {
+			t.Fatalf("\nWithProfileLabels(true)\ngoroutine profile:\n%s\nmissing q.site label", &profile)
+		}
+	}
+}
+
+// TestProfileLabelsCtx verifies that Ctx keeps the labels of its context, both
+// while the record is written and after the call.
+func TestProfileLabelsCtx(t *testing.T) {
+	var during bytes.Buffer
+	sink := SinkFunc(func(r Record) error {
+		return pprof.Lookup("goroutine").WriteTo(&during, 1)
+	})
+	l := New(WithOutput(io.Discard), WithSink(sink), WithProfileLabels(true))
+
+	var after bytes.Buffer
+	pprof.Do(context.Background(), pprof.Labels("app", "test"), func(ctx context.Context) {
+		l.Ctx(ctx, 1)
+		_ = pprof.Lookup("goroutine").WriteTo(&after, 1)
+	})
+
+	for _, want := range []string{`"app":"test"`, `"q.func":"github.com/bingoohuang/q.TestProfileLabelsCtx.func2"`} {
+		if !strings.Contains(during.String(), want) {
+			t.Fatalf("\ngoroutine profile during Ctx:\n%s\nmissing label %s", &during, want)
+		}
+	}
+
+	if !strings.Contains(after.String(), `"app":"test"`) {
+		t.Fatalf("\ngoroutine profile after Ctx:\n%s\nmissing label %s", &after, `"app":"test"`)
+	}
+}
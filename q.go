@@ -7,12 +7,23 @@ package q
 import (
 	"fmt"
 	"os"
+	"sync/atomic"
+	"time"
 )
 
 // nolint: gochecknoglobals
 var (
 	// std is the singleton logger.
-	std logger
+	std = logger{
+		out:     envOutput(os.Getenv("Q_OUTPUT")),
+		tee:     envOutput(os.Getenv("Q_TEE")),
+		format:  envFormat(os.Getenv("Q_FORMAT")),
+		noColor: envNoColor(),
+		filter:  envFilter(os.Getenv("Q_FILTER")),
+
+		timeFormat: os.Getenv("Q_TIME_FORMAT"),
+		timeLoc:    envTimeLocation(os.Getenv("Q_TIME_LOCATION")),
+	}
 
 	// CallDepth allows setting the number of levels runtime.Caller will
 	// skip when looking up the caller of the q.Q function. This allows
@@ -27,54 +38,197 @@ var (
 	CallDepth = 3
 
 	envQ = os.Getenv("Q") == "1"
+
+	// disabled turns all q calls into no-ops when set. See Disable.
+	disabled atomic.Bool
 )
 
+func init() { // nolint: gochecknoinits
+	disabled.Store(os.Getenv("Q_DISABLED") == "1")
+	std.level.Store(int32(envLevel(os.Getenv("Q_LEVEL"))))
+	std.sinks = []Sink{outputSink{&std}}
+
+	if !std.noColor {
+		enableTerminalColors(std.out)
+		enableTerminalColors(std.tee)
+	}
+}
+
+// Enable turns logging back on after Disable.
+func Enable() { disabled.Store(false) }
+
+// Disable turns every q call into a no-op until Enable is called. Disabled
+// calls return before any formatting or source parsing. Logging can also be
+// disabled from the start with $Q_DISABLED=1.
+func Disable() { disabled.Store(true) }
+
+// Enabled reports whether logging is currently enabled.
+func Enabled() bool { return !disabled.Load() }
+
 // D pretty-prints the given arguments to the $TMPDIR/$USER.q log file when export Q=1
 func D(v ...interface{}) {
-	if envQ {
-		q(CallDepth, v...)
+	if !off && envQ {
+		std.q(CallDepth, v...)
 	}
 }
 
 // Q pretty-prints the given arguments to the $TMPDIR/$USER.q log file.
 func Q(v ...interface{}) {
-	q(CallDepth, v...)
+	if off {
+		return
+	}
+
+	std.q(CallDepth, v...)
 }
 
-func q(callDepth int, v ...interface{}) {
-	std.mu.Lock()
-	defer std.mu.Unlock()
+// Qf formats according to a format specifier and writes the result to the
+// $TMPDIR/$USER.q log file, under the same header as Q.
+func Qf(format string, v ...interface{}) {
+	if off {
+		return
+	}
 
-	// Flush the buffered writes to disk.
-	defer func() {
-		if err := std.flush(); err != nil {
-			fmt.Println(err)
-		}
-	}()
+	std.qf(CallDepth, format, v...)
+}
+
+// If pretty-prints the given arguments to the $TMPDIR/$USER.q log file only
+// if cond is true. The output is the same as for Q(v...), including the names
+// of the arguments.
+func If(cond bool, v ...interface{}) {
+	if off || !cond {
+		return
+	}
+
+	std.log(CallDepth, v, 1)
+}
+
+func (l *logger) q(callDepth int, v ...interface{}) {
+	l.log(callDepth+1, v, 0)
+}
+
+func (l *logger) qf(callDepth int, format string, v ...interface{}) {
+	if off || l.skip(LevelInfo) {
+		return // skip the Sprintf as well
+	}
+
+	l.log(callDepth+1, []interface{}{fmt.Sprintf(format, v...)}, noNames)
+}
+
+// noNames is the skipArgs of log for records whose values don't come from the
+// arguments of the q call, e.g. the message of Qf.
+const noNames = -1
+
+// log writes the values to the log file, under a header for the caller found
+// callDepth frames up the stack. Each value is prefixed with the source text of
+// the matching argument of the q.Q() call, after skipping the first skipArgs
+// arguments that are not logged, like the condition of q.If().
+func (l *logger) log(callDepth int, v []interface{}, skipArgs int) {
+	if off || l.skip(LevelInfo) {
+		return
+	}
 
-	args := formatArgs(v...)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r, ok := l.newRecord(callDepth+1, v, skipArgs); ok {
+		l.write(r)
+	}
+}
+
+// newRecord returns the record of the values for the caller found callDepth
+// frames up the stack, with argument names looked up as described for log.
+// ok is false if the call site doesn't pass the logger's filter, and the
+// record must not be written. l.mu must be held.
+func (l *logger) newRecord(callDepth int, v []interface{}, skipArgs int) (r Record, ok bool) {
+	r = Record{Time: time.Now(), GID: goroutineID()}
 	funcName, file, line, err := getCallerInfo(callDepth)
+	if err == nil && !l.filter.match(file, line, funcName) {
+		return r, false
+	}
+
+	// Only evaluate the Lazy values of records that are written.
+	r.Values = l.hexValues(resolveLazy(v))
 	if err != nil {
-		std.output(args...) // no name=value printing
+		return r, true
+	}
+
+	r.Func, r.File, r.Line = funcName, file, line
+	if l.stackFrames > 0 {
+		r.Stack = callers(callDepth, l.stackFrames)
+	}
+
+	if skipArgs >= 0 {
+		l.labeled(r, func() {
+			// q.Q(foo, bar, baz) -> []string{"foo", "bar", "baz"}
+			r.Names, _ = cachedArgNames(file, line) // no name=value printing on error
+			if len(r.Names) >= skipArgs {
+				r.Names = spreadNames(r.Names[skipArgs:], len(v))
+			}
+		})
+	}
+
+	return r, true
+}
+
+// write renders the record in the logger's format, flushes it and passes it
+// on to the sinks, unless it only repeats the previous one, see SetDedup.
+// l.mu must be held.
+func (l *logger) write(r Record) {
+	if l.repeated(r) {
+		return
+	}
+
+	l.labeled(r, func() { l.writeSinks(r) })
+}
+
+// render renders the record in the logger's format and flushes it to the
+// logger's output, see outputSink. l.mu must be held.
+func (l *logger) render(r Record) error {
+	switch l.format {
+	case JSONL:
+		l.outputJSON(r)
+	case Logfmt:
+		l.outputLogfmt(r)
+	case Binary:
+		l.outputBinary(r)
+	case HTML:
+		l.outputHTML(r)
+	case Markdown:
+		l.outputMarkdown(r)
+	default:
+		l.outputText(r)
+	}
+
+	// Flush the buffered writes to disk.
+	return l.flush()
+}
+
+// outputText writes the record in the human-readable text format.
+func (l *logger) outputText(r Record) {
+	var args []string
+	if l.highlight && !l.noColor {
+		args = highlightArgs(l.valueOptions(), r.Values...)
+	} else {
+		args = formatArgs(l.valueOptions(), r.Values...)
+	}
+	if r.File == "" {
+		l.output(levelTag(r.Level, args)...) // no caller info, no header
 		return
 	}
 
 	// Print a header line if this q.Q() call is in a different file or
 	// function than the previous q.Q() call, or if the 2s timer expired.
 	// A header line looks like this: [14:00:36 main.go main.main:122].
-	header := std.header(funcName, file, line)
+	header := l.header(r.Func, r.File, r.Line, r.GID)
 	if header != "" {
-		fmt.Fprint(&std.buf, "\n", header, "\n")
-	}
-
-	// q.Q(foo, bar, baz) -> []string{"foo", "bar", "baz"}
-	names, err := argNames(file, line)
-	if err != nil {
-		std.output(args...) // no name=value printing
-		return
+		fmt.Fprint(&l.buf, "\n", header, "\n")
 	}
 
 	// Convert the arguments to name=value strings.
-	args = prependArgName(names, args)
-	std.output(args...)
+	args = prependArgName(r.Names, args)
+	l.output(levelTag(r.Level, args)...)
+
+	for _, frame := range r.Stack {
+		fmt.Fprint(&l.buf, "    at ", frame, "\n")
+	}
 }
@@ -0,0 +1,143 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestQf verifies that Qf() writes the printf-formatted message under a
+// header naming the calling function.
+func TestQf(t *testing.T) {
+	name := setTestPath(t)
+
+	Qf("%s has %d items", "cart", 3)
+
+	got := readLog(t, name)
+	want := colorize("cart has 3 items", cyan)
+	if !strings.Contains(got, want) {
+		t.Fatalf("\nQf()\ngot:  %q\nwant: %q", got, want)
+	}
+
+	if !strings.Contains(got, "q.TestQf") {
+		t.Fatalf("\nQf()\ngot:  %q\nmissing caller function name", got)
+	}
+}
+
+// TestDisable verifies that nothing is written while logging is disabled.
+func TestDisable(t *testing.T) {
+	name := setTestPath(t)
+
+	Disable()
+	Q("hidden")
+	Qf("hidden %d", 1)
+	Enable()
+
+	if _, err := os.Stat(name); err == nil {
+		t.Fatalf("\nQ() while disabled\ngot:  %q\nwant: no log file", readLog(t, name))
+	}
+
+	Q("shown")
+	if got := readLog(t, name); !strings.Contains(got, "shown") {
+		t.Fatalf("\nQ() after Enable()\ngot:  %q\nwant: shown", got)
+	}
+}
+
+// TestIf verifies that If() only logs when the condition is true, and names
+// the arguments without the condition.
+func TestIf(t *testing.T) {
+	name := setTestPath(t)
+
+	skipped, logged := "skipped", "logged"
+	If(false, skipped)
+	If(len(logged) > 0, logged)
+
+	got := readLog(t, name)
+	if strings.Contains(got, "skipped") {
+		t.Fatalf("\nIf(false, skipped)\ngot:  %q\nwant: nothing logged", got)
+	}
+
+	want := colorize("logged", bold) + "=" + colorize("logged", cyan)
+	if !strings.Contains(got, want) {
+		t.Fatalf("\nIf(true, logged)\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+// TestQMultiLine verifies that Q() names the arguments of a call split across
+// lines and of a method chain.
+func TestQMultiLine(t *testing.T) {
+	name := setTestPath(t)
+
+	var sb strings.Builder
+	Q(
+		sb.Len(),
+		strings.
+			ToUpper("a"),
+	)
+
+	got := readLog(t, name)
+	for _, want := range []string{
+		colorize("sb.Len()", bold) + "=",
+		colorize(`strings.ToUpper("a")`, bold) + "=",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\nQ()\ngot:  %q\nwant: %q", got, want)
+		}
+	}
+}
+
+// TestQCallArgs verifies that Q() names function call arguments by their
+// source text, including the values of a variadic spread and the results of a
+// multi-valued call.
+func TestQCallArgs(t *testing.T) {
+	name := setTestPath(t)
+
+	items := []interface{}{"x", "y"}
+	Q(len(items))
+	Q(items...)
+	Q(strconv.Atoi("42"))
+
+	got := readLog(t, name)
+	for _, want := range []string{
+		colorize("len(items)", bold) + "=",
+		colorize("items[0]", bold) + "=",
+		colorize("items[1]", bold) + "=",
+		colorize(`strconv.Atoi("42")[0]`, bold) + "=",
+		colorize(`strconv.Atoi("42")[1]`, bold) + "=",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\nQ()\ngot:  %q\nwant: %q", got, want)
+		}
+	}
+}
+
+// BenchmarkQ measures the time and allocations of a q call, from looking up
+// the argument names to flushing the record.
+func BenchmarkQ(b *testing.B) {
+	l := New(WithOutput(io.Discard))
+	port, host := 443, "example.com"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Q(port, host)
+	}
+}
+
+// BenchmarkQNoColor is BenchmarkQ with the colors stripped before flushing.
+func BenchmarkQNoColor(b *testing.B) {
+	l := New(WithOutput(io.Discard), WithColors(false))
+	port, host := 443, "example.com"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Q(port, host)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+// Package qlog reads the records of a q log file written in the q.Binary
+// format, e.g. with Q_FORMAT=binary, for tools that process the log:
+//
+//	entries, err := qlog.ReadFile(q.Path())
+package qlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/bingoohuang/q"
+)
+
+// maxRecordSize bounds the length of a record, so that a corrupt length
+// doesn't allocate unbounded memory.
+const maxRecordSize = 64 << 20
+
+// ErrFormat is returned when the data is not a record in the q.Binary format.
+var ErrFormat = errors.New("qlog: not a binary q record")
+
+// Entry is a decoded record. Its Values are the pretty-printed strings written
+// by q.
+type Entry struct {
+	q.Record
+	PID int // id of the process that wrote the record
+}
+
+// record mirrors the binary form of a record written by q. gob matches the
+// fields by name.
+type record struct {
+	Time   time.Time
+	Level  int
+	File   string
+	Line   int
+	Func   string
+	PID    int
+	GID    uint64
+	Names  []string
+	Values []string
+	Stack  []string
+}
+
+// Reader decodes the records of a q.Binary log one by one.
+type Reader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+// NewReader returns a Reader decoding the records read from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next record. At the end of the input it returns io.EOF, and
+// io.ErrUnexpectedEOF if the last record is truncated, e.g. because it is
+// still being written.
+func (r *Reader) Read() (Entry, error) {
+	marker, err := r.r.ReadByte()
+	if err != nil {
+		return Entry{}, err // io.EOF between records
+	}
+
+	if marker != q.BinaryMarker {
+		return Entry{}, ErrFormat
+	}
+
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return Entry{}, unexpectedEOF(err)
+	}
+
+	if n > maxRecordSize {
+		return Entry{}, fmt.Errorf("%w: record of %d bytes", ErrFormat, n)
+	}
+
+	if uint64(cap(r.buf)) < n {
+		r.buf = make([]byte, n)
+	}
+	r.buf = r.buf[:n]
+	if _, err := io.ReadFull(r.r, r.buf); err != nil {
+		return Entry{}, unexpectedEOF(err)
+	}
+
+	var rec record
+	if err := gob.NewDecoder(bytes.NewReader(r.buf)).Decode(&rec); err != nil {
+		return Entry{}, fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+
+	return newEntry(rec), nil
+}
+
+// ReadFile returns all complete records of the named q.Binary log file.
+func ReadFile(name string) ([]Entry, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	r := NewReader(f)
+	for {
+		e, err := r.Read()
+		switch {
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+			return entries, nil
+		case err != nil:
+			return entries, fmt.Errorf("read %s: %w", name, err)
+		}
+
+		entries = append(entries, e)
+	}
+}
+
+// newEntry converts a decoded record to an Entry.
+func newEntry(rec record) Entry {
+	values := make([]interface{}, len(rec.Values))
+	for i, v := range rec.Values {
+		values[i] = v
+	}
+
+	return Entry{
+		Record: q.Record{
+			Time:   rec.Time,
+			Level:  q.Level(rec.Level),
+			File:   rec.File,
+			Line:   rec.Line,
+			Func:   rec.Func,
+			GID:    rec.GID,
+			Names:  rec.Names,
+			Values: values,
+			Stack:  rec.Stack,
+		},
+		PID: rec.PID,
+	}
+}
+
+// unexpectedEOF turns io.EOF within a record into io.ErrUnexpectedEOF.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
@@ -0,0 +1,89 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package qlog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bingoohuang/q"
+)
+
+// TestRead verifies that Read() decodes the records written in the q.Binary
+// format, with their names, values and caller.
+func TestRead(t *testing.T) {
+	var buf bytes.Buffer
+	l := q.New(q.WithOutput(&buf), q.WithFormat(q.Binary), q.WithColors(false))
+
+	port, host := 443, "example.com"
+	l.Q(port, host)
+	l.Warn(port)
+
+	r := NewReader(&buf)
+	first, err := r.Read()
+	if err != nil {
+		t.Fatalf("Read(): %v", err)
+	}
+
+	if first.PID != os.Getpid() || first.Func != "github.com/bingoohuang/q/qlog.TestRead" {
+		t.Fatalf("\nRead()\ngot:  pid %d func %q\nwant: pid %d func TestRead", first.PID, first.Func, os.Getpid())
+	}
+
+	if want := []string{"port", "host"}; !reflect.DeepEqual(first.Names, want) {
+		t.Fatalf("\nRead() names\ngot:  %q\nwant: %q", first.Names, want)
+	}
+
+	if want := []interface{}{"int(443)", "example.com"}; !reflect.DeepEqual(first.Values, want) {
+		t.Fatalf("\nRead() values\ngot:  %q\nwant: %q", first.Values, want)
+	}
+
+	second, err := r.Read()
+	if err != nil || second.Level != q.LevelWarn {
+		t.Fatalf("\nRead()\ngot:  %v, %v\nwant: WARN record", second.Level, err)
+	}
+
+	if _, err := r.Read(); !errors.Is(err, io.EOF) {
+		t.Fatalf("\nRead()\ngot:  %v\nwant: io.EOF", err)
+	}
+}
+
+// TestReadFile verifies that ReadFile() returns the complete records of a file
+// and skips a truncated last record.
+func TestReadFile(t *testing.T) {
+	var buf bytes.Buffer
+	l := q.New(q.WithOutput(&buf), q.WithFormat(q.Binary))
+	for i := 0; i < 3; i++ {
+		l.Q(i)
+	}
+
+	name := filepath.Join(t.TempDir(), "q")
+	if err := os.WriteFile(name, buf.Bytes()[:buf.Len()-1], 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadFile(name)
+	if err != nil {
+		t.Fatalf("ReadFile(%q): %v", name, err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("\nReadFile(%q)\ngot:  %d records\nwant: 2", name, len(entries))
+	}
+}
+
+// TestReadText verifies that Read() rejects a log in a text format.
+func TestReadText(t *testing.T) {
+	r := NewReader(bytes.NewReader([]byte("\n[2024-01-02T15:04:05.000 main/main.go:10 main.main]\n")))
+	if _, err := r.Read(); !errors.Is(err, ErrFormat) {
+		t.Fatalf("\nRead()\ngot:  %v\nwant: %v", err, ErrFormat)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+// Package qlogrus connects q and logrus. Hook writes logrus entries to the q
+// log file with q's pretty-printing, and Sink mirrors q.Q calls into a logrus
+// logger:
+//
+//	logrus.AddHook(qlogrus.NewHook())
+//	q.AddSink(qlogrus.Sink(logrus.StandardLogger()))
+package qlogrus
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bingoohuang/q"
+	"github.com/bingoohuang/q/pretty"
+)
+
+// Hook is a logrus.Hook writing entries to a q log, each as the level and
+// message followed by the fields as name=value pairs, sorted by name.
+type Hook struct {
+	levels []logrus.Level
+	write  func(q.Record)
+}
+
+// NewHook returns a Hook writing entries of the given levels, or of all
+// levels if none are given, to the $TMPDIR/$USER.q log file, like q.Q.
+func NewHook(levels ...logrus.Level) *Hook {
+	return newHook(q.WriteRecord, levels)
+}
+
+// NewLoggerHook returns a Hook writing entries of the given levels, or of all
+// levels if none are given, to the output of l.
+func NewLoggerHook(l *q.Logger, levels ...logrus.Level) *Hook {
+	return newHook(l.WriteRecord, levels)
+}
+
+func newHook(write func(q.Record), levels []logrus.Level) *Hook {
+	if len(levels) == 0 {
+		levels = logrus.AllLevels
+	}
+
+	return &Hook{levels: levels, write: write}
+}
+
+// Levels returns the levels of the entries written by the hook.
+func (h *Hook) Levels() []logrus.Level { return h.levels }
+
+// Fire writes the entry under a header for its caller, if the logger reports
+// callers.
+func (h *Hook) Fire(e *logrus.Entry) error {
+	r := q.Record{
+		Time:   e.Time,
+		Names:  []string{""},
+		Values: []interface{}{strings.ToUpper(e.Level.String()) + " " + e.Message},
+	}
+
+	if e.Caller != nil {
+		r.File, r.Line, r.Func = e.Caller.File, e.Caller.Line, e.Caller.Function
+	}
+
+	keys := make([]string, 0, len(e.Data))
+	for k := range e.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		r.Names = append(r.Names, k)
+		r.Values = append(r.Values, e.Data[k])
+	}
+
+	h.write(r)
+
+	return nil
+}
+
+// Sink returns a q.Sink writing every record to logger at debug level. The
+// message is the calling function, with the file and line and one field per
+// argument, keyed by its source text, e.g. port=443.
+func Sink(logger logrus.FieldLogger) q.Sink {
+	return q.SinkFunc(func(r q.Record) error {
+		fields := make(logrus.Fields, len(r.Values)+1)
+		if r.File != "" {
+			fields["file"] = shortFile(r.File) + ":" + strconv.Itoa(r.Line)
+		}
+
+		for i, v := range r.Values {
+			key := "arg" + strconv.Itoa(i)
+			if i < len(r.Names) && r.Names[i] != "" {
+				key = r.Names[i]
+			}
+
+			// logrus formatters don't know about redacted fields.
+			if pretty.ContainsRedacted(v) {
+				v = pretty.Sprint(v)
+			}
+			fields[key] = v
+		}
+
+		logger.WithFields(fields).WithTime(r.Time).Debug(r.Func)
+
+		return nil
+	})
+}
+
+// shortFile returns the <directory>/<file> of a path, like the q log file.
+func shortFile(file string) string {
+	return filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file))
+}
@@ -0,0 +1,72 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package qlogrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bingoohuang/q"
+)
+
+// TestHook verifies that a Hook writes logrus entries with the caller, the
+// message and the sorted fields.
+func TestHook(t *testing.T) {
+	var buf bytes.Buffer
+	l := q.New(q.WithOutput(&buf), q.WithColors(false))
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetReportCaller(true)
+	logger.AddHook(NewLoggerHook(l, logrus.InfoLevel))
+
+	logger.Warn("hidden")
+	logger.WithFields(logrus.Fields{"id": 7, "app": "demo"}).Info("hello")
+
+	got := buf.String()
+	for _, want := range []string{
+		"qlogrus_test.go:",
+		"qlogrus.TestHook]",
+		"INFO hello app=demo id=int(7)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+
+	if strings.Contains(got, "hidden") {
+		t.Fatalf("\ngot:  %q\nwant: no warning entry", got)
+	}
+}
+
+// TestSink verifies that a Sink mirrors q records into a logrus logger.
+func TestSink(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetLevel(logrus.DebugLevel)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	l := q.New(q.WithOutput(io.Discard), q.WithSink(Sink(logger)))
+
+	port := 443
+	l.Q(port)
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal(%q): %v", buf.String(), err)
+	}
+
+	if got["level"] != "debug" || got["msg"] != "github.com/bingoohuang/q/qlogrus.TestSink" ||
+		got["port"] != 443.0 || !strings.Contains(got["file"].(string), "qlogrus_test.go:") {
+		t.Fatalf("\ngot:  %s\nwant: debug entry of port=443", buf.String())
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build qoff
+
+package q
+
+// off is true when q is built with the qoff build tag. Every q call then
+// compiles to an empty function: nothing is formatted and nothing is written.
+const off = true
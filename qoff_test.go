@@ -0,0 +1,39 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build qoff
+
+package q
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestQoff verifies that q calls write nothing when q is built with the qoff
+// build tag.
+func TestQoff(t *testing.T) {
+	name := setTestPath(t)
+
+	Q("a", 1)
+	If(true, "b")
+	Every(1, "c")
+	Once("d")
+	Limit(time.Second, "e")
+
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Fatalf("\ngot:  %v\nwant: no log file", err)
+	}
+
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf))
+	l.Q("a", 1)
+	l.If(true, "b")
+
+	if buf.Len() != 0 {
+		t.Fatalf("\ngot:  %q\nwant: no output", buf.String())
+	}
+}
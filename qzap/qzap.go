@@ -0,0 +1,171 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+// Package qzap connects q and zap. NewCore writes zap entries to the q log
+// file with q's pretty-printing, and Sink mirrors q.Q calls into a zap logger:
+//
+//	logger := zap.New(zapcore.NewTee(core, qzap.NewCore(zapcore.DebugLevel)))
+//	q.AddSink(qzap.Sink(logger))
+package qzap
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/bingoohuang/q"
+	"github.com/bingoohuang/q/pretty"
+)
+
+// Core is a zapcore.Core writing entries to a q log, each as the level and
+// message followed by the fields as name=value pairs.
+type Core struct {
+	zapcore.LevelEnabler
+
+	write  func(q.Record)
+	names  []string
+	values []interface{}
+}
+
+// NewCore returns a Core writing the entries enabled by enab to the
+// $TMPDIR/$USER.q log file, like q.Q.
+func NewCore(enab zapcore.LevelEnabler) *Core {
+	return &Core{LevelEnabler: enab, write: q.WriteRecord}
+}
+
+// NewLoggerCore returns a Core writing the entries enabled by enab to the
+// output of l.
+func NewLoggerCore(l *q.Logger, enab zapcore.LevelEnabler) *Core {
+	return &Core{LevelEnabler: enab, write: l.WriteRecord}
+}
+
+// With returns a Core that writes the fields with every entry.
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	c2 := *c
+	c2.names, c2.values = appendFields(c.names, c.values, fields)
+
+	return &c2
+}
+
+// Check adds the Core to the checked entry if the level is enabled.
+func (c *Core) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+
+	return ce
+}
+
+// Write writes the entry and fields under a header for the entry's caller.
+func (c *Core) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	r := q.Record{
+		Time:   e.Time,
+		Names:  append([]string{""}, c.names...),
+		Values: append([]interface{}{e.Level.CapitalString() + " " + e.Message}, c.values...),
+	}
+
+	if e.Caller.Defined {
+		r.File, r.Line, r.Func = e.Caller.File, e.Caller.Line, e.Caller.Function
+	}
+
+	if e.Stack != "" {
+		r.Stack = stackFrames(e.Stack)
+	}
+
+	r.Names, r.Values = appendFields(r.Names, r.Values, fields)
+	c.write(r)
+
+	return nil
+}
+
+// Sync is a no-op, q flushes every record when it is written.
+func (c *Core) Sync() error { return nil }
+
+// appendFields appends the names and values of the fields. Values of
+// zap.Reflect and zap.Any fields are kept as they are, so q can pretty-print
+// them.
+func appendFields(names []string, values []interface{}, fields []zapcore.Field) ([]string, []interface{}) {
+	names = append([]string(nil), names...)
+	values = append([]interface{}(nil), values...)
+
+	for _, f := range fields {
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+
+		v, ok := enc.Fields[f.Key]
+		if f.Type == zapcore.ReflectType && f.Interface != nil {
+			v, ok = f.Interface, true
+		}
+
+		if !ok {
+			continue // e.g. zap.Skip()
+		}
+
+		names = append(names, f.Key)
+		values = append(values, v)
+	}
+
+	return names, values
+}
+
+// stackFrames turns a zap stack trace, with the file and line indented on the
+// line after each function, into the "function file:line" frames of q.
+func stackFrames(stack string) []string {
+	lines := strings.Split(stack, "\n")
+	frames := make([]string, 0, (len(lines)+1)/2)
+	for i := 0; i < len(lines); i += 2 {
+		frame := lines[i]
+		if i+1 < len(lines) {
+			frame += " " + strings.TrimSpace(lines[i+1])
+		}
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+// Sink returns a q.Sink writing every record to logger at debug level. The
+// message is the calling function, followed by the file and line and one
+// field per argument, keyed by its source text, e.g. port=443.
+func Sink(logger *zap.Logger) q.Sink {
+	logger = logger.WithOptions(zap.WithCaller(false))
+
+	return q.SinkFunc(func(r q.Record) error {
+		ce := logger.Check(zapcore.DebugLevel, r.Func)
+		if ce == nil {
+			return nil
+		}
+
+		ce.Time = r.Time
+		fields := make([]zapcore.Field, 0, len(r.Values)+1)
+		if r.File != "" {
+			fields = append(fields, zap.String("file", shortFile(r.File)+":"+strconv.Itoa(r.Line)))
+		}
+
+		for i, v := range r.Values {
+			key := "arg" + strconv.Itoa(i)
+			if i < len(r.Names) && r.Names[i] != "" {
+				key = r.Names[i]
+			}
+
+			// zap encoders don't know about redacted fields.
+			if pretty.ContainsRedacted(v) {
+				v = pretty.Sprint(v)
+			}
+			fields = append(fields, zap.Any(key, v))
+		}
+
+		ce.Write(fields...)
+
+		return nil
+	})
+}
+
+// shortFile returns the <directory>/<file> of a path, like the q log file.
+func shortFile(file string) string {
+	return filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file))
+}
@@ -0,0 +1,72 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package qzap
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/bingoohuang/q"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+// TestCore verifies that a Core writes zap entries with the caller, the
+// message and the pretty-printed fields.
+func TestCore(t *testing.T) {
+	var buf bytes.Buffer
+	l := q.New(q.WithOutput(&buf), q.WithColors(false))
+	logger := zap.New(NewLoggerCore(l, zapcore.InfoLevel), zap.AddCaller()).With(zap.String("app", "demo"))
+
+	logger.Debug("hidden")
+	logger.Info("hello", zap.Int("id", 7), zap.Any("user", user{Name: "ann", Age: 3}))
+
+	got := buf.String()
+	for _, want := range []string{
+		"qzap_test.go:",
+		"qzap.TestCore]",
+		"INFO hello app=demo id=int64(7) user=qzap.user{Name:\"ann\", Age:3}\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+
+	if strings.Contains(got, "hidden") {
+		t.Fatalf("\ngot:  %q\nwant: no debug entry", got)
+	}
+}
+
+// TestSink verifies that a Sink mirrors q records into a zap logger.
+func TestSink(t *testing.T) {
+	var buf bytes.Buffer
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	logger := zap.New(zapcore.NewCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel))
+	l := q.New(q.WithOutput(io.Discard), q.WithSink(Sink(logger)))
+
+	port := 443
+	l.Q(port)
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal(%q): %v", buf.String(), err)
+	}
+
+	if got["level"] != "debug" || got["msg"] != "github.com/bingoohuang/q/qzap.TestSink" ||
+		got["port"] != 443.0 || !strings.Contains(got["file"].(string), "qzap_test.go:") {
+		t.Fatalf("\ngot:  %s\nwant: debug entry of port=443", buf.String())
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+)
+
+// importPath is the import path of package q.
+const importPath = "github.com/bingoohuang/q"
+
+// receivers are the names of the variables, parameters and fields holding a
+// Logger or Fields, and of the functions returning one, in a file and in the
+// top-level declarations of the other files of its package. The source is
+// not type-checked, so the names are matched regardless of their scope.
+type receivers struct {
+	pkg   string          // name of package q in the file, see qPackage
+	vars  map[string]bool // variables, parameters and fields
+	funcs map[string]bool // functions and methods
+}
+
+// newReceivers returns the receivers declared in the file f.
+func newReceivers(f *ast.File) *receivers {
+	r := &receivers{pkg: qPackage(f), vars: map[string]bool{}, funcs: map[string]bool{}}
+	r.add(f, false)
+
+	return r
+}
+
+// addPackage adds the receivers of the top-level declarations of the other
+// files of the package of f, which is in filename.
+func (r *receivers) addPackage(filename string, f *ast.File) {
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(filename), "*.go"))
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if filepath.Base(name) == filepath.Base(filename) {
+			continue
+		}
+
+		pf, err := parser.ParseFile(fset, name, nil, parser.SkipObjectResolution)
+		if err == nil && pf.Name.Name == f.Name.Name {
+			r.add(pf, true)
+		}
+	}
+}
+
+// add adds the receivers declared in f, or only in its top-level declarations.
+func (r *receivers) add(f *ast.File, topLevel bool) {
+	pkg := qPackage(f)
+	if pkg == "" {
+		return // the file can't refer to a Logger
+	}
+
+	// Functions may be declared after their use.
+	for _, decl := range f.Decls {
+		if fn, is := decl.(*ast.FuncDecl); is && fn.Type.Results != nil {
+			for _, res := range fn.Type.Results.List {
+				if isReceiverType(res.Type, pkg) {
+					r.funcs[fn.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			return !topLevel
+		case *ast.Field:
+			if isReceiverType(n.Type, pkg) {
+				for _, name := range n.Names {
+					r.vars[name.Name] = true
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if n.Type != nil && isReceiverType(n.Type, pkg) ||
+					n.Type == nil && i < len(n.Values) && r.isNew(n.Values[i], pkg) {
+					r.vars[name.Name] = true
+				}
+			}
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				break
+			}
+
+			for i, lhs := range n.Lhs {
+				if r.isNew(n.Rhs[i], pkg) {
+					switch lhs := lhs.(type) {
+					case *ast.Ident:
+						r.vars[lhs.Name] = true
+					case *ast.SelectorExpr:
+						r.vars[lhs.Sel.Name] = true
+					}
+				}
+			}
+		}
+
+		return true
+	})
+}
+
+// is reports whether x is a Logger or Fields.
+func (r *receivers) is(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return r.vars[x.Name]
+	case *ast.SelectorExpr:
+		return r.vars[x.Sel.Name] // a field, e.g. s.qlog
+	case *ast.ParenExpr:
+		return r.is(x.X)
+	case *ast.StarExpr:
+		return r.is(x.X)
+	}
+
+	return r.isNew(x, r.pkg)
+}
+
+// isNew reports whether x returns a Logger or Fields, like q.New(), q.With()
+// and l.With(), given the name pkg of package q in its file.
+func (r *receivers) isNew(x ast.Expr, pkg string) bool {
+	switch x := x.(type) {
+	case *ast.CallExpr:
+		switch fun := x.Fun.(type) {
+		case *ast.Ident:
+			return r.funcs[fun.Name] || isQName(fun, pkg, "New", "With")
+		case *ast.SelectorExpr:
+			return r.funcs[fun.Sel.Name] || isQName(fun, pkg, "New", "With") ||
+				fun.Sel.Name == "With" && r.is(fun.X)
+		}
+	case *ast.UnaryExpr:
+		return x.Op == token.AND && r.isNew(x.X, pkg)
+	case *ast.CompositeLit:
+		return isReceiverType(x.Type, pkg)
+	}
+
+	return false
+}
+
+// isReceiverType reports whether t is Logger, Fields, or a pointer to them.
+func isReceiverType(t ast.Expr, pkg string) bool {
+	if star, is := t.(*ast.StarExpr); is {
+		t = star.X
+	}
+
+	return isQName(t, pkg, "Logger", "Fields")
+}
+
+// isQName reports whether x is one of the names exported by package q, e.g.
+// q.New, given the name pkg of package q in its file.
+func isQName(x ast.Expr, pkg string, names ...string) bool {
+	var name string
+	switch x := x.(type) {
+	case *ast.Ident:
+		if pkg != "." {
+			return false
+		}
+		name = x.Name
+	case *ast.SelectorExpr:
+		if id, is := x.X.(*ast.Ident); !is || id.Name != pkg {
+			return false
+		}
+		name = x.Sel.Name
+	default:
+		return false
+	}
+
+	for _, n := range names {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+// qPackage returns the name package q is imported as in f: "q", its alias, or
+// "." if it is dot-imported or f is in package q itself. It returns "" if f
+// doesn't import package q.
+func qPackage(f *ast.File) string {
+	if f.Name.Name == "q" {
+		return "."
+	}
+
+	for _, imp := range f.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); path != importPath {
+			continue
+		}
+
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+
+		return "q"
+	}
+
+	return ""
+}
@@ -0,0 +1,114 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import "sync"
+
+// RingSink is a Sink that keeps the most recent records in memory, e.g. to
+// attach them to a crash report. The records keep references to the logged
+// values, so values changed after logging are seen changed. It is safe for
+// concurrent use.
+type RingSink struct {
+	mu      sync.Mutex // protects all the other fields
+	records []Record   // circular buffer, oldest record at next once full
+	next    int        // index of the next write
+	full    bool       // records has wrapped around at least once
+}
+
+// NewRingSink returns a RingSink keeping the last size records.
+func NewRingSink(size int) *RingSink {
+	if size < 1 {
+		size = 1
+	}
+
+	return &RingSink{records: make([]Record, size)}
+}
+
+// Write stores the record, replacing the oldest one once the ring is full.
+func (s *RingSink) Write(r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[s.next] = r
+	s.next++
+	if s.next == len(s.records) {
+		s.next = 0
+		s.full = true
+	}
+
+	return nil
+}
+
+// Recent returns up to the last n records, oldest first.
+func (s *RingSink) Recent(n int) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := s.next
+	if s.full {
+		size = len(s.records)
+	}
+
+	if n > size {
+		n = size
+	}
+
+	if n <= 0 {
+		return nil
+	}
+
+	out := make([]Record, n)
+	start := s.next - n
+	if start < 0 {
+		start += len(s.records)
+	}
+
+	for i := range out {
+		out[i] = s.records[(start+i)%len(s.records)]
+	}
+
+	return out
+}
+
+// SetRecent makes Q and its variants keep their last size records in memory,
+// in addition to writing them to the log file, for Recent. 0 disables it,
+// which is the default, and drops the kept records.
+func SetRecent(size int) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.recent = nil
+	if size > 0 {
+		std.recent = NewRingSink(size)
+	}
+}
+
+// WithRecent makes the Logger keep its last size records in memory, for
+// Logger.Recent. Combined with WithOutput(io.Discard), nothing is written.
+func WithRecent(size int) Option {
+	return func(l *logger) { l.recent = NewRingSink(size) }
+}
+
+// Recent returns up to the last n records kept since SetRecent, oldest first.
+func Recent(n int) []Record {
+	return std.recentRecords(n)
+}
+
+// Recent returns up to the last n records kept by WithRecent, oldest first.
+func (l *Logger) Recent(n int) []Record {
+	return l.recentRecords(n)
+}
+
+func (l *logger) recentRecords(n int) []Record {
+	l.mu.Lock()
+	r := l.recent
+	l.mu.Unlock()
+
+	if r == nil {
+		return nil
+	}
+
+	return r.Recent(n)
+}
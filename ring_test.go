@@ -0,0 +1,63 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+// TestRingSink verifies that RingSink.Recent() returns the last records,
+// oldest first, before and after the ring wrapped around.
+func TestRingSink(t *testing.T) {
+	testCases := []struct {
+		writes int
+		n      int
+		want   string
+	}{
+		{writes: 0, n: 2, want: "[]"},
+		{writes: 2, n: 0, want: "[]"},
+		{writes: 2, n: 3, want: "[0 1]"},
+		{writes: 3, n: 3, want: "[0 1 2]"},
+		{writes: 5, n: 2, want: "[3 4]"},
+		{writes: 7, n: 5, want: "[4 5 6]"},
+	}
+
+	for _, tc := range testCases {
+		s := NewRingSink(3)
+		for i := 0; i < tc.writes; i++ {
+			_ = s.Write(Record{Line: i})
+		}
+
+		lines := []int{}
+		for _, r := range s.Recent(tc.n) {
+			lines = append(lines, r.Line)
+		}
+
+		if got := fmt.Sprint(lines); got != tc.want {
+			t.Fatalf("\n%d writes, Recent(%d)\ngot:  %s\nwant: %s", tc.writes, tc.n, got, tc.want)
+		}
+	}
+}
+
+// TestLoggerRecent verifies that a Logger created WithRecent keeps its records.
+func TestLoggerRecent(t *testing.T) {
+	l := New(WithOutput(io.Discard), WithRecent(2))
+	for i := 0; i < 3; i++ {
+		l.Q(i)
+	}
+
+	got := l.Recent(10)
+	if len(got) != 2 || got[0].Values[0] != 1 || got[1].Values[0] != 2 {
+		t.Fatalf("\nl.Recent(10)\ngot:  %+v\nwant: records of 1 and 2", got)
+	}
+
+	if got := New().Recent(10); got != nil {
+		t.Fatalf("\nRecent() without WithRecent\ngot:  %+v\nwant: nil", got)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+)
+
+// nolint: gochecknoglobals
+var (
+	// maxFileSize is the size in bytes above which the log file is rotated.
+	// 0 disables rotation.
+	maxFileSize atomic.Int64
+
+	// maxBackups is the number of rotated files kept next to the log file.
+	maxBackups atomic.Int64
+)
+
+func init() { // nolint: gochecknoinits
+	maxBackups.Store(3)
+}
+
+// SetMaxFileSize enables rotation of the log file once it would grow beyond
+// size bytes. The current file is renamed to <name>.1, older files are shifted
+// to <name>.2, <name>.3 and so on. A size <= 0 disables rotation, which is the
+// default.
+func SetMaxFileSize(size int64) {
+	maxFileSize.Store(size)
+}
+
+// SetMaxBackups sets the number of rotated log files to keep. It defaults to 3.
+// Files beyond that count are removed on rotation.
+func SetMaxBackups(n int) {
+	maxBackups.Store(int64(n))
+}
+
+// appendRotated appends data to the file name like AppendFile, rotating the
+// file first if the data would make it exceed the maximum size. The size is
+// checked, the file rotated and the data appended while holding the lock of
+// name.lock, so that concurrent writers neither rotate the file twice nor
+// append beyond the size. The lock can't be that of the file itself, which
+// is renamed.
+func appendRotated(name string, data []byte, mode os.FileMode) error {
+	if maxFileSize.Load() <= 0 {
+		return AppendFile(name, data, mode)
+	}
+
+	lock, err := os.OpenFile(name+".lock", os.O_CREATE|os.O_WRONLY, mode)
+	if err != nil {
+		return fmt.Errorf("rotate %s: %w", name, err)
+	}
+	defer lock.Close() // closing the file releases the lock as well
+
+	if lockFile(lock) == nil { // rotate anyway if locking fails
+		defer unlockFile(lock) // nolint: errcheck
+	}
+
+	return MergeErrors(rotateFile(name, len(data)), AppendFile(name, data, mode))
+}
+
+// rotateFile rotates the file name if appending n more bytes would make it
+// exceed the configured maximum size. Writers hold the lock of appendRotated
+// while calling it. Should locking fail, only the process that wins the rename
+// of the current file shifts the backups.
+func rotateFile(name string, n int) error {
+	limit := maxFileSize.Load()
+	if limit <= 0 {
+		return nil
+	}
+
+	fi, err := os.Stat(name)
+	if err != nil || fi.Size()+int64(n) <= limit {
+		return nil // nolint: nilerr // nothing to rotate yet
+	}
+
+	// Move the current file out of the way first. Another process may have
+	// rotated it in the meantime, which is fine.
+	tmp := name + ".rotating." + strconv.Itoa(os.Getpid())
+	if err := os.Rename(name, tmp); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("rotate %s: %w", name, err)
+	}
+
+	backups := int(maxBackups.Load())
+	if backups <= 0 {
+		return removeFile(tmp)
+	}
+
+	if err := removeFile(backupName(name, backups)); err != nil {
+		return err
+	}
+
+	for i := backups - 1; i > 0; i-- {
+		err := os.Rename(backupName(name, i), backupName(name, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rotate %s: %w", name, err)
+		}
+	}
+
+	if err := os.Rename(tmp, backupName(name, 1)); err != nil {
+		return fmt.Errorf("rotate %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// backupName returns the name of the i-th rotated file of name.
+func backupName(name string, i int) string {
+	return name + "." + strconv.Itoa(i)
+}
+
+// removeFile removes the named file. A missing file is not an error.
+func removeFile(name string) error {
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove %s: %w", name, err)
+	}
+
+	return nil
+}
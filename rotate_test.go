@@ -0,0 +1,110 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestRotateFile verifies that rotateFile() shifts the log file into numbered
+// backups once it exceeds the maximum size, keeping at most maxBackups files.
+func TestRotateFile(t *testing.T) {
+	SetMaxFileSize(10)
+	SetMaxBackups(2)
+	t.Cleanup(func() {
+		SetMaxFileSize(0)
+		SetMaxBackups(3)
+	})
+
+	name := filepath.Join(t.TempDir(), "q")
+	for _, data := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if err := rotateFile(name, len(data)); err != nil {
+			t.Fatalf("rotateFile(%q): %v", name, err)
+		}
+
+		if err := AppendFile(name, []byte(data), 0o666); err != nil {
+			t.Fatalf("AppendFile(%q): %v", name, err)
+		}
+	}
+
+	want := map[string]string{
+		name:                "fourth\n",
+		backupName(name, 1): "third\n",
+		backupName(name, 2): "second\n",
+	}
+	for file, content := range want {
+		got := readLog(t, file)
+		if got != content {
+			t.Fatalf("\n%s\ngot:  %q\nwant: %q", file, got, content)
+		}
+	}
+
+	if _, err := os.Stat(backupName(name, 3)); err == nil {
+		t.Fatalf("%s exists, want at most 2 backups", backupName(name, 3))
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(name))
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".rotating.") {
+			t.Fatalf("leftover temporary file %s", e.Name())
+		}
+	}
+}
+
+// TestAppendRotatedConcurrent verifies that concurrent writers rotate the log
+// file such that no file exceeds the maximum size and no data is lost.
+func TestAppendRotatedConcurrent(t *testing.T) {
+	const (
+		writers = 32
+		writes  = 50
+		line    = "0123456789\n"
+	)
+	SetMaxFileSize(10 * int64(len(line)))
+	SetMaxBackups(writers * writes)
+	t.Cleanup(func() {
+		SetMaxFileSize(0)
+		SetMaxBackups(3)
+	})
+
+	name := filepath.Join(t.TempDir(), "q")
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < writes; j++ {
+				if _, err := fileWriter(name).Write([]byte(line)); err != nil {
+					t.Errorf("Write(): %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := 0
+	for i := 0; ; i++ {
+		file := name
+		if i > 0 {
+			file = backupName(name, i)
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			break
+		}
+		if int64(len(b)) > maxFileSize.Load() {
+			t.Fatalf("%s has %d bytes, want at most %d", file, len(b), maxFileSize.Load())
+		}
+		lines += strings.Count(string(b), line)
+	}
+
+	if lines != writers*writes {
+		t.Fatalf("\nlines in the files\ngot:  %d\nwant: %d", lines, writers*writes)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// site is the sampling state of a single call site.
+type site struct {
+	calls      uint64    // number of calls so far
+	last       time.Time // last time the call site was logged
+	suppressed uint64    // calls not logged since the last logged call
+}
+
+// nolint: gochecknoglobals
+var (
+	sites   = make(map[uintptr]*site)
+	sitesMu sync.Mutex
+)
+
+// Every pretty-prints the given arguments like Q, but only on every n-th call
+// of the call site: the first, the n+1-th, and so on. The record ends with the
+// number of the call, e.g. (call #201).
+func Every(n int, v ...interface{}) {
+	if off || std.skip(LevelInfo) {
+		return
+	}
+
+	s, unlock := lockSite(CallDepth - 1)
+	s.calls++
+	calls := s.calls
+	unlock()
+
+	if n > 1 && (calls-1)%uint64(n) != 0 {
+		return
+	}
+
+	std.logNote(CallDepth, v, fmt.Sprintf("(call #%d)", calls))
+}
+
+// Once pretty-prints the given arguments like Q, but only the first time the
+// call site runs during the lifetime of the process. It confirms that a
+// rarely taken branch is hit without flooding the output if it turns out to
+// be hot.
+func Once(v ...interface{}) {
+	if off || std.skip(LevelInfo) {
+		return
+	}
+
+	s, unlock := lockSite(CallDepth - 1)
+	s.calls++
+	first := s.calls == 1
+	unlock()
+
+	if first {
+		std.log(CallDepth, v, 0)
+	}
+}
+
+// Limit pretty-prints the given arguments like Q, but at most once per
+// interval for the call site. If calls were dropped since the last record, it
+// ends with their count, e.g. (suppressed 17).
+func Limit(interval time.Duration, v ...interface{}) {
+	if off || std.skip(LevelInfo) {
+		return
+	}
+
+	s, unlock := lockSite(CallDepth - 1)
+	now := time.Now()
+	if !s.last.IsZero() && now.Sub(s.last) < interval {
+		s.suppressed++
+		unlock()
+
+		return
+	}
+
+	suppressed := s.suppressed
+	s.last, s.suppressed = now, 0
+	unlock()
+
+	if suppressed == 0 {
+		std.log(CallDepth, v, 1)
+		return
+	}
+
+	std.logNote(CallDepth, v, fmt.Sprintf("(suppressed %d)", suppressed))
+}
+
+// logNote is log of the values after the first argument, with the note
+// appended as an unnamed value. The note is added after the argument names
+// are looked up, so that it isn't named like a value of a variadic spread.
+func (l *logger) logNote(callDepth int, v []interface{}, note string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r, ok := l.newRecord(callDepth+1, v, 1)
+	if !ok {
+		return
+	}
+
+	// r.Values may be the spread slice of the caller, which must not change.
+	r.Values = append(r.Values[:len(r.Values):len(r.Values)], note)
+	l.write(r)
+}
+
+// lockSite returns the state of the call site found callDepth frames up the
+// stack, counted like getCallerInfo, with the sites lock held. The lock is
+// released by calling unlock.
+func lockSite(callDepth int) (s *site, unlock func()) {
+	pc, _, _, _ := runtime.Caller(callDepth)
+
+	sitesMu.Lock()
+	s = sites[pc]
+	if s == nil {
+		s = &site{}
+		sites[pc] = s
+	}
+
+	return s, sitesMu.Unlock
+}
@@ -0,0 +1,93 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEvery verifies that Every() logs the first and every n-th call of a
+// call site.
+func TestEvery(t *testing.T) {
+	name := setTestPath(t)
+
+	for i := 0; i < 7; i++ {
+		Every(3, i)
+	}
+
+	got := readLog(t, name)
+	for _, want := range []string{"(call #1)", "(call #4)", "(call #7)"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\nEvery(3, i)\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+
+	if n := strings.Count(got, "(call #"); n != 3 {
+		t.Fatalf("\nEvery(3, i)\ngot:  %d records\nwant: 3", n)
+	}
+
+	if !strings.Contains(got, colorize("i", bold)+"=") {
+		t.Fatalf("\nEvery(3, i)\ngot:  %q\nwant: named argument i", got)
+	}
+}
+
+// TestEverySpread verifies that Every() doesn't write the note into the spare
+// capacity of a spread slice of the caller.
+func TestEverySpread(t *testing.T) {
+	setTestPath(t)
+
+	xs := make([]interface{}, 1, 4)
+	xs[0] = "x"
+	Every(1, xs...)
+
+	if got := xs[:2][1]; got != nil {
+		t.Fatalf("\nEvery(1, xs...)\ngot:  xs[:2][1] = %q\nwant: nil", got)
+	}
+}
+
+// TestLimit verifies that Limit() logs a call site at most once per interval
+// and reports the number of suppressed calls.
+func TestLimit(t *testing.T) {
+	name := setTestPath(t)
+
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 5; j++ {
+			Limit(50*time.Millisecond, i)
+		}
+		time.Sleep(60 * time.Millisecond)
+	}
+
+	got := readLog(t, name)
+	if n := strings.Count(got, colorize("i", bold)+"="); n != 2 {
+		t.Fatalf("\nLimit()\ngot:  %q\nwant: 2 records", got)
+	}
+
+	if !strings.Contains(got, "(suppressed 4)") {
+		t.Fatalf("\nLimit()\ngot:  %q\nwant: (suppressed 4)", got)
+	}
+}
+
+// TestOnce verifies that Once() logs only the first call of each call site.
+func TestOnce(t *testing.T) {
+	name := setTestPath(t)
+
+	for i := 0; i < 3; i++ {
+		Once(i)
+		Once("other site", i)
+	}
+
+	got := readLog(t, name)
+	if n := strings.Count(got, colorize("i", bold)+"="); n != 2 {
+		t.Fatalf("\nOnce(i)\ngot:  %q\nwant: 2 records", got)
+	}
+
+	if !strings.Contains(got, colorize("i", bold)+"="+colorize("int(0)", cyan)) {
+		t.Fatalf("\nOnce(i)\ngot:  %q\nwant: i=int(0)", got)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	_ "embed" // for the viewer page
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/bingoohuang/q/pretty"
+)
+
+// streamBuffer is the number of records buffered per client. Records are
+// dropped for clients that fall further behind.
+const streamBuffer = 256
+
+// nolint: gochecknoglobals
+var (
+	//go:embed serve.html
+	servePage []byte
+
+	// streams is the Stream receiving the records of Q, once Serve is called.
+	streams     *Stream
+	streamsOnce sync.Once
+)
+
+// Serve starts an HTTP server on addr, e.g. "localhost:7070", that streams
+// the records of Q and its variants as they happen. The root path serves a
+// page to view them in a browser, /events streams them as server-sent events,
+// one JSON record per event in the JSONL format. Serve returns once the
+// server is listening.
+func Serve(addr string) error {
+	streamsOnce.Do(func() {
+		streams = NewStream()
+		AddSink(streams)
+	})
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("q serve: %w", err)
+	}
+
+	go http.Serve(ln, streams) // nolint: errcheck,gosec
+
+	return nil
+}
+
+// Stream is a Sink and an http.Handler that streams the records it receives
+// to its HTTP clients, see Serve. Use it to mount the viewer into an existing
+// server, e.g. mux.Handle("/q/", http.StripPrefix("/q", s)).
+type Stream struct {
+	mu      sync.Mutex // protects clients
+	clients map[chan []byte]struct{}
+}
+
+// NewStream returns a Stream without clients.
+func NewStream() *Stream {
+	return &Stream{clients: map[chan []byte]struct{}{}}
+}
+
+// Write sends the record to all connected clients, without blocking.
+func (s *Stream) Write(r Record) error {
+	if !s.connected() {
+		return nil // rendering the record would be wasted
+	}
+
+	// With the HTML of the values, for the viewer to fold them.
+	jr := newJSONRecord(r, 0)
+	for i := range jr.Args {
+		jr.Args[i].HTML = sprintHTML(r.Values[i])
+	}
+
+	b, err := json.Marshal(jr)
+	if err != nil {
+		return fmt.Errorf("marshal q record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.clients {
+		select {
+		case c <- b:
+		default: // the client is too slow, drop the record
+		}
+	}
+
+	return nil
+}
+
+// connected reports whether there are clients.
+func (s *Stream) connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.clients) > 0
+}
+
+// ServeHTTP serves the viewer page at / and the records at /events.
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/", "":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(servePage)
+	case "/pretty.css":
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+		_, _ = io.WriteString(w, pretty.HTMLStyle)
+	case "/events":
+		s.serveEvents(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveEvents streams the records as server-sent events until the client
+// disconnects.
+func (s *Stream) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	c := make(chan []byte, streamBuffer)
+	s.mu.Lock()
+	s.clients[c] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case b := <-c:
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
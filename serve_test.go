@@ -0,0 +1,107 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestStream verifies that a Stream serves the viewer page and streams the
+// records it receives as server-sent events.
+func TestStream(t *testing.T) {
+	s := NewStream()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET /: %v", err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(page), `EventSource("events")`) {
+		t.Fatalf("\nGET /\ngot:  %.100q\nwant: the viewer page", page)
+	}
+
+	// The client is registered once the response headers arrive.
+	resp, err = http.Get(srv.URL + "/events")
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("\nContent-Type\ngot:  %q\nwant: %q", ct, "text/event-stream")
+	}
+
+	l := New(WithOutput(io.Discard), WithSink(s))
+	port := 443
+	l.Q(port)
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	var jr jsonRecord
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &jr); err != nil {
+		t.Fatalf("json.Unmarshal(%q): %v", line, err)
+	}
+
+	if len(jr.Args) != 1 || jr.Args[0].Name != "port" || jr.Args[0].Value != "int(443)" {
+		t.Fatalf("\ngot:  %q\nwant: event of port=int(443)", line)
+	}
+
+	if !strings.Contains(jr.Args[0].HTML, `<span class="pretty-number">443</span>`) {
+		t.Fatalf("\ngot:  %q\nwant: the HTML of int(443)", jr.Args[0].HTML)
+	}
+}
+
+// TestStreamStyle verifies that a Stream serves the style sheet of the HTML
+// of the values.
+func TestStreamStyle(t *testing.T) {
+	srv := httptest.NewServer(NewStream())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/pretty.css")
+	if err != nil {
+		t.Fatalf("GET /pretty.css: %v", err)
+	}
+	defer resp.Body.Close()
+
+	css, _ := io.ReadAll(resp.Body)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/css") || !strings.Contains(string(css), ".pretty-type") {
+		t.Fatalf("\nGET /pretty.css\ngot:  %q %.100q\nwant: the style sheet", ct, css)
+	}
+}
+
+// goStringCounter counts the calls of its GoString method.
+type goStringCounter struct{ calls *int }
+
+func (c goStringCounter) GoString() string {
+	*c.calls++
+	return "counted"
+}
+
+// TestStreamNoClients verifies that a Stream without clients doesn't render
+// the records it receives.
+func TestStreamNoClients(t *testing.T) {
+	var calls int
+	if err := NewStream().Write(Record{Values: []interface{}{goStringCounter{&calls}}}); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	if calls != 0 {
+		t.Fatalf("\nGoString calls\ngot:  %d\nwant: 0", calls)
+	}
+}
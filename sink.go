@@ -0,0 +1,161 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"fmt"
+	"time"
+)
+
+// Record describes a single call of q.Q() or one of its variants.
+type Record struct {
+	Time  time.Time
+	Level Level  // LevelInfo unless written by Debug or Warn
+	File  string // empty if the caller is unknown
+	Line  int
+	Func  string
+	GID   uint64   // id of the calling goroutine
+	Names []string // source text of the arguments, may be shorter than Values
+	// Values are the logged values. Values of helpers like q.Timer or q.Hex
+	// are already rendered as strings.
+	Values []interface{}
+	Stack  []string // frames of the calling goroutine, see WithStack
+}
+
+// prepend adds values with the given names before the values of r.
+func (r *Record) prepend(names []string, values []interface{}) {
+	if len(values) == 0 {
+		return
+	}
+
+	// Names may be shorter than Values, keep them aligned.
+	padded := make([]string, len(r.Values))
+	copy(padded, r.Names)
+	r.Names = append(append([]string(nil), names...), padded...)
+	r.Values = append(append([]interface{}(nil), values...), r.Values...)
+}
+
+// Sink receives every record written by a logger. The first sink of a logger
+// is its output, which renders the records in its format to the log file by
+// default; AddSink adds more and SetSinks replaces them. Write is called with
+// the logger's lock held, so records arrive in order and Write must not call q.
+type Sink interface {
+	Write(r Record) error
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(r Record) error
+
+// Write calls f(r).
+func (f SinkFunc) Write(r Record) error { return f(r) }
+
+// AddSink makes Q and its variants pass every record to s.
+func AddSink(s Sink) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.sinks = append(std.sinks, s)
+}
+
+// WithSink makes the Logger pass every record to s.
+func WithSink(s Sink) Option {
+	return func(l *logger) { l.sinks = append(l.sinks, s) }
+}
+
+// SetSinks makes Q and its variants pass every record to the given sinks
+// only, instead of also writing it to their output, the log file at Path by
+// default, e.g. q.SetSinks(q.FileSink("/var/log/app/q.log"), netSink).
+func SetSinks(sinks ...Sink) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.sinks = append([]Sink(nil), sinks...)
+}
+
+// WithSinks makes the Logger pass every record to the given sinks only,
+// instead of to its output, like SetSinks.
+func WithSinks(sinks ...Sink) Option {
+	return func(l *logger) { l.sinks = append([]Sink(nil), sinks...) }
+}
+
+// FileSink returns a Sink that writes the records to the file at path, like
+// Q writes them to its log file: in the text format, unless opts configure it
+// otherwise like those of New, and rotated by SetMaxFileSize. Use it to log
+// to another file than Path, or to the log file of another logger.
+func FileSink(path string, opts ...Option) Sink {
+	l := New(append(opts, WithOutput(fileWriter(path)))...)
+
+	return fileSink{&l.logger}
+}
+
+// outputSink is the output of a logger: it renders the records in the format
+// of the logger and flushes them to its output.
+type outputSink struct {
+	l *logger
+}
+
+// Write renders the record. The logger's lock is held by its writeSinks.
+func (s outputSink) Write(r Record) error {
+	return s.l.render(r)
+}
+
+// fileSink is the output of a logger of its own, see FileSink.
+type fileSink struct {
+	l *logger
+}
+
+// Write renders the record with the lock of the sink's logger held.
+func (s fileSink) Write(r Record) error {
+	s.l.mu.Lock()
+	defer s.l.mu.Unlock()
+
+	return s.l.render(r)
+}
+
+// WriteRecord writes a record that was not created by a q call, e.g. by an
+// adapter for another logging package, like Q writes its records. A zero Time
+// or GID is set to the current time or goroutine.
+func WriteRecord(r Record) {
+	std.writeRecord(r)
+}
+
+// WriteRecord writes a record to the Logger's output, like WriteRecord.
+func (l *Logger) WriteRecord(r Record) {
+	l.writeRecord(r)
+}
+
+func (l *logger) writeRecord(r Record) {
+	if off || l.skip(r.Level) {
+		return
+	}
+
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+
+	if r.GID == 0 {
+		r.GID = goroutineID()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r.Values = l.hexValues(resolveLazy(r.Values))
+	l.write(r)
+}
+
+// writeSinks passes the record to all sinks, the logger's output first by
+// default. Errors are printed. l.mu must be held.
+func (l *logger) writeSinks(r Record) {
+	if l.recent != nil {
+		_ = l.recent.Write(r) // never fails
+	}
+
+	for _, s := range l.sinks {
+		if err := s.Write(r); err != nil {
+			fmt.Println(fmt.Errorf("q sink: %w", err))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSink verifies that sinks receive the records with caller info, names
+// and values, while the output is still written.
+func TestSink(t *testing.T) {
+	var got []Record
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithSink(SinkFunc(func(r Record) error {
+		got = append(got, r)
+		return nil
+	})))
+
+	port := 443
+	l.Q(port)
+
+	if len(got) != 1 {
+		t.Fatalf("\nsink got %d records\nwant: 1", len(got))
+	}
+
+	r := got[0]
+	if r.Func != "github.com/bingoohuang/q.TestSink" || r.Line == 0 || r.Time.IsZero() || r.GID == 0 {
+		t.Fatalf("\ngot:  %+v\nwant: caller info", r)
+	}
+
+	if len(r.Names) != 1 || r.Names[0] != "port" || len(r.Values) != 1 || r.Values[0] != 443 {
+		t.Fatalf("\ngot:  %+v\nwant: port=443", r)
+	}
+
+	if buf.Len() == 0 {
+		t.Fatalf("output is empty, want the record")
+	}
+}
+
+// TestFileSink verifies that a FileSink writes the records of another logger
+// to its file, in the text format or in the format of its options.
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	text, jsonl := filepath.Join(dir, "q.log"), filepath.Join(dir, "q.jsonl")
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithSink(FileSink(text, WithColors(false))),
+		WithSink(FileSink(jsonl, WithFormat(JSONL))))
+
+	port := 443
+	l.Q(port)
+	l.Q(port)
+
+	got := readLog(t, text)
+	if !strings.Contains(got, "q.TestFileSink]") || strings.Count(got, "port=int(443)") != 2 {
+		t.Fatalf("\ngot:  %q\nwant: two records under a header", got)
+	}
+
+	got = readLog(t, jsonl)
+	if strings.Count(got, `"name":"port"`) != 2 || strings.Count(got, "\n") != 2 {
+		t.Fatalf("\ngot:  %q\nwant: two JSON records", got)
+	}
+
+	if buf.Len() == 0 {
+		t.Fatalf("output is empty, want the record")
+	}
+}
+
+// TestSetSinks verifies that SetSinks and WithSinks replace the output of
+// the logger.
+func TestSetSinks(t *testing.T) {
+	var n int
+	count := SinkFunc(func(Record) error {
+		n++
+		return nil
+	})
+
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithSinks(count))
+	l.Q(1)
+	if n != 1 || buf.Len() != 0 {
+		t.Fatalf("\nWithSinks\ngot:  %d records, output %q\nwant: 1 record, no output", n, buf.String())
+	}
+
+	name := setTestPath(t)
+	other := filepath.Join(t.TempDir(), "other")
+	old := std.sinks
+	SetSinks(FileSink(other))
+	t.Cleanup(func() { SetSinks(old...) })
+
+	Q(2)
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Fatalf("\nSetSinks\ngot:  %v\nwant: no log file at Path", err)
+	}
+	if got := readLog(t, other); !strings.Contains(got, "int(2)") {
+		t.Fatalf("\nSetSinks\ngot:  %q\nwant: the record in the file of the sink", got)
+	}
+}
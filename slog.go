@@ -0,0 +1,211 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bingoohuang/q/pretty"
+)
+
+// SlogHandler is a slog.Handler writing records to a q log, so that values
+// logged with slog get q's pretty-printing. Each record is written as the
+// level and message, followed by the attributes as name=value pairs.
+type SlogHandler struct {
+	l      *logger
+	opts   slog.HandlerOptions
+	attrs  []slog.Attr // attributes added by WithAttrs, with group prefixes
+	groups []string    // groups opened by WithGroup
+}
+
+// NewSlogHandler returns a SlogHandler writing to the $TMPDIR/$USER.q log
+// file, like Q. A nil opts is the same as the zero options, which only handle
+// records of slog.LevelInfo and above.
+func NewSlogHandler(opts *slog.HandlerOptions) *SlogHandler {
+	return newSlogHandler(&std, opts)
+}
+
+// SlogHandler returns a SlogHandler writing to the Logger's output.
+func (l *Logger) SlogHandler(opts *slog.HandlerOptions) *SlogHandler {
+	return newSlogHandler(&l.logger, opts)
+}
+
+func newSlogHandler(l *logger, opts *slog.HandlerOptions) *SlogHandler {
+	h := &SlogHandler{l: l}
+	if opts != nil {
+		h.opts = *opts
+	}
+
+	return h
+}
+
+// Enabled reports whether records of the level are handled.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if off || disabled.Load() {
+		return false
+	}
+
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+
+	return level >= minLevel
+}
+
+// Handle writes the record under a header for the caller of the slog call.
+func (h *SlogHandler) Handle(_ context.Context, sr slog.Record) error {
+	if off || disabled.Load() {
+		return nil
+	}
+
+	r := Record{
+		Time:   sr.Time,
+		Names:  []string{""},
+		Values: []interface{}{sr.Level.String() + " " + sr.Message},
+	}
+
+	if sr.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{sr.PC}).Next()
+		r.Func, r.File, r.Line = f.Function, f.File, f.Line
+	}
+
+	add := func(a slog.Attr) {
+		r.Names = append(r.Names, a.Key)
+		r.Values = append(r.Values, a.Value.Any())
+	}
+
+	for _, a := range h.attrs {
+		add(a)
+	}
+
+	sr.Attrs(func(a slog.Attr) bool {
+		h.flatten(h.groups, a, add)
+		return true
+	})
+
+	h.l.writeRecord(r)
+
+	return nil
+}
+
+// WithAttrs returns a handler that writes the attributes with every record.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		h.flatten(h.groups, a, func(a slog.Attr) { h2.attrs = append(h2.attrs, a) })
+	}
+
+	return &h2
+}
+
+// WithGroup returns a handler that prefixes the keys of the following
+// attributes with the group name, e.g. req.method.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.groups = append(append([]string(nil), h.groups...), name)
+
+	return &h2
+}
+
+// flatten passes the attribute to add, after ReplaceAttr and with its key
+// prefixed by the groups. Group attributes are flattened into their members.
+func (h *SlogHandler) flatten(groups []string, a slog.Attr, add func(slog.Attr)) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		members := a.Value.Group()
+		if a.Key != "" {
+			groups = append(append([]string(nil), groups...), a.Key)
+		}
+
+		for _, m := range members {
+			h.flatten(groups, m, add)
+		}
+
+		return
+	}
+
+	if h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+
+	if a.Equal(slog.Attr{}) {
+		return // dropped by ReplaceAttr, or an empty attribute
+	}
+
+	if len(groups) > 0 {
+		a.Key = strings.Join(groups, ".") + "." + a.Key
+	}
+
+	add(a)
+}
+
+// nolint: gochecknoglobals
+var (
+	// slogOutput receives the records of Q once SetSlogOutput is called.
+	slogOutput     atomic.Pointer[slog.Logger]
+	slogOutputOnce sync.Once
+)
+
+// SetSlogOutput makes Q and its variants forward every record to logger, in
+// addition to writing it to the log file. A nil logger stops forwarding.
+// The logger must not write back to q, e.g. through a SlogHandler.
+func SetSlogOutput(logger *slog.Logger) {
+	slogOutput.Store(logger)
+	slogOutputOnce.Do(func() {
+		AddSink(SinkFunc(func(r Record) error {
+			if l := slogOutput.Load(); l != nil {
+				return SlogSink(l).Write(r)
+			}
+
+			return nil
+		}))
+	})
+}
+
+// SlogSink returns a Sink forwarding records to logger at slog.LevelDebug.
+// The message is the calling function, followed by the file and line and one
+// attribute per argument, keyed by its source text, e.g. port=443.
+func SlogSink(logger *slog.Logger) Sink {
+	return SinkFunc(func(r Record) error {
+		ctx := context.Background()
+		h := logger.Handler()
+		if !h.Enabled(ctx, slog.LevelDebug) {
+			return nil
+		}
+
+		sr := slog.NewRecord(r.Time, slog.LevelDebug, r.Func, 0)
+		if r.File != "" {
+			sr.AddAttrs(slog.String("file", shortFile(r.File)+":"+strconv.Itoa(r.Line)))
+		}
+
+		for i, v := range r.Values {
+			key := "arg" + strconv.Itoa(i)
+			if i < len(r.Names) && r.Names[i] != "" {
+				key = r.Names[i]
+			}
+
+			// slog handlers don't know about redacted fields, like encoding/json.
+			if pretty.ContainsRedacted(v) {
+				v = sprint(v)
+			}
+			sr.AddAttrs(slog.Any(key, v))
+		}
+
+		return h.Handle(ctx, sr)
+	})
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestSlogHandler verifies that a SlogHandler writes slog records with the
+// caller, the message and the attributes, and honors the level.
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false))
+	log := slog.New(l.SlogHandler(nil)).With("app", "demo").WithGroup("req")
+
+	log.Debug("hidden")
+	log.Info("hello", "id", 7, slog.Group("user", "name", "ann"))
+
+	got := buf.String()
+	for _, want := range []string{
+		"slog_test.go:",
+		"q.TestSlogHandler]",
+		"INFO hello app=demo req.id=int64(7) req.user.name=ann\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+
+	if strings.Contains(got, "hidden") {
+		t.Fatalf("\ngot:  %q\nwant: no debug record", got)
+	}
+}
+
+// TestSlogSink verifies that a SlogSink forwards records to a slog logger,
+// with the arguments as attributes.
+func TestSlogSink(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	l := New(WithOutput(io.Discard), WithSink(SlogSink(slog.New(h))))
+
+	port := 443
+	l.Q(port, "x")
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal(%q): %v", buf.String(), err)
+	}
+
+	if got["level"] != "DEBUG" || got["msg"] != "github.com/bingoohuang/q.TestSlogSink" ||
+		got["port"] != 443.0 || got["arg1"] != "x" || !strings.Contains(got["file"].(string), "slog_test.go:") {
+		t.Fatalf("\ngot:  %s\nwant: debug record of port=443 arg1=x", buf.String())
+	}
+}
+
+// TestSlogSinkRedacted verifies that redacted fields don't leak through the
+// attributes, also when held by interfaces.
+func TestSlogSinkRedacted(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	l := New(WithOutput(io.Discard), WithSink(SlogSink(slog.New(h))))
+
+	login := struct {
+		User     string
+		Password string `q:"redact"`
+	}{"root", "hunter2"}
+	l.Q(map[string]interface{}{"c": login}, []interface{}{login})
+
+	if got := buf.String(); strings.Contains(got, "hunter2") || !strings.Contains(got, "***") {
+		t.Fatalf("\ngot:  %q\nwant: redacted password", got)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// maxStackFrames is the number of frames captured by Stack.
+const maxStackFrames = 64
+
+// Stack writes the stack of the calling goroutine to the $TMPDIR/$USER.q log
+// file, starting at the caller of Stack.
+func Stack() {
+	if off {
+		return
+	}
+
+	std.stack(CallDepth)
+}
+
+// SetStack makes Q and its variants write the top n frames of the calling
+// goroutine's stack under every record. 0 disables it, which is the default.
+func SetStack(n int) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.stackFrames = n
+}
+
+// WithStack makes the Logger write the top n frames of the calling goroutine's
+// stack under every record.
+func WithStack(n int) Option {
+	return func(l *logger) { l.stackFrames = n }
+}
+
+func (l *logger) stack(callDepth int) {
+	if l.skip(LevelInfo) {
+		return
+	}
+
+	frames := callers(callDepth, maxStackFrames)
+	l.log(callDepth+1, []interface{}{strings.Join(frames, "\n")}, noNames)
+}
+
+// callers returns at most n frames of the current goroutine's stack, formatted
+// as "function file:line". skip is the number of frames to skip, counted like
+// the callDepth of getCallerInfo from the function calling callers.
+func callers(skip, n int) []string {
+	pcs := make([]uintptr, n)
+	n = runtime.Callers(skip+1, pcs) // +1 for runtime.Callers itself
+	frames := runtime.CallersFrames(pcs[:n])
+
+	lines := make([]string, 0, n)
+	for {
+		f, more := frames.Next()
+		lines = append(lines, formatFrame(f))
+		if !more {
+			break
+		}
+	}
+
+	return lines
+}
+
+// formatFrame returns the frame formatted as "function file:line".
+func formatFrame(f runtime.Frame) string {
+	return fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line)
+}
@@ -0,0 +1,47 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestStack verifies that Stack() writes the stack starting at its caller.
+func TestStack(t *testing.T) {
+	name := setTestPath(t)
+
+	Stack()
+
+	got := readLog(t, name)
+	if !strings.Contains(got, "q.TestStack ") || !strings.Contains(got, "testing.tRunner ") {
+		t.Fatalf("\nStack()\ngot:  %q\nwant: frames of TestStack and its callers", got)
+	}
+
+	if strings.Contains(got, "q.callers ") || strings.Contains(got, "q.Stack ") {
+		t.Fatalf("\nStack()\ngot:  %q\nwant: no frames of the q package", got)
+	}
+}
+
+// TestWithStack verifies that WithStack() writes the top frames under every
+// record.
+func TestWithStack(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false), WithStack(1))
+
+	l.Q(1)
+
+	got := buf.String()
+	if !strings.Contains(got, "    at github.com/bingoohuang/q.TestWithStack ") {
+		t.Fatalf("\nLogger.Q()\ngot:  %q\nwant: the frame of the caller", got)
+	}
+
+	if strings.Count(got, "    at ") != 1 {
+		t.Fatalf("\nLogger.Q()\ngot:  %q\nwant: exactly one frame", got)
+	}
+}
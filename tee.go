@@ -0,0 +1,21 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import "io"
+
+// Tee makes Q and its variants write everything to w as well as to the log
+// file, e.g. q.Tee(os.Stderr) to watch the output live. Each record is written
+// to both in a single Write call. A nil w turns tee mode off. The initial tee
+// can also be set with Q_TEE=stderr or Q_TEE=stdout.
+func Tee(w io.Writer) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.tee = w
+	if !std.noColor {
+		enableTerminalColors(w)
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestTee verifies that Tee() mirrors the log file content to the writer.
+func TestTee(t *testing.T) {
+	name := setTestPath(t)
+
+	var buf bytes.Buffer
+	Tee(&buf)
+	t.Cleanup(func() { Tee(nil) })
+
+	Q("mirrored")
+
+	if got, want := buf.String(), readLog(t, name); got != want {
+		t.Fatalf("\nTee()\ngot:  %q\nwant: %q", got, want)
+	}
+}
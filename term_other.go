@@ -0,0 +1,15 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package q
+
+import "os"
+
+// consoleWidth reports that f is not a terminal, on platforms where the
+// width of a terminal can't be queried.
+func consoleWidth(*os.File) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,25 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package q
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// consoleWidth returns the number of columns of the terminal behind f, and
+// false if f is not a terminal.
+func consoleWidth(f *os.File) (int, bool) {
+	var ws struct{ row, col, xpixel, ypixel uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 || ws.col == 0 {
+		return 0, false
+	}
+
+	return int(ws.col), true
+}
@@ -0,0 +1,34 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package q
+
+import (
+	"os"
+	"unsafe"
+)
+
+// nolint: gochecknoglobals
+var procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
+
+// consoleScreenBufferInfo is CONSOLE_SCREEN_BUFFER_INFO.
+type consoleScreenBufferInfo struct {
+	size, cursorPosition                   struct{ x, y int16 }
+	attributes                             uint16
+	left, top, right, bottom               int16
+	maximumWindowSizeX, maximumWindowSizeY int16
+}
+
+// consoleWidth returns the number of columns of the console window behind f,
+// and false if f is not a console.
+func consoleWidth(f *os.File) (int, bool) {
+	var info consoleScreenBufferInfo
+	if r, _, _ := procGetConsoleScreenBufferInfo.Call(f.Fd(), uintptr(unsafe.Pointer(&info))); r == 0 {
+		return 0, false
+	}
+
+	return int(info.right-info.left) + 1, true
+}
@@ -0,0 +1,51 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import "strings"
+
+// TB is the part of testing.TB used by T, so that q needn't import testing.
+// The standard library testing.T and testing.B are TBs.
+type TB interface {
+	Helper()
+	Log(args ...interface{})
+	Cleanup(f func())
+}
+
+// T routes the output of Q and its variants through t.Log until the end of
+// the test, so that it shows up with go test -v, next to the test that wrote
+// it. Colors are turned off meanwhile. Each record keeps its header with the
+// file, line and function of the q call. T changes package-level state, so it
+// must not be used in parallel tests.
+func T(t TB) {
+	t.Helper()
+
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	prevOut, prevNoColor := std.out, std.noColor
+	std.out, std.noColor = testWriter{t}, true
+	std.lastFile = "" // start with a header
+
+	t.Cleanup(func() {
+		std.mu.Lock()
+		defer std.mu.Unlock()
+
+		std.out, std.noColor = prevOut, prevNoColor
+		std.lastFile = ""
+	})
+}
+
+// testWriter writes every flush of a logger as a single t.Log call.
+type testWriter struct {
+	t TB
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Helper()
+	w.t.Log(strings.TrimRight(string(p), "\n"))
+
+	return len(p), nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"strings"
+	"testing"
+)
+
+// fakeTB records the logs and cleanups of a test. It is a TB, but no
+// testing.TB.
+type fakeTB struct {
+	logs     []string
+	cleanups []func()
+}
+
+func (tb *fakeTB) Helper()              {}
+func (tb *fakeTB) Cleanup(f func())     { tb.cleanups = append(tb.cleanups, f) }
+func (tb *fakeTB) Log(a ...interface{}) { tb.logs = append(tb.logs, a[0].(string)) }
+
+// TestT verifies that T() routes the output through t.Log, with the header
+// and without colors, and restores the output in the cleanup.
+func TestT(t *testing.T) {
+	setTestPath(t)
+	tb := &fakeTB{}
+	T(tb)
+
+	port := 443
+	Q(port)
+
+	if len(tb.logs) != 1 {
+		t.Fatalf("\nt.Log calls\ngot:  %q\nwant: 1 call", tb.logs)
+	}
+
+	got := tb.logs[0]
+	for _, want := range []string{"testlog_test.go:", "q.TestT]", "port=int(443)"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\nt.Log()\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+
+	if strings.Contains(got, "\033[") || strings.HasSuffix(got, "\n") {
+		t.Fatalf("\nt.Log()\ngot:  %q\nwant: no colors and no trailing newline", got)
+	}
+
+	for _, f := range tb.cleanups {
+		f()
+	}
+
+	Q(port)
+	if len(tb.logs) != 1 {
+		t.Fatalf("\nt.Log calls after cleanup\ngot:  %q\nwant: 1 call", tb.logs)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"strconv"
+	"time"
+)
+
+// DefaultTimeFormat is the layout of the header timestamps unless another one
+// is set with SetTimeFormat, e.g. 2006-01-02T15:04:05.000.
+const DefaultTimeFormat = "2006-01-02T15:04:05.000"
+
+// UnixMilli is the time format that writes header timestamps as milliseconds
+// since the UNIX epoch, e.g. 1718000000123, for machine processing.
+const UnixMilli = "unixmilli"
+
+// SetTimeFormat sets the layout of the header timestamps, as understood by
+// time.Time.Format, or UnixMilli. An empty layout restores DefaultTimeFormat.
+// The initial layout can also be set with $Q_TIME_FORMAT. Layouts without
+// spaces keep the headers readable by qtail.
+func SetTimeFormat(layout string) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.timeFormat = layout
+}
+
+// SetTimeLocation sets the time zone of the header timestamps. nil restores
+// the local time zone, which is the default. The initial time zone can also
+// be set by name with $Q_TIME_LOCATION, e.g. Q_TIME_LOCATION=UTC.
+func SetTimeLocation(loc *time.Location) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.timeLoc = loc
+}
+
+// WithTimeFormat sets the layout of the Logger's header timestamps, see
+// SetTimeFormat.
+func WithTimeFormat(layout string) Option {
+	return func(l *logger) { l.timeFormat = layout }
+}
+
+// WithTimeLocation sets the time zone of the Logger's header timestamps.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(l *logger) { l.timeLoc = loc }
+}
+
+// envTimeLocation returns the time zone named by the value of
+// $Q_TIME_LOCATION, nil for the local time zone if it is empty or unknown.
+func envTimeLocation(v string) *time.Location {
+	if v == "" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(v)
+	if err != nil {
+		return nil
+	}
+
+	return loc
+}
+
+// formatTime returns t as written in a header, in the logger's time format
+// and time zone.
+func (l *logger) formatTime(t time.Time) string {
+	if l.timeLoc != nil {
+		t = t.In(l.timeLoc)
+	}
+
+	switch l.timeFormat {
+	case "":
+		return t.Format(DefaultTimeFormat)
+	case UnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+
+	return t.Format(l.timeFormat)
+}
@@ -0,0 +1,63 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// TestFormatTime verifies that formatTime() honors the logger's time format
+// and time zone.
+func TestFormatTime(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 15, 4, 5, 123e6, time.UTC)
+	testCases := []struct {
+		layout string
+		loc    *time.Location
+		want   string
+	}{
+		{layout: "", loc: time.UTC, want: "2024-01-02T15:04:05.123"},
+		{layout: time.RFC3339, loc: time.UTC, want: "2024-01-02T15:04:05Z"},
+		{layout: time.RFC3339, loc: time.FixedZone("", 8*3600), want: "2024-01-02T23:04:05+08:00"},
+		{layout: UnixMilli, loc: nil, want: "1704207845123"},
+	}
+
+	for _, tc := range testCases {
+		l := &logger{timeFormat: tc.layout, timeLoc: tc.loc}
+		if got := l.formatTime(ts); got != tc.want {
+			t.Fatalf("\nformatTime(%q, %v)\ngot:  %q\nwant: %q", tc.layout, tc.loc, got, tc.want)
+		}
+	}
+}
+
+// TestWithTimeFormat verifies that a Logger writes its header timestamps in
+// the time format set with WithTimeFormat.
+func TestWithTimeFormat(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false), WithTimeFormat(UnixMilli))
+	l.Q(1)
+
+	if got := buf.String(); !regexp.MustCompile(`^\n\[\d{13} `).MatchString(got) {
+		t.Fatalf("\nLogger.Q()\ngot:  %q\nwant: header with epoch millis", got)
+	}
+}
+
+// TestEnvTimeLocation verifies that envTimeLocation() maps $Q_TIME_LOCATION
+// values to time zones.
+func TestEnvTimeLocation(t *testing.T) {
+	for value, want := range map[string]*time.Location{
+		"":      nil,
+		"UTC":   time.UTC,
+		"bogus": nil,
+	} {
+		if got := envTimeLocation(value); got != want {
+			t.Fatalf("\nenvTimeLocation(%q)\ngot:  %v\nwant: %v", value, got, want)
+		}
+	}
+}
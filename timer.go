@@ -0,0 +1,123 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import "time"
+
+// Timer starts a timer and returns a function that writes the time elapsed
+// since then, as label=duration, to the $TMPDIR/$USER.q log file. The record
+// is written under the header of the code calling the returned function:
+//
+//	stop := q.Timer("parse")
+//	parse()
+//	stop() // parse=12.3ms
+func Timer(label string) (stop func()) {
+	start := time.Now()
+
+	return func() {
+		if off {
+			return
+		}
+
+		std.since(CallDepth, start, label)
+	}
+}
+
+// Since writes the time elapsed since start, as label=duration, to the
+// $TMPDIR/$USER.q log file. It is meant to be deferred:
+//
+//	defer q.Since(time.Now(), "parse")
+func Since(start time.Time, label string) {
+	if off {
+		return
+	}
+
+	std.since(CallDepth, start, label)
+}
+
+func (l *logger) since(callDepth int, start time.Time, label string) {
+	if l.skip(LevelInfo) {
+		return
+	}
+
+	elapsed := time.Since(start)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r, ok := l.newRecord(callDepth+1, []interface{}{elapsed.String()}, noNames)
+	if !ok {
+		return
+	}
+
+	r.Names = []string{label}
+	l.write(r)
+}
+
+// ResetTimer restarts the elapsed time column of the $TMPDIR/$USER.q log file
+// at 0, so that the following records measure from now. It is otherwise only
+// restarted when a header is written.
+func ResetTimer() {
+	if off {
+		return
+	}
+
+	std.resetTimer()
+}
+
+// Mark writes a mark=label record, with the time elapsed since the previous
+// mark, ResetTimer or header, and then restarts the elapsed time column. It
+// measures the phases of an operation:
+//
+//	q.Mark("start")
+//	parse()
+//	q.Mark("parsed") // 0.012s mark=parsed
+func Mark(label string) {
+	if off || std.skip(LevelInfo) {
+		return
+	}
+
+	std.mark(CallDepth, label)
+}
+
+// ResetTimer restarts the elapsed time column of the Logger's output at 0.
+func (l *Logger) ResetTimer() {
+	if off {
+		return
+	}
+
+	l.resetTimer()
+}
+
+// Mark writes a mark=label record to the Logger's output and then restarts
+// its elapsed time column, like q.Mark.
+func (l *Logger) Mark(label string) {
+	if off || l.skip(LevelInfo) {
+		return
+	}
+
+	l.mark(CallDepth, label)
+}
+
+func (l *logger) resetTimer() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.start = time.Now()
+}
+
+func (l *logger) mark(callDepth int, label string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r, ok := l.newRecord(callDepth+1, []interface{}{label}, noNames)
+	if !ok {
+		return
+	}
+
+	r.Names = []string{"mark"}
+	l.write(r)
+	l.start = time.Now()
+}
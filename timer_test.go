@@ -0,0 +1,66 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// TestTimer verifies that Timer() and Since() write label=duration records
+// under the header of their caller.
+func TestTimer(t *testing.T) {
+	name := setTestPath(t)
+
+	stop := Timer("parse")
+	time.Sleep(time.Millisecond)
+	stop()
+
+	func() {
+		defer Since(time.Now(), "load")
+	}()
+
+	got := stripColors(readLog(t, name))
+	for _, want := range []string{
+		`q\.TestTimer\]`,
+		`parse=\d+(\.\d+)?ms`,
+		`q\.TestTimer\.func1\]`,
+		`load=\d+(\.\d+)?[nµm]?s`,
+	} {
+		if !regexp.MustCompile(want).MatchString(got) {
+			t.Fatalf("\ngot:  %q\nwant: match for %s", got, want)
+		}
+	}
+}
+
+// TestMark verifies that Mark() writes the time elapsed since the previous
+// mark and that ResetTimer() restarts the elapsed time column.
+func TestMark(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false))
+
+	l.Q(1)
+	time.Sleep(20 * time.Millisecond)
+	l.Mark("slept")
+	l.Q(2)
+	time.Sleep(20 * time.Millisecond)
+	l.ResetTimer()
+	l.Q(3)
+
+	got := buf.String()
+	for _, want := range []string{
+		`\n0\.0[2-9]\ds mark=slept\n`,
+		`\n0\.00\ds int\(2\)\n`,
+		`\n0\.00\ds int\(3\)\n`,
+	} {
+		if !regexp.MustCompile(want).MatchString(got) {
+			t.Fatalf("\ngot:  %q\nwant: match for %s", got, want)
+		}
+	}
+}
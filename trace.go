@@ -0,0 +1,78 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Trace writes "→ pkg.Func" followed by the given arguments, typically the
+// parameters of the calling function, to the $TMPDIR/$USER.q log file. It
+// returns a function that writes "← pkg.Func (took 12.3ms)" when called, so
+// the entry and exit of a function can be traced with a single line:
+//
+//	func parse(name string, n int) {
+//		defer q.Trace(name, n)()
+//		...
+//	}
+func Trace(v ...interface{}) (exit func()) {
+	if off || std.skip(LevelInfo) {
+		return func() {}
+	}
+
+	funcName := std.traceEnter(CallDepth, v)
+
+	start := time.Now()
+
+	return func() {
+		std.traceExit(CallDepth, funcName, start)
+	}
+}
+
+// traceEnter writes the entry record and returns the short name of the traced
+// function.
+func (l *logger) traceEnter(callDepth int, v []interface{}) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r, ok := l.newRecord(callDepth+1, v, 0)
+	funcName := shortFuncName(r.Func)
+	if !ok {
+		return funcName
+	}
+
+	r.Values = append([]interface{}{"→ " + funcName}, r.Values...)
+	r.Names = append([]string{""}, r.Names...)
+	l.write(r)
+
+	return funcName
+}
+
+func (l *logger) traceExit(callDepth int, funcName string, start time.Time) {
+	if l.skip(LevelInfo) {
+		return
+	}
+
+	msg := fmt.Sprintf("← %s (took %s)", funcName, time.Since(start))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r, ok := l.newRecord(callDepth+1, []interface{}{msg}, noNames); ok {
+		l.write(r)
+	}
+}
+
+// shortFuncName strips the package path from a function name returned by
+// runtime.FuncForPC, e.g. "github.com/foo/bar.Baz" -> "bar.Baz".
+func shortFuncName(funcName string) string {
+	if i := strings.LastIndex(funcName, "/"); i >= 0 {
+		return funcName[i+1:]
+	}
+
+	return funcName
+}
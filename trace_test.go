@@ -0,0 +1,49 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"regexp"
+	"testing"
+)
+
+func traced(name string, n int) {
+	defer Trace(name, n)()
+}
+
+// TestTrace verifies that Trace() writes the entry record with the named
+// arguments and the exit record with the elapsed time.
+func TestTrace(t *testing.T) {
+	name := setTestPath(t)
+
+	traced("foo", 3)
+
+	got := stripColors(readLog(t, name))
+	for _, want := range []string{
+		`→ q\.traced name=foo n=int\(3\)`,
+		`← q\.traced \(took \d+(\.\d+)?[nµm]?s\)`,
+	} {
+		if !regexp.MustCompile(want).MatchString(got) {
+			t.Fatalf("\ngot:  %q\nwant: match for %s", got, want)
+		}
+	}
+}
+
+// TestShortFuncName verifies that shortFuncName() strips the package path.
+func TestShortFuncName(t *testing.T) {
+	testCases := map[string]string{
+		"main.main":                        "main.main",
+		"github.com/bingoohuang/q.Q":       "q.Q",
+		"github.com/foo/bar.(*T).Do.func1": "bar.(*T).Do.func1",
+	}
+
+	for funcName, want := range testCases {
+		if got := shortFuncName(funcName); got != want {
+			t.Fatalf("\nshortFuncName(%q)\ngot:  %q\nwant: %q", funcName, got, want)
+		}
+	}
+}
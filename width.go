@@ -0,0 +1,67 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import (
+	"io"
+	"os"
+
+	"github.com/bingoohuang/q/pretty"
+)
+
+// AutoWidth makes SetMaxWidth and WithMaxWidth break long lines at the width
+// of the terminal the output goes to, or at 80 characters if it doesn't go to
+// a terminal. It is the default.
+const AutoWidth = -1
+
+// noWrap is the maxWidth of loggers that never break long lines.
+const noWrap = -1
+
+// SetMaxWidth sets the width at which long output lines of Q and its
+// variants are broken. 0 means never, which keeps every record on a single
+// line for grep, and AutoWidth the width of the terminal.
+func SetMaxWidth(width int) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.setMaxWidth(width)
+}
+
+// setMaxWidth stores width, such that the zero logger has AutoWidth.
+func (l *logger) setMaxWidth(width int) {
+	switch {
+	case width == AutoWidth:
+		l.maxWidth = 0
+	case width <= 0:
+		l.maxWidth = noWrap
+	default:
+		l.maxWidth = width
+	}
+}
+
+// terminalWidth returns the width of the terminal behind the output or, if
+// the output is a file, behind the tee, and false if neither is a terminal.
+func (l *logger) terminalWidth() (int, bool) {
+	for _, w := range []io.Writer{l.out, l.tee} {
+		if f, ok := w.(*os.File); ok {
+			if width, ok := consoleWidth(f); ok {
+				return width, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// valueOptions returns the options printing the values of the text format
+// within the line width, such that a width of 0 prints each value on a single
+// line.
+func (l *logger) valueOptions() []pretty.Option {
+	if l.maxWidth == noWrap {
+		return []pretty.Option{pretty.Width(0)}
+	}
+
+	return []pretty.Option{pretty.Width(l.lineWidth())}
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestMaxWidth verifies that WithMaxWidth breaks long lines at the given
+// width, never for 0, and at 80 characters for AutoWidth when the output is
+// not a terminal.
+func TestMaxWidth(t *testing.T) {
+	long := strings.Repeat("a", 50)
+	tests := []struct {
+		width int
+		want  bool // line broken before b
+	}{
+		{width: 20, want: true},
+		{width: AutoWidth, want: true},
+		{width: 0, want: false},
+		{width: 200, want: false},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		l := New(WithOutput(&buf), WithColors(false), WithMaxWidth(tt.width))
+		a, b := long, long
+		l.Q(a, b)
+
+		if got := strings.Contains(buf.String(), "\n       b="); got != tt.want {
+			t.Fatalf("\nWithMaxWidth(%d)\ngot:  %q\nwant: line broken before b %t", tt.width, buf.String(), tt.want)
+		}
+	}
+}
+
+// TestMaxWidthValues verifies that the values are printed within the width,
+// and on a single line with WithMaxWidth(0).
+func TestMaxWidthValues(t *testing.T) {
+	type point struct{ X, Y int }
+	tests := []struct {
+		width int
+		want  string
+	}{
+		{width: 0, want: "p=q.point{X:1, Y:2}\n"},
+		{width: 80, want: "p=q.point{X:1, Y:2}\n"},
+		{width: 10, want: "p=q.point{\n           X:  1,\n           Y:  2,\n       }\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		l := New(WithOutput(&buf), WithColors(false), WithMaxWidth(tt.width))
+		p := point{1, 2}
+		l.Q(p)
+
+		if got := buf.String(); !strings.HasSuffix(got, tt.want) {
+			t.Fatalf("\nWithMaxWidth(%d)\ngot:  %q\nwant: suffix %q", tt.width, got, tt.want)
+		}
+	}
+}
+
+// TestLineWidth verifies the width at which the std logger breaks lines
+// after SetMaxWidth.
+func TestLineWidth(t *testing.T) {
+	t.Cleanup(func() { SetMaxWidth(AutoWidth) })
+
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf))
+	if got := l.lineWidth(); got != maxLineWidth {
+		t.Fatalf("\nlineWidth()\ngot:  %d\nwant: %d", got, maxLineWidth)
+	}
+
+	SetMaxWidth(120)
+	if got := std.lineWidth(); got != 120 {
+		t.Fatalf("\nSetMaxWidth(120)\ngot:  %d\nwant: 120", got)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package q
+
+import "fmt"
+
+// badKey is the name of a With argument that is not a key of a pair.
+const badKey = "!BADKEY"
+
+// Fields is a scoped logger that prepends its fields to every record, see
+// With. It writes under the same headers as the logger it was created from.
+type Fields struct {
+	l      *logger
+	names  []string
+	values []interface{}
+}
+
+// With returns a scoped logger whose fields, given as alternating names and
+// values, are prepended to every record it writes to the $TMPDIR/$USER.q log
+// file, e.g. q.With("reqID", id, "user", u).Q(port) writes
+// reqID=... user=... port=int(443). A value without a string name is written
+// as !BADKEY=value.
+func With(kv ...interface{}) *Fields {
+	return newFields(&std, nil, nil, kv)
+}
+
+// With returns a scoped logger prepending the fields to every record written
+// to the Logger's output, like With.
+func (l *Logger) With(kv ...interface{}) *Fields {
+	return newFields(&l.logger, nil, nil, kv)
+}
+
+// With returns a scoped logger with the fields added to those of f.
+func (f *Fields) With(kv ...interface{}) *Fields {
+	return newFields(f.l, f.names, f.values, kv)
+}
+
+func newFields(l *logger, names []string, values []interface{}, kv []interface{}) *Fields {
+	f := &Fields{
+		l:      l,
+		names:  append([]string(nil), names...),
+		values: append([]interface{}(nil), values...),
+	}
+
+	for i := 0; i < len(kv); i++ {
+		name, ok := kv[i].(string)
+		if !ok || i+1 == len(kv) {
+			f.names, f.values = append(f.names, badKey), append(f.values, kv[i])
+			continue
+		}
+
+		f.names, f.values = append(f.names, name), append(f.values, kv[i+1])
+		i++
+	}
+
+	return f
+}
+
+// Q pretty-prints the fields and the given arguments, like q.Q.
+func (f *Fields) Q(v ...interface{}) {
+	if off {
+		return
+	}
+
+	f.log(CallDepth, v, 0)
+}
+
+// If pretty-prints the fields and the given arguments only if cond is true,
+// like q.If.
+func (f *Fields) If(cond bool, v ...interface{}) {
+	if off || !cond {
+		return
+	}
+
+	f.log(CallDepth, v, 1)
+}
+
+// Qf pretty-prints the fields and the formatted message, like q.Qf.
+func (f *Fields) Qf(format string, v ...interface{}) {
+	if off || f.l.skip(LevelInfo) {
+		return // skip the Sprintf as well
+	}
+
+	f.log(CallDepth, []interface{}{fmt.Sprintf(format, v...)}, noNames)
+}
+
+// log writes the record of the values with the fields prepended. See
+// logger.log for callDepth and skipArgs.
+func (f *Fields) log(callDepth int, v []interface{}, skipArgs int) {
+	if f.l.skip(LevelInfo) {
+		return
+	}
+
+	f.l.mu.Lock()
+	defer f.l.mu.Unlock()
+
+	r, ok := f.l.newRecord(callDepth+1, v, skipArgs)
+	if !ok {
+		return
+	}
+
+	r.prepend(f.names, f.values)
+	f.l.write(r)
+}
@@ -0,0 +1,44 @@
+// Copyright 2016 Ryan Boehning. All rights reserved.
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+//go:build !qoff
+
+package q
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestWith verifies that a scoped logger prepends its fields to the records
+// of Q, If and Qf, under the header of the caller.
+func TestWith(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithColors(false), WithMaxWidth(200))
+	id := 42
+	f := l.With("reqID", id).With("user", "ann", 7)
+
+	port := 443
+	f.Q(port)
+	f.If(true, port)
+	f.If(false, port)
+	f.Qf("n=%d", 1)
+
+	got := buf.String()
+	for _, want := range []string{
+		"with_test.go:",
+		"q.TestWith]",
+		" reqID=int(42) user=ann !BADKEY=int(7) port=int(443)\n",
+		" reqID=int(42) user=ann !BADKEY=int(7) n=1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("\ngot:  %q\nmissing %q", got, want)
+		}
+	}
+
+	if n := strings.Count(got, "port=int(443)"); n != 2 {
+		t.Fatalf("\ngot:  %q\nwant: 2 records of port", got)
+	}
+}